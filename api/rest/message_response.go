@@ -7,18 +7,32 @@ import (
 )
 
 type MessageResponse struct {
-	ID            int64   `json:"id"`
-	Recipient     string  `json:"recipient"`
-	Content       string  `json:"content"`
-	Status        string  `json:"status"`
-	SentAt        *string `json:"sentAt,omitempty"`
-	RetryCount    int     `json:"retryCount"`
-	LastAttemptAt *string `json:"lastAttemptAt,omitempty"`
-	CreatedAt     string  `json:"createdAt"`
-	UpdatedAt     *string `json:"updatedAt,omitempty"`
-	ResponseID    *string `json:"responseId,omitempty"`
-	ResponseCode  *int64  `json:"responseCode,omitempty"`
-	ErrorMessage  *string `json:"errorMessage,omitempty"`
+	ID            int64    `json:"id"`
+	Recipient     string   `json:"recipient"`
+	Content       string   `json:"content"`
+	Status        string   `json:"status"`
+	Channel       string   `json:"channel"`
+	Subject       *string  `json:"subject,omitempty"`
+	Priority      string   `json:"priority,omitempty"`
+	Tenant        *string  `json:"tenant,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	SentAt        *string  `json:"sentAt,omitempty"`
+	RetryCount    int      `json:"retryCount"`
+	LastAttemptAt *string  `json:"lastAttemptAt,omitempty"`
+	CreatedAt     string   `json:"createdAt"`
+	UpdatedAt     *string  `json:"updatedAt,omitempty"`
+	ResponseID    *string  `json:"responseId,omitempty"`
+	ResponseCode  *int64   `json:"responseCode,omitempty"`
+	ErrorMessage  *string  `json:"errorMessage,omitempty"`
+	Provider      *string  `json:"provider,omitempty"`
+	// FailedAttempts lists the providers that failed before delivery.
+	FailedAttempts []ProviderAttemptResponse `json:"failedAttempts,omitempty"`
+}
+
+type ProviderAttemptResponse struct {
+	Provider    string `json:"provider"`
+	Error       string `json:"error"`
+	AttemptedAt string `json:"attemptedAt"`
 }
 
 type MessagesListResponse struct {
@@ -32,10 +46,21 @@ func ToMessageResponse(msg domain.Message) MessageResponse {
 		Recipient:  msg.Recipient,
 		Content:    msg.Content,
 		Status:     string(msg.Status),
+		Channel:    string(msg.ChannelOrDefault()),
+		Priority:   string(msg.Priority),
+		Tags:       msg.Tags,
 		RetryCount: msg.RetryCount,
 		CreatedAt:  msg.CreatedAt.Format(time.RFC3339),
 	}
 
+	if msg.Subject.Valid {
+		resp.Subject = &msg.Subject.String
+	}
+
+	if msg.Tenant.Valid {
+		resp.Tenant = &msg.Tenant.String
+	}
+
 	if msg.SentAt.Valid {
 		sentAt := msg.SentAt.Time.Format(time.RFC3339)
 		resp.SentAt = &sentAt
@@ -63,6 +88,18 @@ func ToMessageResponse(msg domain.Message) MessageResponse {
 		resp.ErrorMessage = &msg.ErrorMessage.String
 	}
 
+	if msg.Provider.Valid {
+		resp.Provider = &msg.Provider.String
+	}
+
+	for _, attempt := range msg.FailedAttempts {
+		resp.FailedAttempts = append(resp.FailedAttempts, ProviderAttemptResponse{
+			Provider:    attempt.Provider,
+			Error:       attempt.Error,
+			AttemptedAt: attempt.AttemptedAt.Format(time.RFC3339),
+		})
+	}
+
 	return resp
 }
 
@@ -73,3 +110,47 @@ func ToMessageResponses(messages []domain.Message) []MessageResponse {
 	}
 	return responses
 }
+
+type MessageAttemptResponse struct {
+	ID           int64   `json:"id"`
+	Provider     string  `json:"provider"`
+	StatusCode   *int64  `json:"statusCode,omitempty"`
+	LatencyMs    int64   `json:"latencyMs"`
+	ResponseBody *string `json:"responseBody,omitempty"`
+	ErrorClass   string  `json:"errorClass,omitempty"`
+	Error        *string `json:"error,omitempty"`
+	RetryAttempt *int64  `json:"retryAttempt,omitempty"`
+	CreatedAt    string  `json:"createdAt"`
+}
+
+type MessageAttemptsListResponse struct {
+	Attempts []MessageAttemptResponse `json:"attempts"`
+	Count    int                      `json:"count"`
+}
+
+func ToMessageAttemptResponses(attempts []domain.MessageAttempt) []MessageAttemptResponse {
+	responses := make([]MessageAttemptResponse, len(attempts))
+	for i, attempt := range attempts {
+		resp := MessageAttemptResponse{
+			ID:         attempt.ID,
+			Provider:   attempt.Provider,
+			LatencyMs:  attempt.LatencyMs,
+			ErrorClass: string(attempt.ErrorClass),
+			CreatedAt:  attempt.CreatedAt.Format(time.RFC3339),
+		}
+		if attempt.StatusCode.Valid {
+			resp.StatusCode = &attempt.StatusCode.Int64
+		}
+		if attempt.ResponseBody.Valid {
+			resp.ResponseBody = &attempt.ResponseBody.String
+		}
+		if attempt.Error.Valid {
+			resp.Error = &attempt.Error.String
+		}
+		if attempt.RetryAttempt.Valid {
+			resp.RetryAttempt = &attempt.RetryAttempt.Int64
+		}
+		responses[i] = resp
+	}
+	return responses
+}
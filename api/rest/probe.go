@@ -0,0 +1,21 @@
+package rest
+
+// Probe and check statuses.
+const (
+	ProbeStatusOK       = "ok"
+	ProbeStatusFail     = "fail"
+	ProbeStatusDraining = "draining"
+)
+
+type ProbeResponse struct {
+	Status string          `json:"status"`
+	Checks []CheckResponse `json:"checks"`
+}
+
+type CheckResponse struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+	CheckedAt string  `json:"checkedAt"`
+}
@@ -0,0 +1,146 @@
+package rest
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+)
+
+type RouteMatch struct {
+	RecipientPrefixes []string `json:"recipientPrefixes,omitempty" example:"+90555"`
+	Countries         []string `json:"countries,omitempty" example:"TR"`
+	Priorities        []string `json:"priorities,omitempty" example:"high"`
+	Tenants           []string `json:"tenants,omitempty"`
+	Tags              []string `json:"tags,omitempty"`
+}
+
+type RouteTarget struct {
+	Provider string `json:"provider" example:"primary"`
+	Weight   int    `json:"weight" example:"100"`
+}
+
+type RoutingRuleRequest struct {
+	Name     string        `json:"name" example:"turkish-numbers"`
+	Position int           `json:"position"`
+	Enabled  *bool         `json:"enabled,omitempty"`
+	Match    RouteMatch    `json:"match"`
+	Targets  []RouteTarget `json:"targets"`
+}
+
+type RoutingRuleResponse struct {
+	ID        int64         `json:"id"`
+	Name      string        `json:"name"`
+	Position  int           `json:"position"`
+	Enabled   bool          `json:"enabled"`
+	Match     RouteMatch    `json:"match"`
+	Targets   []RouteTarget `json:"targets"`
+	CreatedAt string        `json:"createdAt"`
+	UpdatedAt *string       `json:"updatedAt,omitempty"`
+}
+
+type RoutingRulesListResponse struct {
+	Rules []RoutingRuleResponse `json:"rules"`
+	Count int                   `json:"count"`
+}
+
+type DryRunRequest struct {
+	Channel   string   `json:"channel,omitempty" example:"sms"`
+	Recipient string   `json:"recipient" example:"+905551112233"`
+	Priority  string   `json:"priority,omitempty" example:"normal"`
+	Tenant    string   `json:"tenant,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+}
+
+type DryRunResponse struct {
+	// Matched is false when no rule matched and the default provider order applies.
+	Matched  bool   `json:"matched"`
+	RuleID   *int64 `json:"ruleId,omitempty"`
+	RuleName string `json:"ruleName,omitempty"`
+	Provider string `json:"provider,omitempty"`
+}
+
+func (r RoutingRuleRequest) ToDomain() domain.RoutingRule {
+	rule := domain.RoutingRule{
+		Name:     r.Name,
+		Position: r.Position,
+		Enabled:  r.Enabled == nil || *r.Enabled,
+		Match: domain.RouteMatch{
+			RecipientPrefixes: r.Match.RecipientPrefixes,
+			Countries:         r.Match.Countries,
+			Tenants:           r.Match.Tenants,
+			Tags:              r.Match.Tags,
+		},
+	}
+
+	for _, priority := range r.Match.Priorities {
+		rule.Match.Priorities = append(rule.Match.Priorities, domain.MessagePriority(priority))
+	}
+
+	for _, target := range r.Targets {
+		rule.Targets = append(rule.Targets, domain.RouteTarget{
+			Provider: target.Provider,
+			Weight:   target.Weight,
+		})
+	}
+
+	return rule
+}
+
+func (r DryRunRequest) ToDomain() domain.Message {
+	message := domain.Message{
+		Channel:   domain.MessageChannel(r.Channel),
+		Recipient: r.Recipient,
+		Priority:  domain.MessagePriority(r.Priority),
+		Tags:      r.Tags,
+	}
+
+	if r.Tenant != "" {
+		message.Tenant = sql.NullString{String: r.Tenant, Valid: true}
+	}
+
+	return message
+}
+
+func ToRoutingRuleResponse(rule domain.RoutingRule) RoutingRuleResponse {
+	resp := RoutingRuleResponse{
+		ID:       rule.ID,
+		Name:     rule.Name,
+		Position: rule.Position,
+		Enabled:  rule.Enabled,
+		Match: RouteMatch{
+			RecipientPrefixes: rule.Match.RecipientPrefixes,
+			Countries:         rule.Match.Countries,
+			Tenants:           rule.Match.Tenants,
+			Tags:              rule.Match.Tags,
+		},
+		Targets:   make([]RouteTarget, 0, len(rule.Targets)),
+		CreatedAt: rule.CreatedAt.Format(time.RFC3339),
+	}
+
+	for _, priority := range rule.Match.Priorities {
+		resp.Match.Priorities = append(resp.Match.Priorities, string(priority))
+	}
+
+	for _, target := range rule.Targets {
+		resp.Targets = append(resp.Targets, RouteTarget{
+			Provider: target.Provider,
+			Weight:   target.Weight,
+		})
+	}
+
+	if rule.UpdatedAt.Valid {
+		updatedAt := rule.UpdatedAt.Time.Format(time.RFC3339)
+		resp.UpdatedAt = &updatedAt
+	}
+
+	return resp
+}
+
+func ToRoutingRuleResponses(rules []domain.RoutingRule) []RoutingRuleResponse {
+	responses := make([]RoutingRuleResponse, len(rules))
+	for i, rule := range rules {
+		responses[i] = ToRoutingRuleResponse(rule)
+	}
+	return responses
+}
@@ -0,0 +1,134 @@
+// Package signing signs and verifies outbound webhook requests.
+//
+// Every request carries an X-Timestamp header with the Unix time in seconds and
+// an X-Signature header holding one or more comma separated "sha256=<hex>"
+// values. Each value is the HMAC-SHA256 of "<timestamp>.<body>" under one of
+// the sender's active secrets, so receivers can rotate secrets without downtime
+// by accepting a request when any signature matches any secret they know.
+package signing
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderSignature = "X-Signature"
+	HeaderTimestamp = "X-Timestamp"
+
+	SignaturePrefix  = "sha256="
+	DefaultTolerance = 5 * time.Minute
+)
+
+var (
+	ErrNoSecrets          = errors.New("no signing secrets configured")
+	ErrMissingSignature   = errors.New("missing signature")
+	ErrInvalidTimestamp   = errors.New("invalid timestamp")
+	ErrTimestampOutOfSync = errors.New("timestamp outside of tolerance")
+	ErrSignatureMismatch  = errors.New("signature mismatch")
+)
+
+// Sign returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>".
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureHeader builds the X-Signature value with one signature per secret.
+// Empty secrets are skipped.
+func SignatureHeader(secrets []string, timestamp int64, body []byte) string {
+	values := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		values = append(values, SignaturePrefix+Sign(secret, timestamp, body))
+	}
+	return strings.Join(values, ",")
+}
+
+// SignRequest sets the X-Timestamp and X-Signature headers on req for body.
+func SignRequest(req *http.Request, secrets []string, body []byte, now time.Time) error {
+	timestamp := now.Unix()
+	signature := SignatureHeader(secrets, timestamp, body)
+	if signature == "" {
+		return ErrNoSecrets
+	}
+
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, signature)
+	return nil
+}
+
+// Verify checks that at least one signature in signatureHeader matches body
+// under one of secrets, and that the timestamp is within tolerance of now.
+// A non-positive tolerance falls back to DefaultTolerance.
+func Verify(secrets []string, signatureHeader, timestampHeader string, body []byte, tolerance time.Duration, now time.Time) error {
+	if signatureHeader == "" {
+		return ErrMissingSignature
+	}
+
+	timestamp, err := strconv.ParseInt(strings.TrimSpace(timestampHeader), 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	if skew := now.Sub(time.Unix(timestamp, 0)); skew > tolerance || skew < -tolerance {
+		return ErrTimestampOutOfSync
+	}
+
+	for _, value := range strings.Split(signatureHeader, ",") {
+		value = strings.TrimSpace(value)
+		if !strings.HasPrefix(value, SignaturePrefix) {
+			continue
+		}
+		got, err := hex.DecodeString(strings.TrimPrefix(value, SignaturePrefix))
+		if err != nil {
+			continue
+		}
+
+		for _, secret := range secrets {
+			if secret == "" {
+				continue
+			}
+			want, _ := hex.DecodeString(Sign(secret, timestamp, body))
+			if hmac.Equal(got, want) {
+				return nil
+			}
+		}
+	}
+
+	return ErrSignatureMismatch
+}
+
+// VerifyRequest reads and verifies the body of r, then restores it so the
+// handler can decode it again. It returns the raw body on success.
+func VerifyRequest(r *http.Request, secrets []string, tolerance time.Duration) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := Verify(secrets, r.Header.Get(HeaderSignature), r.Header.Get(HeaderTimestamp), body, tolerance, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
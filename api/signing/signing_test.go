@@ -0,0 +1,129 @@
+//go:build unit
+
+package signing_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/api/signing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"to":"+905551112233","content":"hello"}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	cases := []struct {
+		name      string
+		signers   []string
+		verifiers []string
+		timestamp string
+		body      []byte
+		now       time.Time
+		wantErr   error
+	}{
+		{
+			name:      "given a matching secret, then the signature is valid",
+			signers:   []string{"current"},
+			verifiers: []string{"current"},
+			timestamp: timestamp,
+			body:      body,
+			now:       now,
+		},
+		{
+			name:      "given the sender still signs with the old secret too, then a receiver on the old secret accepts it",
+			signers:   []string{"new", "old"},
+			verifiers: []string{"old"},
+			timestamp: timestamp,
+			body:      body,
+			now:       now,
+		},
+		{
+			name:      "given the receiver already knows both secrets, then a sender on the new secret is accepted",
+			signers:   []string{"new"},
+			verifiers: []string{"new", "old"},
+			timestamp: timestamp,
+			body:      body,
+			now:       now,
+		},
+		{
+			name:      "given an unknown secret, then verification fails",
+			signers:   []string{"attacker"},
+			verifiers: []string{"current"},
+			timestamp: timestamp,
+			body:      body,
+			now:       now,
+			wantErr:   signing.ErrSignatureMismatch,
+		},
+		{
+			name:      "given a tampered body, then verification fails",
+			signers:   []string{"current"},
+			verifiers: []string{"current"},
+			timestamp: timestamp,
+			body:      []byte(`{"to":"+1","content":"hello"}`),
+			now:       now,
+			wantErr:   signing.ErrSignatureMismatch,
+		},
+		{
+			name:      "given a stale timestamp, then verification fails",
+			signers:   []string{"current"},
+			verifiers: []string{"current"},
+			timestamp: timestamp,
+			body:      body,
+			now:       now.Add(10 * time.Minute),
+			wantErr:   signing.ErrTimestampOutOfSync,
+		},
+		{
+			name:      "given a malformed timestamp, then verification fails",
+			signers:   []string{"current"},
+			verifiers: []string{"current"},
+			timestamp: "yesterday",
+			body:      body,
+			now:       now,
+			wantErr:   signing.ErrInvalidTimestamp,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			header := signing.SignatureHeader(tc.signers, now.Unix(), body)
+			err := signing.Verify(tc.verifiers, header, tc.timestamp, tc.body, 0, tc.now)
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestVerify_MissingSignature(t *testing.T) {
+	err := signing.Verify([]string{"current"}, "", "1700000000", nil, 0, time.Unix(1700000000, 0))
+	assert.ErrorIs(t, err, signing.ErrMissingSignature)
+}
+
+func TestSignRequest_RoundTrip(t *testing.T) {
+	body := []byte(`{"to":"1","content":"hi"}`)
+	req, err := http.NewRequest(http.MethodPost, "http://example.com", bytes.NewReader(body))
+	require.NoError(t, err)
+
+	require.NoError(t, signing.SignRequest(req, []string{"current"}, body, time.Now()))
+
+	got, err := signing.VerifyRequest(req, []string{"current"}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, body, got)
+
+	restored, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, restored, "body must stay readable after verification")
+}
+
+func TestSignRequest_NoSecrets(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "http://example.com", nil)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, signing.SignRequest(req, nil, nil, time.Now()), signing.ErrNoSecrets)
+}
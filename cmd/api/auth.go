@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/muratdemir0/gopulse-messages/internal/infra/auth"
+)
+
+func (a *App) initAuth() error {
+	ac := a.config.Auth
+	if !ac.Enabled {
+		slog.Warn("API authentication is disabled, every route is open to anyone who can reach the server")
+		return nil
+	}
+
+	keys := make([]auth.APIKey, 0, len(ac.APIKeys))
+	for _, key := range ac.APIKeys {
+		keys = append(keys, auth.APIKey{Name: key.Name, Key: key.Key, Role: auth.Role(key.Role)})
+	}
+
+	var jwtConfig *auth.JWTConfig
+	if ac.JWT.Algorithm != "" {
+		jwtConfig = &auth.JWTConfig{
+			Algorithm:     ac.JWT.Algorithm,
+			Secret:        ac.JWT.Secret,
+			PublicKeyFile: ac.JWT.PublicKeyFile,
+			JWKSFile:      ac.JWT.JWKSFile,
+			Issuer:        ac.JWT.Issuer,
+			Audience:      ac.JWT.Audience,
+			RolesClaim:    ac.JWT.RolesClaim,
+		}
+	}
+
+	authenticator, err := auth.NewAuthenticator(keys, jwtConfig)
+	if err != nil {
+		return err
+	}
+
+	policy := a.defaultRoutePolicy()
+	for _, route := range ac.Routes {
+		role, err := auth.ParseRole(route.Role)
+		if err != nil {
+			return fmt.Errorf("route %q: %w", route.Pattern, err)
+		}
+		policy[route.Pattern] = role
+	}
+
+	a.authenticator = authenticator
+	a.authPolicy = policy
+	slog.Info("API authentication enabled", "api_keys", len(keys), "jwt_algorithm", ac.JWT.Algorithm)
+	return nil
+}
+
+// defaultRoutePolicy lists the role required per mux pattern. Patterns not
+// listed require admin.
+func (a *App) defaultRoutePolicy() map[string]auth.Role {
+	return map[string]auth.Role{
+		"/health":                     auth.RolePublic,
+		"GET /livez":                  auth.RolePublic,
+		"GET /readyz":                 auth.RolePublic,
+		"/swagger/":                   auth.RolePublic,
+		"GET " + a.metricsPath():      auth.RolePublic,
+		"GET /messages":               auth.RoleReader,
+		"GET /messages/{id}/attempts": auth.RoleReader,
+		"GET /messages/stream":        auth.RoleReader,
+		"GET /routing/rules":          auth.RoleReader,
+		"POST /routing/dry-run":       auth.RoleReader,
+		"POST /messages/start":        auth.RoleOperator,
+		"POST /messages/stop":         auth.RoleOperator,
+		"POST /routing/rules":         auth.RoleAdmin,
+		"PUT /routing/rules/{id}":     auth.RoleAdmin,
+		"DELETE /routing/rules/{id}":  auth.RoleAdmin,
+	}
+}
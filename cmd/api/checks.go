@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/muratdemir0/gopulse-messages/internal/config"
+)
+
+func (a *App) initChecks() {
+	timeout := time.Duration(a.config.Health.TimeoutMs) * time.Millisecond
+	cacheTTL := time.Duration(a.config.Health.CacheMs) * time.Millisecond
+
+	a.liveness = app.NewCheckRegistry(timeout, cacheTTL)
+	a.liveness.Register("scheduler", app.SchedulerCheck(a.messageService.Scheduler(), a.config.Health.SchedulerMaxMissed))
+
+	a.readiness = app.NewCheckRegistry(timeout, cacheTTL)
+	a.readiness.Register("postgres", a.db.Ping)
+	a.readiness.Register("redis", func(ctx context.Context) error {
+		return a.redis.Ping(ctx).Err()
+	})
+
+	if a.config.Health.CheckWebhook {
+		for _, pc := range a.config.ProviderConfigs() {
+			if pc.Type != config.ProviderTypeWebhook && pc.Type != "" {
+				continue
+			}
+			check, err := hostCheck(pc.Host)
+			if err != nil {
+				slog.Warn("Skipping provider reachability check", "provider", pc.Name, "error", err)
+				continue
+			}
+			a.readiness.Register("provider:"+pc.Name, check)
+		}
+	}
+}
+
+// hostCheck checks that a TCP connection to the host of rawURL can be opened.
+func hostCheck(rawURL string) (app.Checker, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid host %q", rawURL)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	addr := net.JoinHostPort(u.Hostname(), port)
+
+	return func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}, nil
+}
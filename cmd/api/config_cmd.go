@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/muratdemir0/gopulse-messages/internal/config"
+)
+
+const configUsage = `usage: api config validate [-file path]
+
+Loads the config file, applies defaults and GOPULSE_ environment overrides
+and reports every invalid field. The file defaults to .config/$APP_ENV.yaml.
+`
+
+// runConfigCommand runs the config subcommands and returns the exit code.
+func runConfigCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Fprint(stderr, configUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	path := flags.String("file", configPath(), "config file to validate")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(*path)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "%s is invalid:\n", *path)
+		for _, e := range unwrapJoined(err) {
+			fmt.Fprintf(stderr, "  - %v\n", e)
+		}
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "%s is valid\n", *path)
+	return 0
+}
+
+// unwrapJoined splits an errors.Join error into its errors.
+func unwrapJoined(err error) []error {
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
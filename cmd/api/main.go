@@ -8,7 +8,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
-	"strconv"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -17,28 +17,49 @@ import (
 
 	_ "github.com/muratdemir0/gopulse-messages/docs"
 	"github.com/muratdemir0/gopulse-messages/internal/adapters/db"
-	"github.com/muratdemir0/gopulse-messages/internal/adapters/ohttp"
 	"github.com/muratdemir0/gopulse-messages/internal/adapters/redis"
-	"github.com/muratdemir0/gopulse-messages/internal/adapters/webhook"
 	"github.com/muratdemir0/gopulse-messages/internal/app"
 	"github.com/muratdemir0/gopulse-messages/internal/config"
 	"github.com/muratdemir0/gopulse-messages/internal/domain"
+	"github.com/muratdemir0/gopulse-messages/internal/infra/auth"
 	"github.com/muratdemir0/gopulse-messages/internal/infra/cache"
 	"github.com/muratdemir0/gopulse-messages/internal/infra/database"
+	"github.com/muratdemir0/gopulse-messages/internal/infra/events"
 	"github.com/muratdemir0/gopulse-messages/internal/infra/handlers"
 	"github.com/muratdemir0/gopulse-messages/internal/infra/middleware"
+	"github.com/muratdemir0/gopulse-messages/internal/infra/queue"
+	"github.com/muratdemir0/gopulse-messages/internal/infra/ratelimit"
 	"github.com/muratdemir0/gopulse-messages/internal/telemetry"
 	redisclient "github.com/redis/go-redis/v9"
 )
 
 type App struct {
-	config            *config.Config
-	db                *db.Client
-	redis             *redisclient.Client
-	messageService    *app.MessageService
-	server            *http.Server
-	randomMessageRepo *database.MessageRepository
-	tracerProvider    *telemetry.TracerProvider
+	config          *config.Config
+	db              *db.Client
+	redis           *redisclient.Client
+	messageService  *app.MessageService
+	router          *app.Router
+	outboxRelay     *app.OutboxRelay
+	stream          *app.MessageStream
+	queueDispatcher *app.QueueDispatcher
+	listener        *db.Listener
+	stopListening   context.CancelFunc
+	server          *http.Server
+	tracerProvider  *telemetry.TracerProvider
+	meterProvider   *telemetry.MeterProvider
+	loggerProvider  *telemetry.LoggerProvider
+	liveness        *app.CheckRegistry
+	readiness       *app.CheckRegistry
+	authenticator   *auth.Authenticator
+	authPolicy      map[string]auth.Role
+	rateLimiter     ratelimit.Limiter
+	rateLimitPolicy middleware.RateLimitPolicy
+	ipLimiter       ratelimit.Limiter
+	ipLimitPolicy   middleware.RateLimitPolicy
+	providers       *app.ProviderRegistry
+	configWatcher   *config.Watcher
+	liveConfig      atomic.Pointer[config.Config]
+	stopWatching    context.CancelFunc
 }
 
 // @title       GoPulse Messages API
@@ -46,10 +67,21 @@ type App struct {
 // @description GoPulse Messages API
 // @host        localhost:8080
 // @BasePath    /
+//
+// @securityDefinitions.apikey ApiKeyAuth
+// @in                         header
+// @name                       X-API-Key
+//
+// @securityDefinitions.apikey BearerAuth
+// @in                         header
+// @name                       Authorization
+// @description                JWT bearer token, sent as "Bearer <token>"
 func main() {
-	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelDebug,
-	})))
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	slog.SetDefault(newLogger(config.Log{}, newLogHandler()))
 
 	app, err := NewApp()
 	if err != nil {
@@ -85,10 +117,26 @@ func NewApp() (*App, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
+	setLogLevel(cfg.Log.Level)
+	slog.SetDefault(newLogger(cfg.Log, newLogHandler()))
 	slog.Info("Starting application", "name", cfg.App.Name, "port", cfg.App.Port)
 
 	app := &App{config: cfg}
 
+	// Telemetry goes first so the database and redis clients instrument
+	// against the configured providers.
+	if err := app.initTelemetry(); err != nil {
+		slog.Warn("Failed to initialize telemetry", "error", err)
+	}
+
+	if err := app.initMetrics(); err != nil {
+		slog.Warn("Failed to initialize metrics", "error", err)
+	}
+
+	if err := app.initLogging(); err != nil {
+		slog.Warn("Failed to initialize log export", "error", err)
+	}
+
 	if err := app.initDatabase(); err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
@@ -97,12 +145,18 @@ func NewApp() (*App, error) {
 		return nil, fmt.Errorf("failed to initialize redis: %w", err)
 	}
 
-	if err := app.initTelemetry(); err != nil {
-		slog.Warn("Failed to initialize telemetry", "error", err)
+	if err := app.initServices(); err != nil {
+		return nil, fmt.Errorf("failed to initialize services: %w", err)
+	}
+	app.initChecks()
+	if err := app.initAuth(); err != nil {
+		return nil, fmt.Errorf("failed to initialize auth: %w", err)
+	}
+	if err := app.initRateLimit(); err != nil {
+		return nil, fmt.Errorf("failed to initialize rate limiting: %w", err)
 	}
-
-	app.initServices()
 	app.initServer()
+	app.initReload()
 
 	return app, nil
 }
@@ -116,6 +170,35 @@ func (a *App) Start() error {
 		}
 	}()
 
+	if a.outboxRelay != nil {
+		a.outboxRelay.Start()
+	}
+
+	if a.queueDispatcher != nil {
+		a.queueDispatcher.Start()
+	}
+
+	if a.listener != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		a.stopListening = cancel
+		wake := app.NewWakeListener(
+			a.listener.Signals(),
+			a.messageService.Wake,
+			time.Duration(a.config.Database.Notify.DebounceMs)*time.Millisecond,
+			slog.Default(),
+		)
+		go a.listener.Run(ctx)
+		go wake.Run(ctx)
+	}
+
+	if err := a.stream.Start(); err != nil {
+		slog.Warn("failed to start message stream", "error", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopWatching = cancel
+	a.watchConfig(ctx)
+
 	go a.startProducing(context.Background())
 
 	slog.Info("Server starting", "port", a.config.App.Port)
@@ -125,7 +208,18 @@ func (a *App) Start() error {
 func (a *App) Stop() {
 	slog.Info("Starting graceful shutdown...")
 
+	// Fail readiness first and keep serving for the drain delay, so load
+	// balancers see the 503 and stop routing new requests here.
+	a.readiness.Drain()
 	a.server.SetKeepAlivesEnabled(false)
+	if delay := time.Duration(a.config.Health.DrainDelayMs) * time.Millisecond; delay > 0 {
+		slog.Info("Readiness draining, waiting before shutdown", "delay", delay)
+		time.Sleep(delay)
+	}
+
+	if a.stopWatching != nil {
+		a.stopWatching()
+	}
 
 	if err := a.messageService.StopAutoSending(); err != nil {
 		slog.Warn("failed to stop automatic message sending", "error", err)
@@ -133,6 +227,21 @@ func (a *App) Stop() {
 		slog.Info("Automatic message sending stopped")
 	}
 
+	if a.stopListening != nil {
+		a.stopListening()
+	}
+
+	if a.queueDispatcher != nil {
+		a.queueDispatcher.Stop()
+	}
+
+	if a.outboxRelay != nil {
+		a.outboxRelay.Stop()
+	}
+
+	// Open streams would otherwise keep Shutdown waiting.
+	a.stream.Stop()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -156,6 +265,20 @@ func (a *App) Close() {
 		}
 	}
 
+	if a.meterProvider != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := a.meterProvider.Shutdown(ctx); err != nil {
+			slog.Warn("failed to shutdown meter provider", "error", err)
+		}
+	}
+
+	if a.listener != nil {
+		if err := a.listener.Close(); err != nil {
+			slog.Warn("failed to close database listener", "error", err)
+		}
+	}
+
 	if a.db != nil {
 		if err := a.db.Close(); err != nil {
 			slog.Warn("failed to close database connection", "error", err)
@@ -167,6 +290,15 @@ func (a *App) Close() {
 			slog.Warn("failed to close redis connection", "error", err)
 		}
 	}
+
+	// Shut down last so the log records above are still exported.
+	if a.loggerProvider != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := a.loggerProvider.Shutdown(ctx); err != nil {
+			slog.Warn("failed to shutdown logger provider", "error", err)
+		}
+	}
 }
 
 func (a *App) initDatabase() error {
@@ -175,6 +307,7 @@ func (a *App) initDatabase() error {
 		return err
 	}
 	a.db = dbClient
+	a.db.RegisterMetrics()
 	slog.Info("Database connection established")
 	return nil
 }
@@ -213,33 +346,179 @@ func (a *App) initTelemetry() error {
 	return nil
 }
 
-func (a *App) initServices() {
-	clientConfig := ohttp.Config{
-		RetryConfig: &ohttp.RetryConfig{
-			MaxRetries:          3,
-			InitialInterval:     100 * time.Millisecond,
-			RandomizationFactor: 0.5,
-			Multiplier:          2,
-			MaxInterval:         10 * time.Second,
-			MaxElapsedTime:      15 * time.Second,
-		},
-		EnableOpenTelemetry: a.config.Telemetry.Enabled,
-	}
-
-	httpClient := ohttp.NewClient(clientConfig)
-	webhookClient := webhook.NewClient(a.config.Webhook.Host, httpClient)
+// initLogging additionally exports logs over OTLP when telemetry is enabled.
+func (a *App) initLogging() error {
+	if !a.config.Telemetry.Enabled || a.config.Telemetry.OTLPEndpoint == "" {
+		return nil
+	}
+
+	lp, err := telemetry.NewLoggerProvider(a.config.Telemetry.ServiceName, a.config.Telemetry.OTLPEndpoint)
+	if err != nil {
+		return fmt.Errorf("failed to initialize log export: %w", err)
+	}
+
+	a.loggerProvider = lp
+	slog.SetDefault(newLogger(a.config.Log, telemetry.NewFanoutHandler(
+		newLogHandler(),
+		lp.Handler(a.config.Telemetry.ServiceName),
+	)))
+	slog.Info("Log export initialized", "endpoint", a.config.Telemetry.OTLPEndpoint)
+
+	return nil
+}
+
+// newLogger wraps handler so that records are correlated with traces and
+// requests, filtered by the shared log level and redacted before output.
+func newLogger(logConfig config.Log, handler slog.Handler) *slog.Logger {
+	redactor := telemetry.Redactor{LogContent: logConfig.DebugContent}
+	return slog.New(telemetry.NewLogHandler(telemetry.NewLevelHandler(logLevel, telemetry.NewRedactHandler(redactor, handler))))
+}
+
+func newLogHandler() slog.Handler {
+	return slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	})
+}
+
+func (a *App) initMetrics() error {
+	otlpEnabled := a.config.Telemetry.Enabled && a.config.Telemetry.OTLPEndpoint != ""
+	if !a.config.Metrics.Enabled && !otlpEnabled {
+		slog.Info("Metrics disabled")
+		return nil
+	}
+
+	meterConfig := telemetry.MeterConfig{
+		ServiceName:    a.config.Telemetry.ServiceName,
+		ExportInterval: time.Duration(a.config.Telemetry.MetricsIntervalSec) * time.Second,
+		Prometheus:     a.config.Metrics.Enabled,
+	}
+	if otlpEnabled {
+		meterConfig.OTLPEndpoint = a.config.Telemetry.OTLPEndpoint
+	}
+
+	mp, err := telemetry.NewMeterProvider(meterConfig)
+	if err != nil {
+		return fmt.Errorf("failed to initialize metrics: %w", err)
+	}
+
+	a.meterProvider = mp
+	slog.Info("Metrics initialized",
+		"prometheus", meterConfig.Prometheus,
+		"path", a.metricsPath(),
+		"otlp_endpoint", meterConfig.OTLPEndpoint,
+		"export_interval", meterConfig.ExportInterval.String())
+
+	return nil
+}
+
+func (a *App) metricsPath() string {
+	if a.config.Metrics.Path == "" {
+		return "/metrics"
+	}
+	return a.config.Metrics.Path
+}
+
+func (a *App) initServices() error {
+	providers, err := buildProviders(a.config)
+	if err != nil {
+		return err
+	}
+	a.providers = providers
+
 	cache := cache.NewCache(a.redis, 24*time.Hour)
 	messageRepo := database.NewMessageRepository(a.db)
+	routingRuleRepo := database.NewRoutingRuleRepository(a.db)
+	outboxRepo := database.NewOutboxRepository(a.db)
+	a.router = app.NewRouter(routingRuleRepo, providers, slog.Default())
+	a.stream = app.NewMessageStream(
+		events.NewRedisPubSub(a.redis, a.config.Stream.Channel),
+		outboxRepo,
+		uint(max(a.config.Stream.ReplayLimit, 0)),
+		slog.Default(),
+	)
 
+	outbox := app.NewOutbox(a.db, outboxRepo, a.stream)
 	a.messageService = app.NewMessageService(
 		messageRepo,
-		webhookClient,
+		database.NewMessageAttemptRepository(a.db),
+		providers,
+		a.router,
+		outbox,
 		cache,
-		a.config.Webhook.Path,
 		slog.Default(),
 	)
+	a.messageService.Scheduler().SetInterval(time.Duration(a.config.Scheduler.IntervalSec) * time.Second)
 
-	a.randomMessageRepo = messageRepo
+	if a.config.Queue.Enabled {
+		if err := a.initQueue(outbox); err != nil {
+			return err
+		}
+	}
+
+	if a.config.Database.Notify.Enabled {
+		if err := a.initListener(); err != nil {
+			slog.Warn("Failed to listen for new messages, relying on the scheduler", "error", err)
+		}
+	}
+
+	if a.config.Outbox.Enabled {
+		sink, err := buildEventSink(a.config, a.redis)
+		if err != nil {
+			return fmt.Errorf("outbox: %w", err)
+		}
+		a.outboxRelay = app.NewOutboxRelay(
+			a.db,
+			outboxRepo,
+			sink,
+			time.Duration(a.config.Outbox.IntervalMs)*time.Millisecond,
+			uint(max(a.config.Outbox.BatchSize, 0)),
+			slog.Default(),
+		)
+		a.outboxRelay.SetMaxAttempts(a.config.Outbox.MaxAttempts)
+		a.outboxRelay.SetRetention(time.Duration(a.config.Outbox.RetentionSec) * time.Second)
+	}
+
+	return nil
+}
+
+func (a *App) initQueue(outbox *app.Outbox) error {
+	qc := a.config.Queue
+	workQueue := queue.NewRedisStreamQueue(a.redis, queue.Config{
+		Stream:       qc.Stream,
+		Group:        qc.Group,
+		Consumer:     qc.Consumer,
+		Block:        time.Duration(qc.BlockMs) * time.Millisecond,
+		ClaimMinIdle: time.Duration(qc.ClaimMinIdleSec) * time.Second,
+		MaxLen:       qc.MaxLen,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := workQueue.EnsureGroup(ctx); err != nil {
+		return fmt.Errorf("failed to initialize work queue: %w", err)
+	}
+
+	a.queueDispatcher = app.NewQueueDispatcher(workQueue, a.messageService, qc.Workers, qc.BatchSize, slog.Default())
+	outbox.AddListener(a.queueDispatcher)
+
+	sweepDelay := time.Duration(qc.SweepDelaySec) * time.Second
+	if sweepDelay <= 0 {
+		sweepDelay = 2 * time.Minute
+	}
+	a.messageService.SetSweepDelay(sweepDelay)
+
+	slog.Info("Work queue enabled", "stream", qc.Stream, "group", qc.Group, "sweep_delay", sweepDelay)
+	return nil
+}
+
+func (a *App) initListener() error {
+	listener, err := db.NewListener(a.config.Database.DSN, database.MessagesCreatedChannel, slog.Default())
+	if err != nil {
+		return err
+	}
+	a.listener = listener
+	slog.Info("Listening for new messages", "channel", database.MessagesCreatedChannel)
+	return nil
 }
 
 func (a *App) initServer() {
@@ -250,14 +529,44 @@ func (a *App) initServer() {
 func (a *App) setupRoutes() http.Handler {
 	mux := http.NewServeMux()
 	handlers.RegisterHealthHandler(mux)
+	handlers.RegisterProbeHandler(mux, a.liveness, a.readiness, slog.Default())
 	handlers.RegisterMessageHandler(mux, a.messageService, slog.Default())
+	handlers.RegisterMessageStreamHandler(mux, a.stream, time.Duration(a.config.Stream.HeartbeatSec)*time.Second, slog.Default())
+	handlers.RegisterRoutingHandler(mux, a.router, slog.Default())
 
 	handler := httpSwagger.Handler(
 		httpSwagger.URL(fmt.Sprintf("http://localhost:%d/swagger/doc.json", a.config.App.Port)),
 	)
 	mux.Handle("/swagger/", handler)
 
-	wrappedHandler := middleware.Recovery(mux)
+	if a.meterProvider != nil && a.config.Metrics.Enabled {
+		mux.Handle("GET "+a.metricsPath(), a.meterProvider.Handler())
+	}
+
+	var wrappedHandler http.Handler = mux
+	if a.rateLimiter != nil {
+		wrappedHandler = middleware.RateLimit(a.rateLimiter, mux, a.rateLimitPolicy)(wrappedHandler)
+	}
+
+	if a.authenticator != nil {
+		wrappedHandler = middleware.Authorize(a.authenticator, mux, a.authPolicy)(wrappedHandler)
+	}
+
+	if a.ipLimiter != nil {
+		wrappedHandler = middleware.RateLimit(a.ipLimiter, mux, a.ipLimitPolicy)(wrappedHandler)
+	}
+
+	wrappedHandler = middleware.Recovery(wrappedHandler)
+
+	if a.meterProvider != nil {
+		wrappedHandler = middleware.Metrics(wrappedHandler)
+	}
+
+	if a.config.AccessLog.Enabled {
+		wrappedHandler = middleware.AccessLog(slog.Default(), a.config.AccessLog.ExcludePaths)(wrappedHandler)
+	}
+
+	wrappedHandler = middleware.RequestID(wrappedHandler)
 
 	if a.config.Telemetry.Enabled && a.tracerProvider != nil {
 		wrappedHandler = middleware.Tracing(a.config.Telemetry.ServiceName)(wrappedHandler)
@@ -268,10 +577,10 @@ func (a *App) setupRoutes() http.Handler {
 }
 
 func (a *App) setupHTTPServer(handler http.Handler) *http.Server {
-	readTimeout := getTimeoutValue(a.config.App.ReadTimeout, 30)
-	writeTimeout := getTimeoutValue(a.config.App.WriteTimeout, 30)
-	idleTimeout := getTimeoutValue(a.config.App.IdleTimeout, 120)
-	maxHeaderBytes := getHeaderSize(a.config.App.MaxHeaderMB)
+	readTimeout := a.config.App.ReadTimeout
+	writeTimeout := a.config.App.WriteTimeout
+	idleTimeout := a.config.App.IdleTimeout
+	maxHeaderBytes := a.config.App.MaxHeaderMB << 20
 
 	slog.Info("Server configuration",
 		slog.Int("read_timeout_sec", readTimeout),
@@ -291,38 +600,13 @@ func (a *App) setupHTTPServer(handler http.Handler) *http.Server {
 }
 
 func loadConfig() (*config.Config, error) {
-	env := os.Getenv("APP_ENV")
-	if env == "" {
-		env = "dev"
-	}
-
-	configPath := filepath.Join(".config", fmt.Sprintf("%s.yaml", env))
-	cfg, err := config.Load(configPath)
+	cfg, err := config.Load(configPath())
 	if err != nil {
 		return nil, err
 	}
 
-	if dsn := os.Getenv("DATABASE_DSN"); dsn != "" {
-		cfg.Database.DSN = dsn
-	}
-
-	if addr := os.Getenv("REDIS_ADDR"); addr != "" {
-		cfg.Redis.Addr = addr
-	}
-	if password := os.Getenv("REDIS_PASSWORD"); password != "" {
-		cfg.Redis.Password = password
-	}
-	if db := os.Getenv("REDIS_DB"); db != "" {
-		if d, err := strconv.Atoi(db); err == nil {
-			cfg.Redis.DB = d
-		}
-	}
-
-	if endpoint := os.Getenv("TELEMETRY_OTLP_ENDPOINT"); endpoint != "" {
-		cfg.Telemetry.OTLPEndpoint = endpoint
-	}
-	if enabled := os.Getenv("TELEMETRY_ENABLED"); enabled != "" {
-		cfg.Telemetry.Enabled = enabled == "true"
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
 	}
 
 	slog.Info("Database DSN", "dsn", cfg.Database.DSN)
@@ -332,6 +616,16 @@ func loadConfig() (*config.Config, error) {
 	return cfg, nil
 }
 
+// configPath returns the config file of the APP_ENV environment, dev by
+// default.
+func configPath() string {
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "dev"
+	}
+	return filepath.Join(".config", fmt.Sprintf("%s.yaml", env))
+}
+
 func connectDatabase(cfg *config.Config) (*db.Client, error) {
 	dsn := cfg.Database.DSN
 	slog.Info("Connecting to database", "dsn", dsn)
@@ -346,20 +640,6 @@ func connectRedis(cfg *config.Config) (*redisclient.Client, error) {
 	return redisClient, nil
 }
 
-func getTimeoutValue(configValue, defaultValue int) int {
-	if configValue > 0 {
-		return configValue
-	}
-	return defaultValue
-}
-
-func getHeaderSize(maxHeaderMB int) int {
-	if maxHeaderMB > 0 {
-		return maxHeaderMB << 20
-	}
-	return 1 << 20 // 1 MB default
-}
-
 type FakeMessage struct {
 	Recipient string `faker:"phone_number"`
 	Content   string `faker:"sentence"`
@@ -385,7 +665,7 @@ func (a *App) startProducing(ctx context.Context) {
 				Status:    "pending",
 			}
 
-			if err := a.randomMessageRepo.Create(ctx, message); err != nil {
+			if err := a.messageService.CreateMessage(ctx, message); err != nil {
 				slog.Error("failed to create message", "error", err)
 			} else {
 				slog.Info("Successfully created a new message")
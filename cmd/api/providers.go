@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/ohttp"
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/smtp"
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/webhook"
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/muratdemir0/gopulse-messages/internal/config"
+	"github.com/muratdemir0/gopulse-messages/internal/infra/events"
+	redisclient "github.com/redis/go-redis/v9"
+)
+
+func buildProviders(cfg *config.Config) (*app.ProviderRegistry, error) {
+	var providers []app.Provider
+
+	for _, pc := range cfg.ProviderConfigs() {
+		switch pc.Type {
+		case config.ProviderTypeWebhook, "":
+			provider, err := newWebhookProvider(pc, cfg.Telemetry.Enabled)
+			if err != nil {
+				return nil, fmt.Errorf("provider %s: %w", pc.Name, err)
+			}
+			providers = append(providers, provider)
+		case config.ProviderTypeSMTP:
+			providers = append(providers, newSMTPProvider(pc))
+		default:
+			return nil, fmt.Errorf("provider %s: unsupported type %q", pc.Name, pc.Type)
+		}
+
+		slog.Info("Provider configured", "name", pc.Name, "type", pc.Type, "host", pc.Host)
+	}
+
+	return app.NewProviderRegistry(providers...)
+}
+
+func newWebhookProvider(pc config.Provider, telemetryEnabled bool) (*webhook.Provider, error) {
+	requestTemplate, err := webhook.NewRequestTemplate(pc.Request.Format, pc.Request.Body, pc.Request.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := ohttp.NewClient(ohttp.Config{
+		Name:                pc.Name,
+		RetryConfig:         retryConfig(pc.Retry),
+		CircuitBreaker:      circuitBreakerConfig(pc.CircuitBreaker),
+		EnableOpenTelemetry: telemetryEnabled,
+	})
+
+	client := webhook.NewClient(pc.Host, httpClient, webhook.Config{
+		SigningSecrets: pc.Signing.Secrets(),
+		Auth: webhook.Auth{
+			Type:     pc.Auth.Type,
+			Token:    pc.Auth.Token,
+			Username: pc.Auth.Username,
+			Password: pc.Auth.Password,
+			Header:   pc.Auth.Header,
+			Value:    pc.Auth.Value,
+		},
+		Request:  requestTemplate,
+		Response: responseMapping(pc.Response),
+	})
+
+	return webhook.NewProvider(pc.Name, pc.Path, client, app.Capabilities{
+		MaxContentLength: pc.MaxContentLength,
+		Idempotent:       true,
+	}), nil
+}
+
+func responseMapping(r config.Response) *webhook.ResponseMapping {
+	mapping := webhook.DefaultResponseMapping
+	if r.MessageIDPath != "" {
+		mapping.MessageIDPath = r.MessageIDPath
+	}
+	if r.StatusPath != "" {
+		mapping.StatusPath = r.StatusPath
+	}
+	mapping.AcceptedStatuses = r.AcceptedStatuses
+	return &mapping
+}
+
+func newSMTPProvider(pc config.Provider) *smtp.Provider {
+	port := pc.SMTP.Port
+	if port == 0 {
+		port = 587
+	}
+
+	client := smtp.NewClient(smtp.Config{
+		Host:     pc.Host,
+		Port:     port,
+		Username: pc.SMTP.Username,
+		Password: pc.SMTP.Password,
+		From:     pc.SMTP.From,
+		TLS:      pc.SMTP.TLS,
+		Timeout:  time.Duration(pc.SMTP.TimeoutSec) * time.Second,
+	})
+
+	return smtp.NewProvider(pc.Name, client, app.Capabilities{
+		MaxContentLength: pc.MaxContentLength,
+	})
+}
+
+func buildEventSink(cfg *config.Config, redis *redisclient.Client) (app.EventSink, error) {
+	oc := cfg.Outbox
+	switch oc.Sink {
+	case config.OutboxSinkRedisStream, "":
+		slog.Info("Outbox sink configured", "type", config.OutboxSinkRedisStream, "stream", oc.RedisStream.Stream)
+		return events.NewRedisStreamSink(redis, oc.RedisStream.Stream, oc.RedisStream.MaxLen), nil
+	case config.OutboxSinkHTTP:
+		if oc.HTTP.URL == "" {
+			return nil, errors.New("http sink requires a url")
+		}
+		httpClient := ohttp.NewClient(ohttp.Config{Name: "outbox", EnableOpenTelemetry: cfg.Telemetry.Enabled})
+		slog.Info("Outbox sink configured", "type", config.OutboxSinkHTTP, "url", oc.HTTP.URL)
+		return events.NewHTTPSink(
+			oc.HTTP.URL,
+			httpClient,
+			oc.HTTP.Signing.Secrets(),
+			time.Duration(oc.HTTP.TimeoutSec)*time.Second,
+		), nil
+	default:
+		return nil, fmt.Errorf("unsupported sink %q", oc.Sink)
+	}
+}
+
+func retryConfig(r config.Retry) *ohttp.RetryConfig {
+	rc := &ohttp.RetryConfig{
+		MaxRetries:          3,
+		InitialInterval:     100 * time.Millisecond,
+		RandomizationFactor: 0.5,
+		Multiplier:          2,
+		MaxInterval:         10 * time.Second,
+		MaxElapsedTime:      15 * time.Second,
+	}
+
+	if r.MaxRetries > 0 {
+		rc.MaxRetries = uint64(r.MaxRetries)
+	}
+	if r.InitialIntervalMs > 0 {
+		rc.InitialInterval = time.Duration(r.InitialIntervalMs) * time.Millisecond
+	}
+	if r.RandomizationFactor > 0 {
+		rc.RandomizationFactor = r.RandomizationFactor
+	}
+	if r.Multiplier > 0 {
+		rc.Multiplier = r.Multiplier
+	}
+	if r.MaxIntervalMs > 0 {
+		rc.MaxInterval = time.Duration(r.MaxIntervalMs) * time.Millisecond
+	}
+	if r.MaxElapsedTimeMs > 0 {
+		rc.MaxElapsedTime = time.Duration(r.MaxElapsedTimeMs) * time.Millisecond
+	}
+
+	return rc
+}
+
+func circuitBreakerConfig(cb config.CircuitBreaker) *ohttp.CircuitBreakerConfig {
+	if !cb.Enabled {
+		return nil
+	}
+
+	return &ohttp.CircuitBreakerConfig{
+		FailureRateThreshold: cb.FailureRateThreshold,
+		MinRequests:          cb.MinRequests,
+		WindowSize:           cb.WindowSize,
+		CoolDown:             time.Duration(cb.CoolDownSec) * time.Second,
+		HalfOpenMaxRequests:  cb.HalfOpenMaxRequests,
+	}
+}
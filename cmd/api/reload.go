@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/config"
+)
+
+// logLevel is shared by every log handler so a reload can change it.
+var logLevel = new(slog.LevelVar)
+
+// endpointSetter is implemented by providers whose endpoint can change at
+// runtime, such as webhook providers.
+type endpointSetter interface {
+	SetEndpoint(host, path string)
+}
+
+// initReload watches the config file and applies the keys listed by
+// config.Reloadable to the running components.
+func (a *App) initReload() {
+	a.liveConfig.Store(a.config)
+	a.configWatcher = config.NewWatcher(configPath(), a.config, slog.Default())
+	a.configWatcher.Subscribe(a.applyConfig)
+}
+
+// applyConfig pushes a reloaded config to the running components and keeps
+// it in a.liveConfig. a.config stays the config the app was started with,
+// which structural keys such as the DSN still follow.
+func (a *App) applyConfig(cfg *config.Config) {
+	prev := a.liveConfig.Swap(cfg)
+	setLogLevel(cfg.Log.Level)
+
+	if a.tracerProvider != nil {
+		a.tracerProvider.SetSampleRate(cfg.Telemetry.SampleRate)
+	}
+
+	a.messageService.Scheduler().SetInterval(time.Duration(cfg.Scheduler.IntervalSec) * time.Second)
+	if a.config.Queue.Enabled && cfg.Queue.SweepDelaySec > 0 {
+		a.messageService.SetSweepDelay(time.Duration(cfg.Queue.SweepDelaySec) * time.Second)
+	}
+
+	for _, pc := range cfg.ProviderConfigs() {
+		if pc.Type != config.ProviderTypeWebhook && pc.Type != "" {
+			continue
+		}
+		provider, ok := a.providers.Get(pc.Name)
+		if !ok {
+			if !hasProvider(prev, pc.Name) {
+				slog.Warn("Provider added to config, restart to enable it", "name", pc.Name)
+			}
+			continue
+		}
+		if setter, ok := provider.(endpointSetter); ok {
+			setter.SetEndpoint(pc.Host, pc.Path)
+		}
+	}
+}
+
+// watchConfig reloads the config when the file changes or the process
+// receives SIGHUP, until ctx is done.
+func (a *App) watchConfig(ctx context.Context) {
+	if err := a.configWatcher.Watch(ctx); err != nil {
+		slog.Warn("Failed to watch config file, send SIGHUP to reload", "error", err)
+	}
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hangup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				slog.Info("SIGHUP received, reloading config")
+				_ = a.configWatcher.Reload()
+			}
+		}
+	}()
+}
+
+// hasProvider reports whether cfg configures a provider named name, so a
+// provider added to the file is only reported on the reload that added it.
+func hasProvider(cfg *config.Config, name string) bool {
+	return slices.ContainsFunc(cfg.ProviderConfigs(), func(pc config.Provider) bool {
+		return pc.Name == name
+	})
+}
+
+func setLogLevel(level string) {
+	if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+		slog.Warn("Invalid log level, keeping the current one", "level", level, "error", err)
+	}
+}
@@ -75,6 +75,16 @@ func NewDB(dsn string) *Client {
 	return &Client{db: db, Goqu: goqu.New("default", db)}
 }
 
+func (c *Client) Ping(ctx context.Context) error {
+	return c.db.PingContext(ctx)
+}
+
+// RegisterMetrics reports connection pool statistics through the global OTel
+// meter provider.
+func (c *Client) RegisterMetrics() {
+	otelsql.ReportDBStatsMetrics(c.db.DB, otelsql.WithAttributes(semconv.DBSystemPostgreSQL))
+}
+
 func (c *Client) Close() error {
 	return c.db.Close()
 }
@@ -256,6 +266,30 @@ func (c *Client) Update(ctx context.Context, query *goqu.UpdateDataset) (sql.Res
 	return res, nil
 }
 
+// UpdateReturning runs an update with a RETURNING clause and scans the
+// returned rows into dest, a pointer to a slice.
+func (c *Client) UpdateReturning(ctx context.Context, dest any, query *goqu.UpdateDataset) error {
+	q, args, err := query.ToSQL()
+	if err != nil {
+		return fmt.Errorf("unable to build query: %w", err)
+	}
+
+	var cancel context.CancelFunc
+	ctx, cancel = context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	if tx := c.getTx(ctx); tx != nil {
+		err = tx.SelectContext(ctx, dest, q, args...)
+	} else {
+		err = c.db.SelectContext(ctx, dest, q, args...)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to execute update query: %w", err)
+	}
+	return nil
+}
+
 func (c *Client) Delete(ctx context.Context, query *goqu.DeleteDataset) (sql.Result, error) {
 	q, args, err := query.ToSQL()
 	if err != nil {
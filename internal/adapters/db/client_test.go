@@ -18,7 +18,10 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
-var testDB *Client
+var (
+	testDB  *Client
+	testDSN string
+)
 
 func TestMain(m *testing.M) {
 	ctx := context.Background()
@@ -61,6 +64,7 @@ func TestMain(m *testing.M) {
 		os.Getenv("DB_NAME"),
 	)
 
+	testDSN = dsn
 	testDB = NewDB(dsn)
 
 	_, err = testDB.Goqu.ExecContext(ctx, `
@@ -246,6 +250,39 @@ func TestClient_Update(t *testing.T) {
 	assert.Equal(t, newEmail, user.Email)
 }
 
+func TestClient_UpdateReturning(t *testing.T) {
+	client, ctx := setupTest(t)
+
+	email := "returning@example.com"
+	insertDS := client.Goqu.Insert("users").Rows(
+		goqu.Record{
+			"first_name":    "John",
+			"last_name":     "Doe",
+			"email":         email,
+			"password_hash": "hash123",
+			"created_at":    time.Now(),
+		},
+	)
+
+	result, err := client.Insert(ctx, insertDS)
+	require.NoError(t, err)
+
+	id, err := result.LastInsertId()
+	require.NoError(t, err)
+
+	updateDS := client.Goqu.Update("users").
+		Set(goqu.Record{"first_name": "Jane"}).
+		Where(goqu.Ex{"id": id}).
+		Returning(goqu.Star())
+
+	var users []TestUser
+	err = client.UpdateReturning(ctx, &users, updateDS)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "Jane", users[0].FirstName)
+	assert.Equal(t, email, users[0].Email)
+}
+
 func TestClient_Delete(t *testing.T) {
 	client, ctx := setupTest(t)
 
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	DefaultMinReconnectInterval = time.Second
+	DefaultMaxReconnectInterval = time.Minute
+	listenerPingInterval        = 90 * time.Second
+)
+
+// Listener receives Postgres NOTIFY messages on one channel over a dedicated
+// connection. The connection is re-established with backoff when it drops.
+type Listener struct {
+	listener *pq.Listener
+	channel  string
+	signals  chan struct{}
+	logger   *slog.Logger
+}
+
+// NewListener opens a listener for channel. Signals carries one value per
+// notification, plus one after every reconnect since notifications sent while
+// disconnected are lost.
+func NewListener(dsn, channel string, logger *slog.Logger) (*Listener, error) {
+	l := &Listener{
+		channel: channel,
+		signals: make(chan struct{}, 1),
+		logger:  logger.With(slog.String("component", "db_listener"), slog.String("channel", channel)),
+	}
+
+	l.listener = pq.NewListener(dsn, DefaultMinReconnectInterval, DefaultMaxReconnectInterval, l.onEvent)
+	if err := l.listener.Listen(channel); err != nil {
+		_ = l.listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", channel, err)
+	}
+
+	return l, nil
+}
+
+func (l *Listener) onEvent(event pq.ListenerEventType, err error) {
+	switch event {
+	case pq.ListenerEventConnectionAttemptFailed:
+		l.logger.Warn("Listener connection attempt failed", "error", err)
+	case pq.ListenerEventDisconnected:
+		l.logger.Warn("Listener disconnected", "error", err)
+	case pq.ListenerEventReconnected:
+		l.logger.Info("Listener reconnected")
+	}
+}
+
+// Signals returns a channel that receives a value whenever a notification
+// arrives. Signals are coalesced while the reader is busy. The channel is
+// closed when Run returns.
+func (l *Listener) Signals() <-chan struct{} {
+	return l.signals
+}
+
+// Run forwards notifications until ctx is done, pinging the server
+// periodically so dead connections are noticed.
+func (l *Listener) Run(ctx context.Context) {
+	defer close(l.signals)
+
+	ping := time.NewTicker(listenerPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case _, ok := <-l.listener.Notify:
+			if !ok {
+				return
+			}
+			// A nil notification follows a reconnect; it signals as well.
+			select {
+			case l.signals <- struct{}{}:
+			default:
+			}
+		case <-ping.C:
+			go func() {
+				if err := l.listener.Ping(); err != nil {
+					l.logger.Warn("Listener ping failed", "error", err)
+				}
+			}()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (l *Listener) Close() error {
+	return l.listener.Close()
+}
@@ -0,0 +1,39 @@
+//go:build integration
+
+package db
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListener_Signals(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	listener, err := NewListener(testDSN, "test_listener", logger)
+	require.NoError(t, err)
+	defer listener.Close() //nolint:errcheck
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go listener.Run(ctx)
+
+	_, err = testDB.Goqu.ExecContext(ctx, "NOTIFY test_listener, '1'")
+	require.NoError(t, err)
+
+	select {
+	case <-listener.Signals():
+	case <-time.After(5 * time.Second):
+		t.Fatal("no signal received")
+	}
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, ok := <-listener.Signals()
+		return !ok
+	}, time.Second, 10*time.Millisecond, "signals channel is closed when Run returns")
+}
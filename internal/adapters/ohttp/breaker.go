@@ -0,0 +1,290 @@
+package ohttp
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	DefaultFailureRateThreshold = 0.5
+	DefaultMinRequests          = 10
+	DefaultWindowSize           = 20
+	DefaultCoolDown             = 30 * time.Second
+	DefaultHalfOpenMaxRequests  = 1
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig configures the per-host circuit breaker. The breaker
+// opens once at least MinRequests outcomes have been observed in the last
+// WindowSize requests and the share of failures reaches FailureRateThreshold.
+type CircuitBreakerConfig struct {
+	FailureRateThreshold float64
+	MinRequests          int
+	WindowSize           int
+	CoolDown             time.Duration
+	HalfOpenMaxRequests  int
+}
+
+type circuitBreaker struct {
+	cfg CircuitBreakerConfig
+	now func() time.Time
+
+	mu               sync.Mutex
+	state            BreakerState
+	window           []bool
+	next             int
+	count            int
+	failures         int
+	openedAt         time.Time
+	halfOpenInFlight int
+	halfOpenSuccess  int
+}
+
+func newCircuitBreaker(cfg CircuitBreakerConfig, now func() time.Time) *circuitBreaker {
+	if cfg.FailureRateThreshold <= 0 || cfg.FailureRateThreshold > 1 {
+		cfg.FailureRateThreshold = DefaultFailureRateThreshold
+	}
+	if cfg.WindowSize <= 0 {
+		cfg.WindowSize = DefaultWindowSize
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = DefaultMinRequests
+	}
+	if cfg.MinRequests > cfg.WindowSize {
+		cfg.MinRequests = cfg.WindowSize
+	}
+	if cfg.CoolDown <= 0 {
+		cfg.CoolDown = DefaultCoolDown
+	}
+	if cfg.HalfOpenMaxRequests <= 0 {
+		cfg.HalfOpenMaxRequests = DefaultHalfOpenMaxRequests
+	}
+
+	return &circuitBreaker{
+		cfg:    cfg,
+		now:    now,
+		state:  BreakerClosed,
+		window: make([]bool, cfg.WindowSize),
+	}
+}
+
+func (b *circuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance()
+	return b.state
+}
+
+// allow reports whether a request may be sent. In the half-open state only
+// HalfOpenMaxRequests probes are let through until their outcome is known.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance()
+
+	switch b.state {
+	case BreakerOpen:
+		return ErrCircuitOpen
+	case BreakerHalfOpen:
+		if b.halfOpenInFlight >= b.cfg.HalfOpenMaxRequests {
+			return ErrCircuitOpen
+		}
+		b.halfOpenInFlight++
+	}
+
+	return nil
+}
+
+func (b *circuitBreaker) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerHalfOpen:
+		if b.halfOpenInFlight > 0 {
+			b.halfOpenInFlight--
+		}
+		if !success {
+			b.trip()
+			return
+		}
+		b.halfOpenSuccess++
+		if b.halfOpenSuccess >= b.cfg.HalfOpenMaxRequests {
+			b.reset()
+		}
+	case BreakerClosed:
+		b.observe(success)
+		if b.count >= b.cfg.MinRequests &&
+			float64(b.failures)/float64(b.count) >= b.cfg.FailureRateThreshold {
+			b.trip()
+		}
+	}
+}
+
+func (b *circuitBreaker) advance() {
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cfg.CoolDown {
+		b.state = BreakerHalfOpen
+		b.halfOpenInFlight = 0
+		b.halfOpenSuccess = 0
+	}
+}
+
+func (b *circuitBreaker) observe(success bool) {
+	if b.count == len(b.window) {
+		if !b.window[b.next] {
+			b.failures--
+		}
+	} else {
+		b.count++
+	}
+
+	b.window[b.next] = success
+	if !success {
+		b.failures++
+	}
+	b.next = (b.next + 1) % len(b.window)
+}
+
+func (b *circuitBreaker) trip() {
+	b.state = BreakerOpen
+	b.openedAt = b.now()
+	b.halfOpenInFlight = 0
+	b.halfOpenSuccess = 0
+}
+
+func (b *circuitBreaker) reset() {
+	b.state = BreakerClosed
+	b.next = 0
+	b.count = 0
+	b.failures = 0
+	b.halfOpenInFlight = 0
+	b.halfOpenSuccess = 0
+}
+
+type breakerRegistry struct {
+	name string
+	cfg  CircuitBreakerConfig
+	now  func() time.Time
+
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+func newBreakerRegistry(name string, cfg CircuitBreakerConfig) *breakerRegistry {
+	return &breakerRegistry{
+		name:     name,
+		cfg:      cfg,
+		now:      time.Now,
+		breakers: make(map[string]*circuitBreaker),
+	}
+}
+
+func (r *breakerRegistry) get(host string) *circuitBreaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.breakers[host]
+	if !ok {
+		b = newCircuitBreaker(r.cfg, r.now)
+		r.breakers[host] = b
+	}
+	return b
+}
+
+func (r *breakerRegistry) states() map[string]BreakerState {
+	r.mu.Lock()
+	hosts := make(map[string]*circuitBreaker, len(r.breakers))
+	for host, b := range r.breakers {
+		hosts[host] = b
+	}
+	r.mu.Unlock()
+
+	states := make(map[string]BreakerState, len(hosts))
+	for host, b := range hosts {
+		states[host] = b.State()
+	}
+	return states
+}
+
+// breakerMetrics holds the breaker registry of every named client behind a
+// single observable gauge, so that clients sharing a host report one point
+// per client instead of conflicting points for the same attribute set. A
+// client rebuilt under the same name replaces the previous registry, which
+// can then be garbage collected.
+var breakerMetrics struct {
+	once       sync.Once
+	mu         sync.Mutex
+	registries map[string]*breakerRegistry
+}
+
+// registerMetrics exposes the breaker state per client and host as an
+// observable gauge: 0 closed, 1 open, 2 half-open.
+func (r *breakerRegistry) registerMetrics() {
+	breakerMetrics.mu.Lock()
+	if breakerMetrics.registries == nil {
+		breakerMetrics.registries = make(map[string]*breakerRegistry)
+	}
+	breakerMetrics.registries[r.name] = r
+	breakerMetrics.mu.Unlock()
+
+	breakerMetrics.once.Do(func() {
+		meter := otel.Meter("github.com/muratdemir0/gopulse-messages/internal/adapters/ohttp")
+
+		_, err := meter.Int64ObservableGauge(
+			"ohttp.circuit_breaker.state",
+			metric.WithDescription("Circuit breaker state per client and host (0=closed, 1=open, 2=half_open)"),
+			metric.WithInt64Callback(observeBreakers),
+		)
+		if err != nil {
+			otel.Handle(err)
+		}
+	})
+}
+
+func observeBreakers(_ context.Context, o metric.Int64Observer) error {
+	breakerMetrics.mu.Lock()
+	registries := slices.Collect(maps.Values(breakerMetrics.registries))
+	breakerMetrics.mu.Unlock()
+
+	for _, r := range registries {
+		for host, state := range r.states() {
+			o.Observe(int64(state), metric.WithAttributes(
+				attribute.String("client", r.name),
+				attribute.String("host", host),
+			))
+		}
+	}
+	return nil
+}
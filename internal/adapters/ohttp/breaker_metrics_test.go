@@ -0,0 +1,85 @@
+//go:build unit
+
+package ohttp_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/ohttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestCircuitBreaker_Metrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Run("Given two clients for the same host, each reports its own point", func(t *testing.T) {
+		for _, name := range []string{"primary", "backup"} {
+			client := ohttp.NewClient(ohttp.Config{
+				Name:           name,
+				CircuitBreaker: &ohttp.CircuitBreakerConfig{},
+			})
+			_, err := doGet(t, client, server.URL)
+			require.NoError(t, err)
+		}
+
+		clients := breakerClients(t, reader, mustHost(t, server.URL))
+		assert.ElementsMatch(t, []string{"primary", "backup"}, clients)
+	})
+
+	t.Run("Given a client rebuilt under the same name, only the new one is reported", func(t *testing.T) {
+		for range 2 {
+			client := ohttp.NewClient(ohttp.Config{
+				Name:           "rebuilt",
+				CircuitBreaker: &ohttp.CircuitBreakerConfig{},
+			})
+			_, err := doGet(t, client, server.URL)
+			require.NoError(t, err)
+		}
+
+		clients := breakerClients(t, reader, mustHost(t, server.URL))
+		assert.ElementsMatch(t, []string{"primary", "backup", "rebuilt"}, clients)
+	})
+}
+
+// breakerClients returns the client attribute of every breaker state point
+// reported for host.
+func breakerClients(t *testing.T, reader sdkmetric.Reader, host string) []string {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	var clients []string
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "ohttp.circuit_breaker.state" {
+				continue
+			}
+			gauge, ok := m.Data.(metricdata.Gauge[int64])
+			require.True(t, ok)
+			for _, point := range gauge.DataPoints {
+				pointHost, _ := point.Attributes.Value(attribute.Key("host"))
+				if pointHost.AsString() != host {
+					continue
+				}
+				client, _ := point.Attributes.Value(attribute.Key("client"))
+				clients = append(clients, client.AsString())
+				assert.Equal(t, int64(ohttp.BreakerClosed), point.Value)
+			}
+		}
+	}
+	return clients
+}
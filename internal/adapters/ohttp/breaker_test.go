@@ -0,0 +1,165 @@
+//go:build unit
+
+package ohttp_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/ohttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBreakerClient(coolDown time.Duration) *ohttp.Client {
+	return ohttp.NewClient(ohttp.Config{
+		CircuitBreaker: &ohttp.CircuitBreakerConfig{
+			FailureRateThreshold: 0.5,
+			MinRequests:          4,
+			WindowSize:           4,
+			CoolDown:             coolDown,
+			HalfOpenMaxRequests:  1,
+		},
+	})
+}
+
+func doGet(t *testing.T, client *ohttp.Client, target string) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+	return resp, err
+}
+
+func TestCircuitBreaker_OpensOnFailureRate(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := newBreakerClient(time.Hour)
+	host := mustHost(t, server.URL)
+
+	for i := 0; i < 4; i++ {
+		_, err := doGet(t, client, server.URL)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, ohttp.BreakerOpen, client.BreakerStates()[host])
+
+	_, err := doGet(t, client, server.URL)
+	assert.True(t, errors.Is(err, ohttp.ErrCircuitOpen))
+	assert.Equal(t, int32(4), atomic.LoadInt32(&hits), "open breaker must not reach the server")
+}
+
+func TestCircuitBreaker_StaysClosedBelowThreshold(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1)%4 == 0 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := newBreakerClient(time.Hour)
+
+	for i := 0; i < 8; i++ {
+		_, err := doGet(t, client, server.URL)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, ohttp.BreakerClosed, client.BreakerStates()[mustHost(t, server.URL)])
+}
+
+func TestCircuitBreaker_HalfOpenRecovers(t *testing.T) {
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if healthy.Load() {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := newBreakerClient(20 * time.Millisecond)
+	host := mustHost(t, server.URL)
+
+	for i := 0; i < 4; i++ {
+		_, _ = doGet(t, client, server.URL)
+	}
+	require.Equal(t, ohttp.BreakerOpen, client.BreakerStates()[host])
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, ohttp.BreakerHalfOpen, client.BreakerStates()[host])
+
+	healthy.Store(true)
+	resp, err := doGet(t, client, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, ohttp.BreakerClosed, client.BreakerStates()[host])
+}
+
+func TestCircuitBreaker_HalfOpenFailureReopens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := newBreakerClient(20 * time.Millisecond)
+	host := mustHost(t, server.URL)
+
+	for i := 0; i < 4; i++ {
+		_, _ = doGet(t, client, server.URL)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	_, err := doGet(t, client, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, ohttp.BreakerOpen, client.BreakerStates()[host])
+}
+
+func TestCircuitBreaker_RetryStopsWhenOpen(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := ohttp.NewClient(ohttp.Config{
+		RetryConfig: &ohttp.RetryConfig{
+			MaxRetries:      10,
+			InitialInterval: time.Millisecond,
+			MaxInterval:     time.Millisecond,
+		},
+		CircuitBreaker: &ohttp.CircuitBreakerConfig{
+			FailureRateThreshold: 1,
+			MinRequests:          3,
+			WindowSize:           3,
+			CoolDown:             time.Hour,
+		},
+	})
+
+	_, err := doGet(t, client, server.URL)
+	assert.True(t, errors.Is(err, ohttp.ErrCircuitOpen))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+}
+
+func mustHost(t *testing.T, rawURL string) string {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	return u.Host
+}
@@ -1,14 +1,18 @@
 package ohttp
 
 import (
+	"bytes"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
@@ -24,7 +28,8 @@ const (
 	DefaultExpectContinueTimeout = 1 * time.Second
 	DefaultRequestTimeout        = 30 * time.Second
 
-	HeaderRetryAttempt = "X-Retry-Attempt"
+	HeaderRetryAttempt   = "X-Retry-Attempt"
+	HeaderIdempotencyKey = "Idempotency-Key"
 )
 
 const (
@@ -43,17 +48,28 @@ type RetryConfig struct {
 	Multiplier          float64
 	MaxInterval         time.Duration
 	MaxElapsedTime      time.Duration
+	// Classifier decides which attempts are retried. Defaults to
+	// DefaultRetryClassifier.
+	Classifier RetryClassifier
+	// MaxRetryAfter caps how long a Retry-After header may delay the next
+	// attempt. Longer delays stop the retry loop. Defaults to DefaultMaxRetryAfter.
+	MaxRetryAfter time.Duration
 }
 
 type Config struct {
+	// Name identifies the client in metrics, such as the provider it serves.
+	// A client built with the same name replaces the previous one.
+	Name                string
 	Transport           *http.Transport
 	RetryConfig         *RetryConfig
+	CircuitBreaker      *CircuitBreakerConfig
 	EnableOpenTelemetry bool
 }
 
 type Client struct {
 	httpClient  *http.Client
 	retryConfig *RetryConfig
+	breakers    *breakerRegistry
 }
 
 func DefaultTransport() *http.Transport {
@@ -75,6 +91,8 @@ func NewClient(configs ...Config) *Client {
 		if userConfig.RetryConfig != nil {
 			cfg.RetryConfig = userConfig.RetryConfig
 		}
+		cfg.Name = userConfig.Name
+		cfg.CircuitBreaker = userConfig.CircuitBreaker
 		cfg.EnableOpenTelemetry = userConfig.EnableOpenTelemetry
 	}
 
@@ -83,13 +101,29 @@ func NewClient(configs ...Config) *Client {
 		transport = otelhttp.NewTransport(cfg.Transport)
 	}
 
-	return &Client{
+	client := &Client{
 		httpClient: &http.Client{
 			Transport: transport,
 			Timeout:   DefaultRequestTimeout,
 		},
 		retryConfig: cfg.RetryConfig,
 	}
+
+	if cfg.CircuitBreaker != nil {
+		client.breakers = newBreakerRegistry(cfg.Name, *cfg.CircuitBreaker)
+		client.breakers.registerMetrics()
+	}
+
+	return client
+}
+
+// BreakerStates returns the current circuit breaker state for every host the
+// client has talked to. It is empty when no circuit breaker is configured.
+func (c *Client) BreakerStates() map[string]BreakerState {
+	if c.breakers == nil {
+		return map[string]BreakerState{}
+	}
+	return c.breakers.states()
 }
 
 func createOptimizedTransport() *http.Transport {
@@ -118,34 +152,51 @@ func createOptimizedTransport() *http.Transport {
 	}
 }
 
+// Do sends the request, retrying it when a RetryConfig is set. Every request
+// carries an Idempotency-Key header, generated unless the caller already set
+// one, which stays the same across all retry attempts.
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if req.Header.Get(HeaderIdempotencyKey) == "" {
+		req.Header.Set(HeaderIdempotencyKey, uuid.NewString())
+	}
+
 	if c.retryConfig == nil {
-		return c.httpClient.Do(req)
+		return c.send(req)
 	}
 	return c.doWithRetry(req)
 }
 
+// send performs a single attempt, guarded by the host's circuit breaker when
+// one is configured. Transport errors and 5xx responses count as failures.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.breakers == nil {
+		return c.httpClient.Do(req)
+	}
+
+	breaker := c.breakers.get(req.URL.Host)
+	if err := breaker.allow(); err != nil {
+		return nil, fmt.Errorf("%s: %w", req.URL.Host, err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	breaker.record(err == nil && resp.StatusCode < 500)
+
+	return resp, err
+}
+
 func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
 	var resp *http.Response
 	var attempt int
 
-	operation := func() error {
-		attempt++
-		var err error
-		resp, err = c.httpClient.Do(req)
-		if err != nil {
-			return err
-		}
-
-		if resp.StatusCode >= 500 {
-			return fmt.Errorf("server error: %s", resp.Status)
-		}
-
-		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
-			return backoff.Permanent(fmt.Errorf("client error: %s", resp.Status))
-		}
+	classify := c.retryConfig.Classifier
+	if classify == nil {
+		classify = DefaultRetryClassifier
+	}
 
-		return nil
+	maxRetryAfter := c.retryConfig.MaxRetryAfter
+	if maxRetryAfter <= 0 {
+		maxRetryAfter = DefaultMaxRetryAfter
 	}
 
 	bo := backoff.NewExponentialBackOff()
@@ -165,10 +216,63 @@ func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
 		bo.MaxElapsedTime = c.retryConfig.MaxElapsedTime
 	}
 
-	b := backoff.WithMaxRetries(bo, c.retryConfig.MaxRetries)
+	b := &retryAfterBackOff{BackOff: backoff.WithMaxRetries(bo, c.retryConfig.MaxRetries)}
+
+	if err := makeReplayable(req); err != nil {
+		return nil, err
+	}
+
+	operation := func() error {
+		attempt++
+		b.retryAfter = 0
+
+		attemptReq, err := rewind(req, attempt)
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+
+		resp, err = c.send(attemptReq)
+		if errors.Is(err, ErrCircuitOpen) {
+			return backoff.Permanent(err)
+		}
+
+		decision := classify(resp, err)
+		if decision == RetryDecisionSuccess {
+			return nil
+		}
+
+		if resp == nil {
+			if decision == RetryDecisionPermanent {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+
+		respErr := newResponseError(resp)
+		discard(resp)
+		resp = nil
+
+		if decision == RetryDecisionPermanent {
+			return backoff.Permanent(respErr)
+		}
+
+		if delay, ok := ParseRetryAfter(respErr.Header.Get(HeaderRetryAfter), time.Now()); ok {
+			if delay > maxRetryAfter {
+				return backoff.Permanent(fmt.Errorf("retry-after %s exceeds limit %s: %w", delay, maxRetryAfter, respErr))
+			}
+			b.retryAfter = delay
+		}
+
+		return respErr
+	}
+
 	err := backoff.Retry(operation, backoff.WithContext(b, req.Context()))
 
 	if err != nil {
+		var respErr *ResponseError
+		if errors.As(err, &respErr) {
+			respErr.Attempts = attempt
+		}
 		return nil, fmt.Errorf("request failed after %d attempts: %w", attempt, err)
 	}
 
@@ -178,3 +282,49 @@ func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
 
 	return resp, nil
 }
+
+// makeReplayable ensures the request body can be re-read for every attempt.
+// Bodies without GetBody are buffered in memory once.
+func makeReplayable(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to buffer request body: %w", err)
+	}
+
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
+
+// rewind returns the request to send for the given attempt. The first attempt
+// uses the original body, later ones get a fresh copy from GetBody.
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 1 || req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to rewind request body: %w", err)
+	}
+
+	attemptReq := req.Clone(req.Context())
+	attemptReq.Body = body
+	return attemptReq, nil
+}
+
+const maxDrainResponseBytes = 64 << 10
+
+// discard drains and closes a response body that won't be handed to the
+// caller, so the underlying connection can be reused.
+func discard(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainResponseBytes))
+	_ = resp.Body.Close()
+}
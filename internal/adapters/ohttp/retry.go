@@ -0,0 +1,130 @@
+package ohttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+const (
+	DefaultMaxRetryAfter = 30 * time.Second
+
+	HeaderRetryAfter = "Retry-After"
+)
+
+type RetryDecision int
+
+const (
+	RetryDecisionSuccess RetryDecision = iota
+	RetryDecisionRetry
+	RetryDecisionPermanent
+)
+
+// RetryClassifier decides whether an attempt succeeded, should be retried or
+// failed permanently. Exactly one of resp and err is non-nil.
+type RetryClassifier func(resp *http.Response, err error) RetryDecision
+
+// DefaultRetryClassifier retries transport errors, 408, 429 and 5xx responses.
+// Other 4xx responses and cancelled contexts are permanent failures.
+func DefaultRetryClassifier(resp *http.Response, err error) RetryDecision {
+	if err != nil {
+		if errors.Is(err, ErrCircuitOpen) ||
+			errors.Is(err, context.Canceled) ||
+			errors.Is(err, context.DeadlineExceeded) {
+			return RetryDecisionPermanent
+		}
+		return RetryDecisionRetry
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode >= 500:
+		return RetryDecisionRetry
+	case resp.StatusCode >= 400:
+		return RetryDecisionPermanent
+	default:
+		return RetryDecisionSuccess
+	}
+}
+
+// ResponseError is returned when the final attempt produced an HTTP response
+// that was not classified as a success. It carries that response's status and
+// headers so callers can inspect them.
+type ResponseError struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	// Body holds up to MaxResponseErrorBody bytes of the response body.
+	Body     []byte
+	Attempts int
+}
+
+func (e *ResponseError) Error() string {
+	if e.StatusCode >= 500 {
+		return fmt.Sprintf("server error: %s", e.Status)
+	}
+	return fmt.Sprintf("client error: %s", e.Status)
+}
+
+// MaxResponseErrorBody caps how much of a failed response body is kept on a
+// ResponseError.
+const MaxResponseErrorBody = 4 << 10
+
+func newResponseError(resp *http.Response) *ResponseError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, MaxResponseErrorBody))
+	return &ResponseError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+	}
+}
+
+// ParseRetryAfter parses a Retry-After header value given either as a number
+// of seconds or as an HTTP-date. Dates in the past yield a zero delay.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	if delay := at.Sub(now); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
+
+// retryAfterBackOff stretches the next backoff interval to the delay the
+// server asked for, when it is longer than the computed one.
+type retryAfterBackOff struct {
+	backoff.BackOff
+	retryAfter time.Duration
+}
+
+func (b *retryAfterBackOff) NextBackOff() time.Duration {
+	next := b.BackOff.NextBackOff()
+	if next == backoff.Stop || b.retryAfter <= next {
+		return next
+	}
+	return b.retryAfter
+}
@@ -0,0 +1,236 @@
+//go:build unit
+
+package ohttp_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/ohttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRetryClient(cfg ohttp.RetryConfig) *ohttp.Client {
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
+	cfg.InitialInterval = time.Millisecond
+	cfg.MaxInterval = 5 * time.Millisecond
+	return ohttp.NewClient(ohttp.Config{RetryConfig: &cfg})
+}
+
+func TestDefaultRetryClassifier(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		err    error
+		want   ohttp.RetryDecision
+	}{
+		{name: "ok", status: http.StatusOK, want: ohttp.RetryDecisionSuccess},
+		{name: "too many requests", status: http.StatusTooManyRequests, want: ohttp.RetryDecisionRetry},
+		{name: "request timeout", status: http.StatusRequestTimeout, want: ohttp.RetryDecisionRetry},
+		{name: "service unavailable", status: http.StatusServiceUnavailable, want: ohttp.RetryDecisionRetry},
+		{name: "bad request", status: http.StatusBadRequest, want: ohttp.RetryDecisionPermanent},
+		{name: "transport error", err: errors.New("connection reset"), want: ohttp.RetryDecisionRetry},
+		{name: "circuit open", err: ohttp.ErrCircuitOpen, want: ohttp.RetryDecisionPermanent},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var resp *http.Response
+			if tc.err == nil {
+				resp = &http.Response{StatusCode: tc.status}
+			}
+			assert.Equal(t, tc.want, ohttp.DefaultRetryClassifier(resp, tc.err))
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("seconds", func(t *testing.T) {
+		d, ok := ohttp.ParseRetryAfter("3", now)
+		assert.True(t, ok)
+		assert.Equal(t, 3*time.Second, d)
+	})
+
+	t.Run("http date", func(t *testing.T) {
+		d, ok := ohttp.ParseRetryAfter(now.Add(5*time.Second).Format(http.TimeFormat), now)
+		assert.True(t, ok)
+		assert.Equal(t, 5*time.Second, d)
+	})
+
+	t.Run("date in the past", func(t *testing.T) {
+		d, ok := ohttp.ParseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now)
+		assert.True(t, ok)
+		assert.Zero(t, d)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, ok := ohttp.ParseRetryAfter("soon", now)
+		assert.False(t, ok)
+	})
+}
+
+func TestDoWithRetry_RetriesTooManyRequests(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := doGet(t, newRetryClient(ohttp.RetryConfig{}), server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get(ohttp.HeaderRetryAttempt))
+}
+
+func TestDoWithRetry_HonorsRetryAfter(t *testing.T) {
+	var hits int32
+	var first time.Time
+	var second time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			first = time.Now()
+			w.Header().Set(ohttp.HeaderRetryAfter, "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		second = time.Now()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	_, err := doGet(t, newRetryClient(ohttp.RetryConfig{}), server.URL)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, second.Sub(first), time.Second)
+}
+
+func TestDoWithRetry_RetryAfterAboveLimitStops(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set(ohttp.HeaderRetryAfter, "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := newRetryClient(ohttp.RetryConfig{MaxRetryAfter: time.Second})
+	_, err := doGet(t, client, server.URL)
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+}
+
+func TestDoWithRetry_ExposesFinalResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Provider-Error", "quota")
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, err := doGet(t, newRetryClient(ohttp.RetryConfig{MaxRetries: 2}), server.URL)
+	require.Error(t, err)
+
+	var respErr *ohttp.ResponseError
+	require.True(t, errors.As(err, &respErr))
+	assert.Equal(t, http.StatusBadGateway, respErr.StatusCode)
+	assert.Equal(t, "quota", respErr.Header.Get("X-Provider-Error"))
+	assert.Equal(t, 3, respErr.Attempts)
+}
+
+func TestDoWithRetry_CustomClassifier(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := newRetryClient(ohttp.RetryConfig{
+		Classifier: func(resp *http.Response, err error) ohttp.RetryDecision {
+			return ohttp.RetryDecisionPermanent
+		},
+	})
+
+	_, err := doGet(t, client, server.URL)
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+}
+
+func TestDoWithRetry_ReplaysBodyWithStableIdempotencyKey(t *testing.T) {
+	cases := []struct {
+		name string
+		body func() io.Reader
+	}{
+		{name: "rewindable buffer", body: func() io.Reader { return bytes.NewBufferString(`{"to":"1"}`) }},
+		{name: "one-shot reader", body: func() io.Reader { return io.NopCloser(strings.NewReader(`{"to":"1"}`)) }},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var bodies []string
+			var keys []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				mu.Lock()
+				bodies = append(bodies, string(data))
+				keys = append(keys, r.Header.Get(ohttp.HeaderIdempotencyKey))
+				n := len(bodies)
+				mu.Unlock()
+				if n < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodPost, server.URL, tc.body())
+			require.NoError(t, err)
+
+			resp, err := newRetryClient(ohttp.RetryConfig{}).Do(req)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+
+			require.Len(t, bodies, 3)
+			for i := range bodies {
+				assert.Equal(t, `{"to":"1"}`, bodies[i], "attempt %d", i+1)
+				assert.NotEmpty(t, keys[i])
+				assert.Equal(t, keys[0], keys[i], "idempotency key must be stable across attempts")
+			}
+		})
+	}
+}
+
+func TestDo_KeepsCallerIdempotencyKey(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(ohttp.HeaderIdempotencyKey)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(ohttp.HeaderIdempotencyKey, "message-42")
+
+	resp, err := ohttp.NewClient().Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, "message-42", got)
+}
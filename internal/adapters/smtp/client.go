@@ -0,0 +1,162 @@
+package smtp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	gosmtp "net/smtp"
+	"strconv"
+	"time"
+)
+
+const (
+	TLSNone     = "none"
+	TLSStartTLS = "starttls"
+	TLSImplicit = "tls"
+
+	DefaultTimeout = 10 * time.Second
+)
+
+var ErrStartTLSUnsupported = errors.New("smtp server does not support STARTTLS")
+
+// Config describes how to reach the SMTP server. Username and Password enable
+// PLAIN authentication, which net/smtp only allows over TLS or to localhost.
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	TLS      string
+	Timeout  time.Duration
+	// TLSConfig overrides the TLS settings, e.g. to trust a test certificate.
+	TLSConfig *tls.Config
+}
+
+type Client struct {
+	config Config
+}
+
+func NewClient(config Config) *Client {
+	if config.TLS == "" {
+		config.TLS = TLSStartTLS
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	return &Client{config: config}
+}
+
+// Send delivers mail over a new connection and returns the Message-ID it was
+// sent with.
+func (c *Client) Send(ctx context.Context, mail Mail) (string, error) {
+	if mail.From == "" {
+		mail.From = c.config.From
+	}
+
+	data, messageID, err := mail.build(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	// build already validated both addresses.
+	from, _ := netmail.ParseAddress(mail.From)
+	to, _ := netmail.ParseAddress(mail.To)
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := c.deliver(conn, from.Address, to.Address, data); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+		return "", err
+	}
+
+	return messageID, nil
+}
+
+func (c *Client) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(c.config.Host, strconv.Itoa(c.config.Port))
+	dialer := &net.Dialer{Timeout: c.config.Timeout}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if c.config.TLS == TLSImplicit {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: c.tlsConfig()}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to smtp server %s: %w", addr, err)
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(c.config.Timeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (c *Client) deliver(conn net.Conn, from, to string, data []byte) error {
+	client, err := gosmtp.NewClient(conn, c.config.Host)
+	if err != nil {
+		return fmt.Errorf("error starting smtp session: %w", err)
+	}
+	defer client.Close()
+
+	if c.config.TLS == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return ErrStartTLSUnsupported
+		}
+		if err := client.StartTLS(c.tlsConfig()); err != nil {
+			return fmt.Errorf("error starting tls: %w", err)
+		}
+	}
+
+	if c.config.Username != "" {
+		auth := gosmtp.PlainAuth("", c.config.Username, c.config.Password, c.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("error authenticating: %w", err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("error setting sender: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("error setting recipient: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error starting data: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("error writing message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("error sending message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (c *Client) tlsConfig() *tls.Config {
+	if c.config.TLSConfig != nil {
+		return c.config.TLSConfig
+	}
+	return &tls.Config{ServerName: c.config.Host, MinVersion: tls.VersionTLS12}
+}
@@ -0,0 +1,274 @@
+//go:build unit
+
+package smtp_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/smtp"
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type received struct {
+	from string
+	to   []string
+	data string
+}
+
+// fakeServer is a minimal SMTP stand-in that records the mail it accepts.
+// rcptReply overrides the reply to RCPT TO, e.g. "450 mailbox busy".
+type fakeServer struct {
+	listener  net.Listener
+	rcptReply string
+
+	mu   sync.Mutex
+	mail []received
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &fakeServer{listener: listener}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *fakeServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeServer) received() []received {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]received(nil), s.mail...)
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP")
+	var current received
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		upper := strings.ToUpper(cmd)
+
+		switch {
+		case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			current = received{from: strings.Trim(cmd[len("MAIL FROM:"):], "<> ")}
+			reply("250 OK")
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			if s.rcptReply != "" {
+				reply(s.rcptReply)
+				continue
+			}
+			current.to = append(current.to, strings.Trim(cmd[len("RCPT TO:"):], "<> "))
+			reply("250 OK")
+		case upper == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			current.data = data.String()
+			s.mu.Lock()
+			s.mail = append(s.mail, current)
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case upper == "RSET", upper == "NOOP":
+			reply("250 OK")
+		case upper == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func newClient(s *fakeServer) *smtp.Client {
+	return smtp.NewClient(smtp.Config{
+		Host: "127.0.0.1",
+		Port: s.port(),
+		From: "GoPulse <no-reply@gopulse.test>",
+		TLS:  smtp.TLSNone,
+	})
+}
+
+func TestClient_Send_PlainText(t *testing.T) {
+	server := newFakeServer(t)
+
+	messageID, err := newClient(server).Send(context.Background(), smtp.Mail{
+		To:        "user@example.com",
+		Subject:   "Merhaba dünya",
+		Text:      "Hello there",
+		MessageID: "message-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "<message-1@gopulse.test>", messageID)
+
+	mails := server.received()
+	require.Len(t, mails, 1)
+	assert.Equal(t, "no-reply@gopulse.test", mails[0].from)
+	assert.Equal(t, []string{"user@example.com"}, mails[0].to)
+
+	msg, err := mail.ReadMessage(strings.NewReader(mails[0].data))
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Merhaba dünya", subject)
+	assert.Equal(t, messageID, msg.Header.Get("Message-ID"))
+	assert.Equal(t, "text/plain; charset=UTF-8", msg.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(msg.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello there", strings.TrimRight(string(body), "\r\n"))
+}
+
+func TestClient_Send_Alternative(t *testing.T) {
+	server := newFakeServer(t)
+
+	_, err := newClient(server).Send(context.Background(), smtp.Mail{
+		To:      "user@example.com",
+		Subject: "Report",
+		Text:    "plain",
+		HTML:    "<p>html</p>",
+	})
+	require.NoError(t, err)
+
+	mails := server.received()
+	require.Len(t, mails, 1)
+	msg, err := mail.ReadMessage(strings.NewReader(mails[0].data))
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	var types []string
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		types = append(types, part.Header.Get("Content-Type"))
+	}
+	assert.Equal(t, []string{"text/plain; charset=UTF-8", "text/html; charset=UTF-8"}, types)
+}
+
+func TestClient_Send_RequiresBody(t *testing.T) {
+	server := newFakeServer(t)
+
+	_, err := newClient(server).Send(context.Background(), smtp.Mail{To: "user@example.com", Subject: "empty"})
+	assert.ErrorIs(t, err, smtp.ErrEmptyBody)
+	assert.Empty(t, server.received())
+}
+
+func TestClient_Send_StartTLSUnsupported(t *testing.T) {
+	server := newFakeServer(t)
+	client := smtp.NewClient(smtp.Config{
+		Host: "127.0.0.1",
+		Port: server.port(),
+		From: "no-reply@gopulse.test",
+	})
+
+	_, err := client.Send(context.Background(), smtp.Mail{To: "user@example.com", Subject: "s", Text: "t"})
+	assert.ErrorIs(t, err, smtp.ErrStartTLSUnsupported)
+	assert.Empty(t, server.received())
+}
+
+func TestProvider_Send(t *testing.T) {
+	tests := []struct {
+		name          string
+		rcptReply     string
+		wantTransient bool
+		wantErr       bool
+	}{
+		{name: "Given the server accepts the mail, it succeeds"},
+		{name: "Given a 4xx reply, the error is transient", rcptReply: "450 mailbox busy", wantErr: true, wantTransient: true},
+		{name: "Given a 5xx reply, the error is permanent", rcptReply: "550 no such user", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeServer(t)
+			server.rcptReply = tt.rcptReply
+			provider := smtp.NewProvider("mail", newClient(server), app.Capabilities{})
+
+			assert.Equal(t, domain.MessageChannelEmail, provider.Capabilities().Channel)
+
+			result, err := provider.Send(context.Background(), app.SendRequest{
+				MessageID:      7,
+				Channel:        domain.MessageChannelEmail,
+				To:             "user@example.com",
+				Subject:        "Hi",
+				Content:        "text",
+				IdempotencyKey: "message-7",
+			})
+
+			if !tt.wantErr {
+				require.NoError(t, err)
+				assert.Equal(t, "<message-7@gopulse.test>", result.ProviderMessageID)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.wantTransient, errors.Is(err, app.ErrTransient))
+		})
+	}
+}
+
+func TestProvider_Send_ConnectionRefusedIsTransient(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	client := smtp.NewClient(smtp.Config{Host: "127.0.0.1", Port: port, From: "no-reply@gopulse.test", TLS: smtp.TLSNone})
+	provider := smtp.NewProvider("mail", client, app.Capabilities{})
+
+	_, err = provider.Send(context.Background(), app.SendRequest{To: "user@example.com", Subject: "s", Content: "t"})
+	assert.ErrorIs(t, err, app.ErrTransient)
+}
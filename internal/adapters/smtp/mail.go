@@ -0,0 +1,131 @@
+package smtp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrEmptyBody = errors.New("email needs a text or html body")
+
+// Mail is a single email. When both Text and HTML are set the message is sent
+// as multipart/alternative.
+type Mail struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	// MessageID is used as the local part of the Message-ID header so
+	// redeliveries of the same message share it. Generated when empty.
+	MessageID string
+}
+
+func (m Mail) build(now time.Time) ([]byte, string, error) {
+	if m.Text == "" && m.HTML == "" {
+		return nil, "", ErrEmptyBody
+	}
+
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid sender %q: %w", m.From, err)
+	}
+	to, err := mail.ParseAddress(m.To)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid recipient %q: %w", m.To, err)
+	}
+
+	localPart := m.MessageID
+	if localPart == "" {
+		localPart = uuid.NewString()
+	}
+	messageID := fmt.Sprintf("<%s@%s>", localPart, domainOf(from.Address))
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", from.String())
+	writeHeader(&buf, "To", to.String())
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	writeHeader(&buf, "Date", now.Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", messageID)
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	switch {
+	case m.HTML == "":
+		err = writeSinglePart(&buf, "text/plain", m.Text)
+	case m.Text == "":
+		err = writeSinglePart(&buf, "text/html", m.HTML)
+	default:
+		err = writeAlternative(&buf, m.Text, m.HTML)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), messageID, nil
+}
+
+func writeSinglePart(buf *bytes.Buffer, contentType, body string) error {
+	writeHeader(buf, "Content-Type", contentType+"; charset=UTF-8")
+	writeHeader(buf, "Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+	return writeQuotedPrintable(buf, body)
+}
+
+func writeAlternative(buf *bytes.Buffer, text, html string) error {
+	mw := multipart.NewWriter(buf)
+	writeHeader(buf, "Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", text},
+		{"text/html", html},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return err
+		}
+		if err := qp.Close(); err != nil {
+			return err
+		}
+	}
+
+	return mw.Close()
+}
+
+func writeQuotedPrintable(buf *bytes.Buffer, body string) error {
+	qp := quotedprintable.NewWriter(buf)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(key)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteString("\r\n")
+}
+
+func domainOf(address string) string {
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		return address[i+1:]
+	}
+	return "localhost"
+}
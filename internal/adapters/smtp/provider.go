@@ -0,0 +1,91 @@
+package smtp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+)
+
+// Provider adapts an SMTP Client to the app.Provider interface. It always
+// delivers on the email channel.
+type Provider struct {
+	name         string
+	client       *Client
+	capabilities app.Capabilities
+}
+
+func NewProvider(name string, client *Client, capabilities app.Capabilities) *Provider {
+	capabilities.Channel = domain.MessageChannelEmail
+	return &Provider{
+		name:         name,
+		client:       client,
+		capabilities: capabilities,
+	}
+}
+
+func (p *Provider) Name() string {
+	return p.name
+}
+
+func (p *Provider) Capabilities() app.Capabilities {
+	return p.capabilities
+}
+
+func (p *Provider) Send(ctx context.Context, req app.SendRequest) (*app.SendResult, error) {
+	messageID, err := p.client.Send(ctx, Mail{
+		To:        req.To,
+		Subject:   req.Subject,
+		Text:      req.Content,
+		HTML:      req.HTMLContent,
+		MessageID: req.IdempotencyKey,
+	})
+	if err != nil && isTransient(ctx, err) {
+		return nil, sendError(fmt.Errorf("%w: %w", app.ErrTransient, err))
+	}
+	if err != nil {
+		return nil, sendError(err)
+	}
+
+	return &app.SendResult{
+		ProviderMessageID: messageID,
+		Attempts:          1,
+		StatusCode:        250,
+	}, nil
+}
+
+// sendError attaches the SMTP reply code and text to err when the server
+// rejected the mail.
+func sendError(err error) error {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return &app.SendError{
+			StatusCode:   protoErr.Code,
+			ResponseBody: protoErr.Msg,
+			Attempts:     1,
+			Err:          err,
+		}
+	}
+	return err
+}
+
+// isTransient reports whether err may go away on a later attempt: network
+// errors and 4xx SMTP replies. 5xx replies are permanent. A cancelled caller
+// context is never transient.
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code >= 400 && protoErr.Code < 500
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
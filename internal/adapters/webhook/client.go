@@ -3,53 +3,168 @@ package webhook
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
+	"github.com/muratdemir0/gopulse-messages/api/signing"
 	"github.com/muratdemir0/gopulse-messages/internal/adapters/ohttp"
 )
 
+const (
+	AuthNone   = "none"
+	AuthBearer = "bearer"
+	AuthBasic  = "basic"
+	AuthHeader = "header"
+)
+
+const maxResponseBodySize = 1 << 20
+
 type Client struct {
-	Host       string
-	httpClient *ohttp.Client
+	Host           string
+	httpClient     *ohttp.Client
+	signingSecrets []string
+	auth           Auth
+	request        *RequestTemplate
+	response       ResponseMapping
+}
+
+// Config holds optional client settings. SigningSecrets lists the active HMAC
+// secrets; when set, every request is signed with each of them so the
+// provider can verify it during a secret rotation. Request and Response
+// default to the {to, content} and {message, messageId} schema.
+type Config struct {
+	SigningSecrets []string
+	Auth           Auth
+	Request        *RequestTemplate
+	Response       *ResponseMapping
+}
+
+// Auth describes how requests authenticate against the provider.
+type Auth struct {
+	Type     string
+	Token    string
+	Username string
+	Password string
+	Header   string
+	Value    string
+}
+
+func (a Auth) apply(req *http.Request) {
+	switch a.Type {
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	case AuthBasic:
+		req.SetBasicAuth(a.Username, a.Password)
+	case AuthHeader:
+		req.Header.Set(a.Header, a.Value)
+	}
 }
 
 type Response struct {
+	// Message is the status reported by the provider, read from
+	// ResponseMapping.StatusPath.
 	Message      string `json:"message"`
 	MessageID    string `json:"messageId"`
 	RetryAttempt int    `json:"-"`
+	StatusCode   int    `json:"-"`
+	Body         []byte `json:"-"`
 }
 
+// Request is the data available to the body and header templates.
 type Request struct {
-	To      string `json:"to"`
-	Content string `json:"content"`
+	MessageID int64  `json:"-"`
+	To        string `json:"to"`
+	Content   string `json:"content"`
+	// IdempotencyKey is sent as the Idempotency-Key header so the provider can
+	// deduplicate redeliveries of the same message. Generated when empty.
+	IdempotencyKey string `json:"-"`
+}
+
+// StatusError is returned when the provider answers with a non-2xx status,
+// or with a 2xx response that could not be mapped, in which case Err holds
+// the mapping error.
+type StatusError struct {
+	StatusCode int
+	URL        string
+	Body       []byte
+	Err        error
 }
 
-func NewClient(host string, httpClient *ohttp.Client) *Client {
-	return &Client{
+func (e *StatusError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%v (status code %d for %s)", e.Err, e.StatusCode, e.URL)
+	}
+	return fmt.Sprintf("unexpected status code: %d for %s", e.StatusCode, e.URL)
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+func NewClient(host string, httpClient *ohttp.Client, configs ...Config) *Client {
+	client := &Client{
 		Host:       host,
 		httpClient: httpClient,
+		response:   DefaultResponseMapping,
+	}
+
+	if len(configs) > 0 {
+		client.auth = configs[0].Auth
+		client.request = configs[0].Request
+		if configs[0].Response != nil {
+			client.response = *configs[0].Response
+		}
+		for _, secret := range configs[0].SigningSecrets {
+			if secret != "" {
+				client.signingSecrets = append(client.signingSecrets, secret)
+			}
+		}
+	}
+
+	if client.request == nil {
+		// The default template always parses.
+		client.request, _ = NewRequestTemplate(FormatJSON, "", nil)
 	}
+
+	return client
 }
 
 func (c *Client) Send(ctx context.Context, message Request, path string) (*Response, error) {
-	payload, err := json.Marshal(message)
+	return c.send(ctx, message, c.Host, path)
+}
+
+func (c *Client) send(ctx context.Context, message Request, host, path string) (*Response, error) {
+	payload, headers, err := c.request.render(message)
 	if err != nil {
 		return nil, err
 	}
 
-	fullUrl, err := url.JoinPath(c.Host, path)
+	fullUrl, err := url.JoinPath(host, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to join URL path: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fullUrl, bytes.NewBuffer(payload))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fullUrl, bytes.NewReader(payload))
 	if err != nil {
 		return nil, err
 	}
+	req.Header.Set("Content-Type", c.request.contentType)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	c.auth.apply(req)
+	if message.IdempotencyKey != "" {
+		req.Header.Set(ohttp.HeaderIdempotencyKey, message.IdempotencyKey)
+	}
+	if len(c.signingSecrets) > 0 {
+		if err := signing.SignRequest(req, c.signingSecrets, payload, time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to sign request: %w", err)
+		}
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -57,13 +172,18 @@ func (c *Client) Send(ctx context.Context, message Request, path string) (*Respo
 	}
 	defer resp.Body.Close() //nolint:errcheck
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d for %s", resp.StatusCode, fullUrl)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	var response Response
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, err
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &StatusError{StatusCode: resp.StatusCode, URL: fullUrl, Body: body}
+	}
+
+	response := Response{StatusCode: resp.StatusCode, Body: body}
+	if err := c.response.apply(body, &response); err != nil {
+		return nil, &StatusError{StatusCode: resp.StatusCode, URL: fullUrl, Body: body, Err: err}
 	}
 
 	response.RetryAttempt, err = strconv.Atoi(resp.Header.Get(ohttp.HeaderRetryAttempt))
@@ -7,7 +7,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/muratdemir0/gopulse-messages/api/signing"
 	"github.com/muratdemir0/gopulse-messages/internal/adapters/ohttp"
 	"github.com/muratdemir0/gopulse-messages/internal/adapters/webhook"
 )
@@ -114,3 +116,25 @@ func TestSend(t *testing.T) {
 		})
 	}
 }
+
+func TestSend_SignsRequest(t *testing.T) {
+	secrets := []string{"current-secret", "previous-secret"}
+
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, verifyErr = signing.VerifyRequest(r, []string{"previous-secret"}, time.Minute)
+		w.Header().Set(ohttp.HeaderRetryAttempt, "1")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"message": "Accepted", "messageId": "1234567890"}`))
+	}))
+	defer server.Close()
+
+	client := webhook.NewClient(server.URL, ohttp.NewClient(), webhook.Config{SigningSecrets: secrets})
+	_, err := client.Send(context.TODO(), webhook.Request{To: "1234567890", Content: "Hello"}, "/messages")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verifyErr != nil {
+		t.Errorf("expected a valid signature, got %v", verifyErr)
+	}
+}
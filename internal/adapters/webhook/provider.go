@@ -0,0 +1,128 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/ohttp"
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+)
+
+// Provider adapts a webhook Client to the app.Provider interface.
+type Provider struct {
+	name         string
+	endpoint     atomic.Pointer[endpoint]
+	client       *Client
+	capabilities app.Capabilities
+}
+
+type endpoint struct {
+	host string
+	path string
+}
+
+func NewProvider(name, path string, client *Client, capabilities app.Capabilities) *Provider {
+	p := &Provider{
+		name:         name,
+		client:       client,
+		capabilities: capabilities,
+	}
+	p.endpoint.Store(&endpoint{host: client.Host, path: path})
+	return p
+}
+
+// SetEndpoint points subsequent sends at host and path. Sends in flight keep
+// the endpoint they started with.
+func (p *Provider) SetEndpoint(host, path string) {
+	p.endpoint.Store(&endpoint{host: host, path: path})
+}
+
+func (p *Provider) Name() string {
+	return p.name
+}
+
+func (p *Provider) Capabilities() app.Capabilities {
+	return p.capabilities
+}
+
+func (p *Provider) Send(ctx context.Context, req app.SendRequest) (*app.SendResult, error) {
+	target := p.endpoint.Load()
+	resp, err := p.client.send(ctx, Request{
+		MessageID:      req.MessageID,
+		To:             req.To,
+		Content:        req.Content,
+		IdempotencyKey: req.IdempotencyKey,
+	}, target.host, target.path)
+	if errors.Is(err, ohttp.ErrCircuitOpen) {
+		return nil, fmt.Errorf("%w: %w", app.ErrProviderUnavailable, err)
+	}
+	if err != nil && isTransient(ctx, err) {
+		return nil, sendError(fmt.Errorf("%w: %w", app.ErrTransient, err))
+	}
+	if err != nil {
+		return nil, sendError(err)
+	}
+
+	return &app.SendResult{
+		ProviderMessageID: resp.MessageID,
+		Attempts:          resp.RetryAttempt,
+		StatusCode:        resp.StatusCode,
+		ResponseBody:      string(resp.Body),
+	}, nil
+}
+
+// sendError attaches the provider's status code and body to err when the
+// request got a response.
+func sendError(err error) error {
+	var respErr *ohttp.ResponseError
+	if errors.As(err, &respErr) {
+		return &app.SendError{
+			StatusCode:   respErr.StatusCode,
+			ResponseBody: string(respErr.Body),
+			Attempts:     respErr.Attempts,
+			Err:          err,
+		}
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return &app.SendError{
+			StatusCode:   statusErr.StatusCode,
+			ResponseBody: string(statusErr.Body),
+			Attempts:     1,
+			Err:          err,
+		}
+	}
+
+	return err
+}
+
+// isTransient reports whether err may go away on a later attempt: network
+// errors, timeouts and 408, 429 or 5xx responses. A cancelled caller context
+// is never transient.
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var respErr *ohttp.ResponseError
+	if errors.As(err, &respErr) {
+		return isTransientStatus(respErr.StatusCode)
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return isTransientStatus(statusErr.StatusCode)
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func isTransientStatus(code int) bool {
+	return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+}
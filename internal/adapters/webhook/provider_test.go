@@ -0,0 +1,161 @@
+//go:build unit
+
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/ohttp"
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/webhook"
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+)
+
+func TestProvider_Send(t *testing.T) {
+	var gotAuth, gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotKey = r.Header.Get(ohttp.HeaderIdempotencyKey)
+		w.Header().Set(ohttp.HeaderRetryAttempt, "1")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"message": "Accepted", "messageId": "ext-1"}`))
+	}))
+	defer server.Close()
+
+	client := webhook.NewClient(server.URL, ohttp.NewClient(), webhook.Config{
+		Auth: webhook.Auth{Type: webhook.AuthBearer, Token: "token"},
+	})
+	provider := webhook.NewProvider("primary", "/messages", client, app.Capabilities{})
+
+	result, err := provider.Send(context.TODO(), app.SendRequest{
+		MessageID:      1,
+		To:             "1234567890",
+		Content:        "Hello",
+		IdempotencyKey: "message-1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProviderMessageID != "ext-1" {
+		t.Errorf("expected provider message id %q, got %q", "ext-1", result.ProviderMessageID)
+	}
+	if gotAuth != "Bearer token" {
+		t.Errorf("expected bearer auth header, got %q", gotAuth)
+	}
+	if gotKey != "message-1" {
+		t.Errorf("expected idempotency key %q, got %q", "message-1", gotKey)
+	}
+}
+
+func TestProvider_SetEndpoint(t *testing.T) {
+	var gotPath string
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotPath = name + r.URL.Path
+			w.Header().Set(ohttp.HeaderRetryAttempt, "1")
+			_, _ = w.Write([]byte(`{"message": "Accepted", "messageId": "ext-1"}`))
+		}))
+	}
+	oldServer := newServer("old")
+	defer oldServer.Close()
+	replacement := newServer("new")
+	defer replacement.Close()
+
+	provider := webhook.NewProvider("primary", "/messages", webhook.NewClient(oldServer.URL, ohttp.NewClient()), app.Capabilities{})
+	send := func() {
+		if _, err := provider.Send(context.TODO(), app.SendRequest{MessageID: 1, To: "1234567890", Content: "Hello"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	send()
+	if gotPath != "old/messages" {
+		t.Errorf("expected request to %q, got %q", "old/messages", gotPath)
+	}
+
+	provider.SetEndpoint(replacement.URL, "/v2/messages")
+	send()
+	if gotPath != "new/v2/messages" {
+		t.Errorf("expected request to %q, got %q", "new/v2/messages", gotPath)
+	}
+}
+
+func TestProvider_Send_CircuitOpenIsUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	httpClient := ohttp.NewClient(ohttp.Config{
+		CircuitBreaker: &ohttp.CircuitBreakerConfig{MinRequests: 1, WindowSize: 1, CoolDown: time.Hour},
+	})
+	provider := webhook.NewProvider("primary", "/messages", webhook.NewClient(server.URL, httpClient), app.Capabilities{})
+
+	_, _ = provider.Send(context.TODO(), app.SendRequest{To: "1", Content: "a"})
+	_, err := provider.Send(context.TODO(), app.SendRequest{To: "1", Content: "a"})
+	if !errors.Is(err, app.ErrProviderUnavailable) {
+		t.Errorf("expected ErrProviderUnavailable, got %v", err)
+	}
+}
+
+func TestProvider_Send_ClassifiesTransientErrors(t *testing.T) {
+	cases := []struct {
+		name          string
+		status        int
+		wantTransient bool
+	}{
+		{name: "server error", status: http.StatusBadGateway, wantTransient: true},
+		{name: "rate limited", status: http.StatusTooManyRequests, wantTransient: true},
+		{name: "bad request", status: http.StatusBadRequest, wantTransient: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			provider := webhook.NewProvider("primary", "/messages", webhook.NewClient(server.URL, ohttp.NewClient()), app.Capabilities{})
+
+			_, err := provider.Send(context.TODO(), app.SendRequest{To: "1", Content: "a"})
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if got := errors.Is(err, app.ErrTransient); got != tc.wantTransient {
+				t.Errorf("expected transient=%v, got %v (%v)", tc.wantTransient, got, err)
+			}
+		})
+	}
+}
+
+func TestProvider_Send_ErrorCarriesResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": "invalid number"}`))
+	}))
+	defer server.Close()
+
+	client := webhook.NewClient(server.URL, ohttp.NewClient())
+	provider := webhook.NewProvider("primary", "/messages", client, app.Capabilities{})
+
+	_, err := provider.Send(context.TODO(), app.SendRequest{To: "1", Content: "Hello"})
+
+	var sendErr *app.SendError
+	if !errors.As(err, &sendErr) {
+		t.Fatalf("expected *app.SendError, got %v", err)
+	}
+	if sendErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d, got %d", http.StatusBadRequest, sendErr.StatusCode)
+	}
+	if sendErr.ResponseBody != `{"error": "invalid number"}` {
+		t.Errorf("unexpected response body %q", sendErr.ResponseBody)
+	}
+	if errors.Is(err, app.ErrTransient) {
+		t.Error("expected a 400 to be permanent")
+	}
+}
@@ -0,0 +1,202 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const (
+	FormatJSON = "json"
+	FormatForm = "form"
+	FormatXML  = "xml"
+)
+
+// DefaultBodyTemplate renders the {to, content} body the client always sent
+// before templates were configurable.
+const DefaultBodyTemplate = `{"to":{{json .To}},"content":{{json .Content}}}`
+
+var contentTypes = map[string]string{
+	FormatJSON: "application/json",
+	FormatForm: "application/x-www-form-urlencoded",
+	FormatXML:  "application/xml",
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"xml": func(s string) (string, error) {
+		var buf bytes.Buffer
+		err := xml.EscapeText(&buf, []byte(s))
+		return buf.String(), err
+	},
+	"form": url.QueryEscape,
+}
+
+// RequestTemplate renders the outbound body and headers from a Request. Body
+// and header values are text/template strings with the json, xml and form
+// escape functions available, e.g. {"phone":{{json .To}}}.
+type RequestTemplate struct {
+	contentType string
+	body        *template.Template
+	headers     map[string]*template.Template
+}
+
+// NewRequestTemplate parses body and headers for the given format. An empty
+// format means JSON and an empty body means DefaultBodyTemplate.
+func NewRequestTemplate(format, body string, headers map[string]string) (*RequestTemplate, error) {
+	if format == "" {
+		format = FormatJSON
+	}
+	contentType, ok := contentTypes[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported request format %q", format)
+	}
+
+	if body == "" {
+		if format != FormatJSON {
+			return nil, fmt.Errorf("a body template is required for format %q", format)
+		}
+		body = DefaultBodyTemplate
+	}
+
+	bodyTmpl, err := template.New("body").Funcs(templateFuncs).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template: %w", err)
+	}
+
+	rt := &RequestTemplate{
+		contentType: contentType,
+		body:        bodyTmpl,
+		headers:     make(map[string]*template.Template, len(headers)),
+	}
+
+	for name, value := range headers {
+		tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for header %s: %w", name, err)
+		}
+		rt.headers[name] = tmpl
+	}
+
+	return rt, nil
+}
+
+func (t *RequestTemplate) render(message Request) ([]byte, map[string]string, error) {
+	var body bytes.Buffer
+	if err := t.body.Execute(&body, message); err != nil {
+		return nil, nil, fmt.Errorf("failed to render body: %w", err)
+	}
+
+	headers := make(map[string]string, len(t.headers))
+	for name, tmpl := range t.headers {
+		var value strings.Builder
+		if err := tmpl.Execute(&value, message); err != nil {
+			return nil, nil, fmt.Errorf("failed to render header %s: %w", name, err)
+		}
+		headers[name] = value.String()
+	}
+
+	return body.Bytes(), headers, nil
+}
+
+var ErrRejected = errors.New("message rejected by provider")
+
+// ResponseMapping locates fields in a JSON response body. Paths are dot
+// separated keys with numeric array indexes and an optional "$." prefix, e.g.
+// "data.messages.0.id".
+type ResponseMapping struct {
+	MessageIDPath string
+	StatusPath    string
+	// AcceptedStatuses lists the status values that mean the provider took
+	// the message. Any other value fails the send with ErrRejected. Empty
+	// accepts every status.
+	AcceptedStatuses []string
+}
+
+// DefaultResponseMapping reads the {message, messageId} response the client
+// always expected before mappings were configurable.
+var DefaultResponseMapping = ResponseMapping{
+	MessageIDPath: "messageId",
+	StatusPath:    "message",
+}
+
+func (m ResponseMapping) apply(body []byte, response *Response) error {
+	if m.MessageIDPath == "" && m.StatusPath == "" {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if m.MessageIDPath != "" {
+		id, err := lookup(doc, m.MessageIDPath)
+		if err != nil {
+			return err
+		}
+		response.MessageID = id
+	}
+
+	if m.StatusPath != "" {
+		status, err := lookup(doc, m.StatusPath)
+		if err != nil {
+			return err
+		}
+		response.Message = status
+	}
+
+	if len(m.AcceptedStatuses) > 0 && !slices.Contains(m.AcceptedStatuses, response.Message) {
+		return fmt.Errorf("%w: status %q", ErrRejected, response.Message)
+	}
+
+	return nil
+}
+
+// lookup resolves path in a decoded JSON document and returns the value as a
+// string. Missing keys yield an empty string.
+func lookup(doc any, path string) (string, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+
+	current := doc
+	for _, key := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			current = node[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return "", nil
+			}
+			current = node[i]
+		default:
+			return "", nil
+		}
+	}
+
+	switch v := current.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("response path %q does not point to a scalar value", path)
+	}
+}
@@ -0,0 +1,156 @@
+//go:build unit
+
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/ohttp"
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type capturedRequest struct {
+	contentType string
+	header      http.Header
+	body        string
+}
+
+func newCapturingServer(t *testing.T, status int, response string) (*httptest.Server, *capturedRequest) {
+	t.Helper()
+	captured := &capturedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		captured.contentType = r.Header.Get("Content-Type")
+		captured.header = r.Header.Clone()
+		captured.body = string(body)
+		w.Header().Set(ohttp.HeaderRetryAttempt, "1")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, captured
+}
+
+func TestSend_RequestTemplate(t *testing.T) {
+	message := webhook.Request{MessageID: 7, To: "+905551112233", Content: `Tom & "Jerry" <3`, IdempotencyKey: "message-7"}
+
+	tests := []struct {
+		name            string
+		format          string
+		body            string
+		headers         map[string]string
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "Given no template, the default JSON body is sent",
+			wantContentType: "application/json",
+			wantBody:        `{"to":"+905551112233","content":"Tom \u0026 \"Jerry\" \u003c3"}`,
+		},
+		{
+			name:            "Given a form template, values are URL encoded",
+			format:          webhook.FormatForm,
+			body:            "phone={{form .To}}&text={{form .Content}}&ref={{.MessageID}}",
+			wantContentType: "application/x-www-form-urlencoded",
+			wantBody:        "phone=%2B905551112233&text=Tom+%26+%22Jerry%22+%3C3&ref=7",
+		},
+		{
+			name:            "Given an XML template, values are escaped",
+			format:          webhook.FormatXML,
+			body:            "<sms><to>{{xml .To}}</to><text>{{xml .Content}}</text></sms>",
+			headers:         map[string]string{"X-Reference": "ref-{{.IdempotencyKey}}"},
+			wantContentType: "application/xml",
+			wantBody:        "<sms><to>+905551112233</to><text>Tom &amp; &#34;Jerry&#34; &lt;3</text></sms>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, captured := newCapturingServer(t, http.StatusOK, `{"message": "Accepted", "messageId": "ext-1"}`)
+
+			tmpl, err := webhook.NewRequestTemplate(tt.format, tt.body, tt.headers)
+			require.NoError(t, err)
+			client := webhook.NewClient(server.URL, ohttp.NewClient(), webhook.Config{Request: tmpl})
+
+			_, err = client.Send(context.Background(), message, "/send")
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantContentType, captured.contentType)
+			assert.Equal(t, tt.wantBody, captured.body)
+			for name := range tt.headers {
+				assert.Equal(t, "ref-message-7", captured.header.Get(name))
+			}
+		})
+	}
+}
+
+func TestNewRequestTemplate_Invalid(t *testing.T) {
+	_, err := webhook.NewRequestTemplate("yaml", "", nil)
+	assert.Error(t, err)
+
+	_, err = webhook.NewRequestTemplate(webhook.FormatForm, "", nil)
+	assert.Error(t, err, "non-JSON formats need an explicit body")
+
+	_, err = webhook.NewRequestTemplate(webhook.FormatJSON, "{{.To", nil)
+	assert.Error(t, err)
+
+	_, err = webhook.NewRequestTemplate(webhook.FormatJSON, "", map[string]string{"X-Bad": "{{"})
+	assert.Error(t, err)
+}
+
+func TestSend_ResponseMapping(t *testing.T) {
+	body := `{"data": {"status": "queued", "messages": [{"id": 12345678901234567}]}}`
+
+	tests := []struct {
+		name       string
+		status     int
+		mapping    webhook.ResponseMapping
+		wantID     string
+		wantStatus string
+		wantErr    error
+	}{
+		{
+			name:       "Given nested paths, the values are extracted",
+			status:     http.StatusAccepted,
+			mapping:    webhook.ResponseMapping{MessageIDPath: "$.data.messages.0.id", StatusPath: "data.status"},
+			wantID:     "12345678901234567",
+			wantStatus: "queued",
+		},
+		{
+			name:       "Given an accepted status, the send succeeds",
+			status:     http.StatusOK,
+			mapping:    webhook.ResponseMapping{MessageIDPath: "data.messages.0.id", StatusPath: "data.status", AcceptedStatuses: []string{"queued"}},
+			wantID:     "12345678901234567",
+			wantStatus: "queued",
+		},
+		{
+			name:    "Given a status outside the accepted list, the send is rejected",
+			status:  http.StatusOK,
+			mapping: webhook.ResponseMapping{StatusPath: "data.status", AcceptedStatuses: []string{"sent"}},
+			wantErr: webhook.ErrRejected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newCapturingServer(t, tt.status, body)
+			mapping := tt.mapping
+			client := webhook.NewClient(server.URL, ohttp.NewClient(), webhook.Config{Response: &mapping})
+
+			resp, err := client.Send(context.Background(), webhook.Request{To: "1", Content: "hi"}, "/send")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, resp.MessageID)
+			assert.Equal(t, tt.wantStatus, resp.Message)
+		})
+	}
+}
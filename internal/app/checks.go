@@ -0,0 +1,166 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultCheckTimeout       = 2 * time.Second
+	DefaultCheckCacheTTL      = 5 * time.Second
+	DefaultSchedulerMaxMissed = 3
+)
+
+// Checker reports whether a dependency is usable. It should return promptly
+// once ctx is done.
+type Checker func(ctx context.Context) error
+
+// CheckResult is the outcome of one check run.
+type CheckResult struct {
+	Name      string
+	Err       error
+	Latency   time.Duration
+	CheckedAt time.Time
+}
+
+// CheckRegistry runs named checks concurrently, each bounded by a timeout.
+// Results are cached so frequent probes do not hammer the dependencies.
+type CheckRegistry struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+	draining atomic.Bool
+
+	mu     sync.Mutex
+	names  []string
+	checks map[string]Checker
+	cache  map[string]CheckResult
+}
+
+func NewCheckRegistry(timeout, cacheTTL time.Duration) *CheckRegistry {
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+	if cacheTTL < 0 {
+		cacheTTL = 0
+	}
+
+	return &CheckRegistry{
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+		checks:   make(map[string]Checker),
+		cache:    make(map[string]CheckResult),
+	}
+}
+
+// Register adds a check, replacing any registered under the same name.
+func (r *CheckRegistry) Register(name string, check Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.checks[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.checks[name] = check
+	delete(r.cache, name)
+}
+
+// Drain marks the service as shutting down. Probes backed by the registry
+// should report it unavailable from then on.
+func (r *CheckRegistry) Drain() {
+	r.draining.Store(true)
+}
+
+func (r *CheckRegistry) Draining() bool {
+	return r.draining.Load()
+}
+
+// Run returns the result of every check in registration order, running those
+// whose cached result has expired.
+func (r *CheckRegistry) Run(ctx context.Context) []CheckResult {
+	now := time.Now()
+
+	r.mu.Lock()
+	results := make([]CheckResult, len(r.names))
+	stale := make(map[int]Checker)
+	for i, name := range r.names {
+		cached, ok := r.cache[name]
+		if ok && now.Sub(cached.CheckedAt) < r.cacheTTL {
+			results[i] = cached
+			continue
+		}
+		results[i] = CheckResult{Name: name}
+		stale[i] = r.checks[name]
+	}
+	r.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i, check := range stale {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.run(ctx, results[i].Name, check)
+		}()
+	}
+	wg.Wait()
+
+	r.mu.Lock()
+	for i := range stale {
+		r.cache[results[i].Name] = results[i]
+	}
+	r.mu.Unlock()
+
+	return results
+}
+
+func (r *CheckRegistry) run(ctx context.Context, name string, check Checker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := runCheck(ctx, check)
+	return CheckResult{
+		Name:      name,
+		Err:       err,
+		Latency:   time.Since(start),
+		CheckedAt: start,
+	}
+}
+
+// runCheck enforces the timeout even for checks that ignore ctx.
+func runCheck(ctx context.Context, check Checker) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("check timed out: %w", ctx.Err())
+	}
+}
+
+// SchedulerCheck fails when a running scheduler has not completed a tick for
+// maxMissed intervals. A stopped scheduler passes, since stopping delivery is
+// an operator decision rather than a fault.
+func SchedulerCheck(s *Scheduler, maxMissed int) Checker {
+	if maxMissed <= 0 {
+		maxMissed = DefaultSchedulerMaxMissed
+	}
+
+	return func(context.Context) error {
+		if !s.Running() {
+			return nil
+		}
+
+		limit := time.Duration(maxMissed) * s.Interval()
+		if since := time.Since(s.LastTick()); since > limit {
+			return fmt.Errorf("scheduler %s last ticked %s ago, limit %s", s.name, since.Round(time.Second), limit)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+//go:build unit
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRegistry_Run(t *testing.T) {
+	t.Run("Given passing and failing checks, results keep registration order", func(t *testing.T) {
+		registry := app.NewCheckRegistry(time.Second, 0)
+		registry.Register("db", func(context.Context) error { return nil })
+		registry.Register("redis", func(context.Context) error { return errors.New("connection refused") })
+
+		results := registry.Run(context.Background())
+
+		require.Len(t, results, 2)
+		assert.Equal(t, "db", results[0].Name)
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, "redis", results[1].Name)
+		assert.EqualError(t, results[1].Err, "connection refused")
+		assert.False(t, results[1].CheckedAt.IsZero())
+	})
+
+	t.Run("Given a check slower than the timeout, it should fail", func(t *testing.T) {
+		registry := app.NewCheckRegistry(20*time.Millisecond, 0)
+		registry.Register("slow", func(context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		})
+
+		start := time.Now()
+		results := registry.Run(context.Background())
+
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.ErrorIs(t, results[0].Err, context.DeadlineExceeded)
+	})
+
+	t.Run("Given a cached result, the check should not run again until it expires", func(t *testing.T) {
+		var runs int32
+		registry := app.NewCheckRegistry(time.Second, 50*time.Millisecond)
+		registry.Register("db", func(context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		})
+
+		registry.Run(context.Background())
+		registry.Run(context.Background())
+		assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+
+		time.Sleep(60 * time.Millisecond)
+		registry.Run(context.Background())
+		assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+	})
+
+	t.Run("Given a drained registry, it should report draining", func(t *testing.T) {
+		registry := app.NewCheckRegistry(time.Second, 0)
+		assert.False(t, registry.Draining())
+
+		registry.Drain()
+		assert.True(t, registry.Draining())
+	})
+}
+
+func TestSchedulerCheck(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+	t.Run("Given a stopped scheduler, it should pass", func(t *testing.T) {
+		scheduler := app.NewScheduler("test", time.Millisecond, func(context.Context) error { return nil }, logger)
+
+		assert.NoError(t, app.SchedulerCheck(scheduler, 1)(context.Background()))
+	})
+
+	t.Run("Given a ticking scheduler, it should pass", func(t *testing.T) {
+		scheduler := app.NewScheduler("test", 10*time.Millisecond, func(context.Context) error { return nil }, logger)
+		scheduler.Start()
+		defer scheduler.Stop()
+
+		time.Sleep(25 * time.Millisecond)
+		assert.NoError(t, app.SchedulerCheck(scheduler, 3)(context.Background()))
+	})
+
+	t.Run("Given a scheduler stuck in its task, it should fail", func(t *testing.T) {
+		release := make(chan struct{})
+		task := func(context.Context) error {
+			<-release
+			return nil
+		}
+		scheduler := app.NewScheduler("test", 5*time.Millisecond, task, logger)
+		scheduler.Start()
+		defer scheduler.Stop()
+		defer close(release)
+
+		time.Sleep(30 * time.Millisecond)
+		assert.Error(t, app.SchedulerCheck(scheduler, 2)(context.Background()))
+	})
+}
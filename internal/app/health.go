@@ -0,0 +1,120 @@
+package app
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHealthAlpha     = 0.3
+	DefaultHealthThreshold = 0.5
+	DefaultHealthHalfLife  = 5 * time.Minute
+)
+
+type healthEntry struct {
+	score     float64
+	updatedAt time.Time
+}
+
+// ProviderHealth keeps an exponentially weighted success score per provider,
+// between 0 (always failing) and 1 (always succeeding). Scores drift back
+// towards 1 over time so a demoted provider is eventually tried again.
+type ProviderHealth struct {
+	alpha     float64
+	threshold float64
+	halfLife  time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]healthEntry
+}
+
+func NewProviderHealth() *ProviderHealth {
+	return &ProviderHealth{
+		alpha:     DefaultHealthAlpha,
+		threshold: DefaultHealthThreshold,
+		halfLife:  DefaultHealthHalfLife,
+		now:       time.Now,
+		entries:   make(map[string]healthEntry),
+	}
+}
+
+func (h *ProviderHealth) Record(name string, success bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	outcome := 0.0
+	if success {
+		outcome = 1.0
+	}
+
+	now := h.now()
+	score := h.scoreAt(name, now)
+	h.entries[name] = healthEntry{
+		score:     h.alpha*outcome + (1-h.alpha)*score,
+		updatedAt: now,
+	}
+}
+
+func (h *ProviderHealth) Score(name string) float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.scoreAt(name, h.now())
+}
+
+// Scores returns the current score of every provider that has been recorded.
+func (h *ProviderHealth) Scores() map[string]float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	scores := make(map[string]float64, len(h.entries))
+	for name := range h.entries {
+		scores[name] = h.scoreAt(name, now)
+	}
+	return scores
+}
+
+// Order returns providers with every healthy one first, in the given priority
+// order, followed by degraded ones sorted by score.
+func (h *ProviderHealth) Order(providers []Provider) []Provider {
+	scores := make(map[string]float64, len(providers))
+	for _, provider := range providers {
+		scores[provider.Name()] = h.Score(provider.Name())
+	}
+
+	ordered := make([]Provider, len(providers))
+	copy(ordered, providers)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		si, sj := scores[ordered[i].Name()], scores[ordered[j].Name()]
+		healthyI, healthyJ := si >= h.threshold, sj >= h.threshold
+		if healthyI != healthyJ {
+			return healthyI
+		}
+		if !healthyI {
+			return si > sj
+		}
+		return false
+	})
+
+	return ordered
+}
+
+func (h *ProviderHealth) scoreAt(name string, now time.Time) float64 {
+	entry, ok := h.entries[name]
+	if !ok {
+		return 1
+	}
+
+	elapsed := now.Sub(entry.updatedAt)
+	if elapsed <= 0 || h.halfLife <= 0 {
+		return entry.score
+	}
+
+	decay := math.Pow(0.5, float64(elapsed)/float64(h.halfLife))
+	return 1 - (1-entry.score)*decay
+}
@@ -0,0 +1,53 @@
+//go:build unit
+
+package app_test
+
+import (
+	"testing"
+
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderHealth_Order(t *testing.T) {
+	primary := &fakeProvider{name: "primary"}
+	secondary := &fakeProvider{name: "secondary"}
+	tertiary := &fakeProvider{name: "tertiary"}
+	providers := []app.Provider{primary, secondary, tertiary}
+
+	t.Run("given no history, the configured order is kept", func(t *testing.T) {
+		health := app.NewProviderHealth()
+		assert.Equal(t, providers, health.Order(providers))
+	})
+
+	t.Run("given a degraded primary, healthy providers move ahead of it", func(t *testing.T) {
+		health := app.NewProviderHealth()
+		for i := 0; i < 5; i++ {
+			health.Record("primary", false)
+		}
+
+		assert.Equal(t, []app.Provider{secondary, tertiary, primary}, health.Order(providers))
+	})
+
+	t.Run("given several degraded providers, the healthier one goes first", func(t *testing.T) {
+		health := app.NewProviderHealth()
+		for i := 0; i < 5; i++ {
+			health.Record("primary", false)
+		}
+		for i := 0; i < 3; i++ {
+			health.Record("secondary", false)
+		}
+
+		assert.Equal(t, []app.Provider{tertiary, secondary, primary}, health.Order(providers))
+	})
+
+	t.Run("given successes, the score recovers", func(t *testing.T) {
+		health := app.NewProviderHealth()
+		health.Record("primary", false)
+		low := health.Score("primary")
+		health.Record("primary", true)
+
+		assert.Greater(t, health.Score("primary"), low)
+		assert.LessOrEqual(t, health.Score("primary"), 1.0)
+	})
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/trace"
 
-	"github.com/muratdemir0/gopulse-messages/internal/adapters/webhook"
 	"github.com/muratdemir0/gopulse-messages/internal/domain"
-	"github.com/muratdemir0/gopulse-messages/internal/infra/cache"
 )
 
 type messageCacheData struct {
@@ -18,44 +21,74 @@ type messageCacheData struct {
 	SentAt    string `json:"sentAt"`
 }
 
+type Cache interface {
+	Set(ctx context.Context, key string, value interface{}) error
+}
+
+// dispatchBatchSize is the number of due messages sent per dispatch round.
+const dispatchBatchSize = 2
+
 type MessageService struct {
-	messageRepo   domain.MessageRepository
-	webhookClient *webhook.Client
-	cache         *cache.Cache
-	scheduler     *Scheduler
-	webhookPath   string
-	logger        *slog.Logger
+	messageRepo domain.MessageRepository
+	attemptRepo domain.MessageAttemptRepository
+	providers   *ProviderRegistry
+	router      *Router
+	outbox      *Outbox
+	health      *ProviderHealth
+	cache       Cache
+	scheduler   *Scheduler
+	sweepDelay  atomic.Int64
+	metrics     *serviceMetrics
+	logger      *slog.Logger
 }
 
 func NewMessageService(
 	messageRepo domain.MessageRepository,
-	webhookClient *webhook.Client,
-	cache *cache.Cache,
-	webhookPath string,
+	attemptRepo domain.MessageAttemptRepository,
+	providers *ProviderRegistry,
+	router *Router,
+	outbox *Outbox,
+	cache Cache,
 	logger *slog.Logger,
 ) *MessageService {
 	service := &MessageService{
-		messageRepo:   messageRepo,
-		webhookClient: webhookClient,
-		cache:         cache,
-		webhookPath:   webhookPath,
-		logger:        logger.With(slog.String("component", "message_service")),
+		messageRepo: messageRepo,
+		attemptRepo: attemptRepo,
+		providers:   providers,
+		router:      router,
+		outbox:      outbox,
+		health:      NewProviderHealth(),
+		cache:       cache,
+		metrics:     newServiceMetrics(),
+		logger:      logger.With(slog.String("component", "message_service")),
 	}
 
-	service.scheduler = NewScheduler(2*time.Minute, service.processMessages, logger)
+	service.scheduler = NewScheduler("dispatch", 2*time.Minute, service.processMessages, logger)
+	registerBacklogMetrics(messageRepo)
 
 	return service
 }
 
+// SetSweepDelay makes the database poller skip messages younger than delay,
+// leaving them to the work queue so the poller only reconciles what the queue
+// missed. It can be changed while sending.
+func (s *MessageService) SetSweepDelay(delay time.Duration) {
+	s.sweepDelay.Store(int64(delay))
+}
+
+func (s *MessageService) dueBefore() time.Time {
+	return time.Now().Add(-time.Duration(s.sweepDelay.Load()))
+}
+
 func (s *MessageService) StartAutoSending() error {
 	ctx := context.Background()
-	s.logger.Info("Processing existing unsent messages on startup...")
+	s.logger.InfoContext(ctx, "Processing existing unsent messages on startup...")
 	if err := s.processAllMessages(ctx); err != nil {
-		s.logger.Error("Error processing messages on startup", "error", err)
+		s.logger.ErrorContext(ctx, "Error processing messages on startup", "error", err)
 	}
 
 	s.scheduler.Start()
-	s.logger.Info("Automatic message sending started")
+	s.logger.InfoContext(ctx, "Automatic message sending started")
 	return nil
 }
 
@@ -65,131 +98,471 @@ func (s *MessageService) StopAutoSending() error {
 	return nil
 }
 
+// processAllMessages sends the backlog on startup. It claims one dispatch
+// batch at a time, so a large backlog never outlives its claim and gets picked
+// up by another replica while still being sent here.
 func (s *MessageService) processAllMessages(ctx context.Context) error {
-	messages, err := s.messageRepo.GetAllDue(ctx)
-	if err != nil {
-		s.logger.Error("Error finding all due messages", "error", err)
-		return err
-	}
-
-	if len(messages) == 0 {
-		s.logger.Info("No pending messages to process on startup")
-		return nil
-	}
-
-	s.logger.Info("Processing all pending messages on startup", "count", len(messages))
+	// Messages created from now on are left to the scheduler, so the pass ends
+	// even while new messages keep arriving.
+	dueBefore := s.dueBefore()
+	total := 0
+
+	for batch := 1; ; batch++ {
+		messages, err := s.messageRepo.FindDue(ctx, dueBefore, dispatchBatchSize)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Error finding due messages on startup", "error", err)
+			return err
+		}
 
-	const batchSize = 10
-	for i := 0; i < len(messages); i += batchSize {
-		end := i + batchSize
-		if end > len(messages) {
-			end = len(messages)
+		if len(messages) == 0 {
+			break
 		}
 
-		batch := messages[i:end]
-		s.logger.Info("Processing message batch", "batch", i/batchSize+1, "size", len(batch))
+		s.logger.InfoContext(ctx, "Processing message batch", "batch", batch, "size", len(messages))
+		total += len(messages)
 
-		for _, message := range batch {
+		failed := false
+		for i, message := range messages {
 			if err := s.processMessage(ctx, message); err != nil {
-				s.logger.Error("Error sending message in startup batch", "message_id", message.ID, "error", err)
+				if errors.Is(err, ErrProviderUnavailable) {
+					s.logger.WarnContext(ctx, "Provider unavailable, deferring remaining startup messages", "message_id", message.ID)
+					s.release(ctx, messages[i:])
+					return nil
+				}
+				failed = true
+				s.logger.ErrorContext(ctx, "Error sending message in startup batch", "message_id", message.ID, "error", err)
 				if err := s.messageRepo.IncrementRetry(ctx, message.ID, time.Now()); err != nil {
-					s.logger.Error("Error incrementing retry for message in startup batch", "message_id", message.ID, "error", err)
+					s.logger.ErrorContext(ctx, "Error incrementing retry for message in startup batch", "message_id", message.ID, "error", err)
 				}
 			}
 		}
+
+		// Failed messages are due again right away; leave them and whatever
+		// follows to the scheduler so retries stay spaced out.
+		if failed || len(messages) < dispatchBatchSize {
+			break
+		}
+	}
+
+	if total == 0 {
+		s.logger.InfoContext(ctx, "No pending messages to process on startup")
+		return nil
 	}
 
-	s.logger.Info("Completed processing all pending messages on startup", "total_processed", len(messages))
+	s.logger.InfoContext(ctx, "Completed processing pending messages on startup", "total_processed", total)
 	return nil
 }
 
-func (s *MessageService) processMessages(ctx context.Context) error {
-	messages, err := s.messageRepo.FindDue(ctx, 2)
+// Scheduler returns the scheduler driving the database poller.
+func (s *MessageService) Scheduler() *Scheduler {
+	return s.scheduler
+}
+
+// Wake runs a dispatch round now instead of at the next tick.
+func (s *MessageService) Wake() {
+	s.scheduler.Trigger()
+}
+
+func (s *MessageService) processMessages(ctx context.Context) (err error) {
+	ctx, span := tracer().Start(ctx, "dispatch.batch")
+	defer func() { endSpan(span, err) }()
+
+	messages, err := s.messageRepo.FindDue(ctx, s.dueBefore(), dispatchBatchSize)
 	if err != nil {
-		s.logger.Error("Error finding due messages", "error", err)
+		s.logger.ErrorContext(ctx, "Error finding due messages", "error", err)
 		return err
 	}
+	span.SetAttributes(attrBatchSize.Int(len(messages)))
 
 	if len(messages) == 0 {
-		s.logger.Info("No pending messages to process")
+		s.logger.InfoContext(ctx, "No pending messages to process")
 		return nil
 	}
 
-	s.logger.Info("Processing messages", "count", len(messages))
+	s.logger.InfoContext(ctx, "Processing messages", "count", len(messages))
 
-	for _, message := range messages {
+	failed := false
+	for i, message := range messages {
 		if err := s.processMessage(ctx, message); err != nil {
-			s.logger.Error("Error sending message", "message_id", message.ID, "error", err)
+			if errors.Is(err, ErrProviderUnavailable) {
+				s.logger.WarnContext(ctx, "Provider unavailable, skipping dispatch for this tick", "message_id", message.ID)
+				s.release(ctx, messages[i:])
+				return nil
+			}
+			failed = true
+			s.logger.ErrorContext(ctx, "Error sending message", "message_id", message.ID, "error", err)
 			if err := s.messageRepo.IncrementRetry(ctx, message.ID, time.Now()); err != nil {
-				s.logger.Error("Error incrementing retry for message", "message_id", message.ID, "error", err)
+				s.logger.ErrorContext(ctx, "Error incrementing retry for message", "message_id", message.ID, "error", err)
 			}
 		}
 	}
 
+	// A full clean batch means more messages may be waiting, such as a burst
+	// of inserts debounced into one wake-up, so keep going instead of leaving
+	// them for the next tick. Each round claims its own batch, so replicas
+	// draining together never send the same message. Failed sends wait for
+	// the tick so retries stay spaced out.
+	if len(messages) == dispatchBatchSize && !failed {
+		s.scheduler.Trigger()
+	}
+
+	return nil
+}
+
+// release drops the claims on messages left unsent, so they are due again at
+// the next round instead of when the claim expires.
+func (s *MessageService) release(ctx context.Context, messages []domain.Message) {
+	ids := make([]int64, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+	if err := s.messageRepo.Release(ctx, ids...); err != nil {
+		s.logger.ErrorContext(ctx, "Error releasing deferred messages", "count", len(ids), "error", err)
+	}
+}
+
+// ProcessMessageByID sends one message if it is still due. Messages that were
+// already sent or ran out of retries are skipped without error. Failed sends
+// count as a retry and are picked up again by the poller.
+func (s *MessageService) ProcessMessageByID(ctx context.Context, id int64) error {
+	message, err := s.messageRepo.FindDueByID(ctx, id)
+	if errors.Is(err, domain.ErrMessageNotDue) {
+		s.logger.DebugContext(ctx, "Message no longer due", "message_id", id)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.processMessage(ctx, message); err != nil {
+		if errors.Is(err, ErrProviderUnavailable) {
+			s.release(ctx, []domain.Message{message})
+			return err
+		}
+		s.logger.ErrorContext(ctx, "Error sending message", "message_id", message.ID, "error", err)
+		if err := s.messageRepo.IncrementRetry(ctx, message.ID, time.Now()); err != nil {
+			s.logger.ErrorContext(ctx, "Error incrementing retry for message", "message_id", message.ID, "error", err)
+		}
+	}
+
 	return nil
 }
 
-func (s *MessageService) processMessage(ctx context.Context, message domain.Message) error {
-	webhookReq := s.buildWebhookRequest(message)
+// processMessage sends the message through the providers chosen by
+// candidates. Transient failures and unavailable providers fail over to the
+// next one; permanent failures stop immediately. When every provider is
+// unavailable the message is left untouched and ErrProviderUnavailable is
+// returned.
+func (s *MessageService) processMessage(ctx context.Context, message domain.Message) (err error) {
+	ctx, span := tracer().Start(ctx, "message.dispatch",
+		trace.WithLinks(originLinks(message)...),
+		trace.WithAttributes(
+			attrMessageID.Int64(message.ID),
+			attrRecipientHash.String(hashRecipient(message.Recipient)),
+			attrChannel.String(string(message.ChannelOrDefault())),
+			attrAttempt.Int(message.RetryCount+1),
+		),
+	)
+	defer func() {
+		outcome := outcomeSent
+		switch {
+		case errors.Is(err, ErrProviderUnavailable):
+			outcome = outcomeDeferred
+		case err != nil:
+			outcome = outcomeFailed
+		}
+		span.SetAttributes(attrOutcome.String(outcome))
+		endSpan(span, err)
+	}()
+
+	if err := message.Validate(); err != nil {
+		return s.handleSendFailure(ctx, message, message.FailedAttempts, err)
+	}
+
+	candidates := s.candidates(ctx, message)
+	if len(candidates) == 0 {
+		err := fmt.Errorf("%w: %s", ErrNoProviderForChannel, message.ChannelOrDefault())
+		return s.handleSendFailure(ctx, message, message.FailedAttempts, err)
+	}
+
+	req := s.buildSendRequest(message)
+	failed := append(domain.ProviderAttempts(nil), message.FailedAttempts...)
+	unavailable := 0
+
+	var lastErr error
+	for _, provider := range candidates {
+		name := provider.Name()
+		span.SetAttributes(attrProvider.String(name))
+
+		if limit := provider.Capabilities().MaxContentLength; limit > 0 && len([]rune(message.Content)) > limit {
+			lastErr = fmt.Errorf("provider %s: content exceeds %d characters", name, limit)
+			failed = append(failed, s.failedAttempt(name, lastErr))
+			continue
+		}
+
+		start := time.Now()
+		resp, err := provider.Send(ctx, req)
+		latency := time.Since(start)
+		s.recordHealth(name, err)
+
+		attempt := newAttempt(message.ID, name, latency, resp, err)
+		s.metrics.send(ctx, name, latency, attempt.ErrorClass)
+		s.recordAttempt(ctx, attempt)
+		if attempt.StatusCode.Valid {
+			message.ResponseCode = attempt.StatusCode
+		}
+
+		if err == nil {
+			return s.handleSendSuccess(ctx, message, name, failed, resp)
+		}
+
+		lastErr = fmt.Errorf("provider %s: %w", name, err)
+
+		if errors.Is(err, ErrProviderUnavailable) {
+			unavailable++
+			continue
+		}
+
+		failed = append(failed, s.failedAttempt(name, err))
+		if !errors.Is(err, ErrTransient) {
+			break
+		}
+
+		s.logger.WarnContext(ctx, "Provider failed, trying next provider", "message_id", message.ID, "provider", name, "error", err)
+	}
+
+	if unavailable > 0 && len(failed) == len(message.FailedAttempts) {
+		return lastErr
+	}
+
+	return s.handleSendFailure(ctx, message, failed, lastErr)
+}
+
+// candidates returns the providers for the message channel to try. The
+// provider picked by the matching routing rule comes first, followed by the
+// rule's other targets and then the remaining providers in health order.
+func (s *MessageService) candidates(ctx context.Context, message domain.Message) []Provider {
+	channel := message.ChannelOrDefault()
+
+	var ordered []Provider
+	for _, provider := range s.health.Order(s.providers.All()) {
+		if provider.Capabilities().Supports(channel) {
+			ordered = append(ordered, provider)
+		}
+	}
+
+	if s.router == nil {
+		return ordered
+	}
+
+	decision := s.router.Route(ctx, message)
+	if decision.Rule == nil {
+		return ordered
+	}
+
+	s.logger.DebugContext(ctx, "Routing rule matched", "message_id", message.ID, "rule", decision.Rule.Name, "provider", decision.Provider)
+
+	preferred := []string{decision.Provider}
+	for _, target := range decision.Rule.Targets {
+		if target.Provider != decision.Provider {
+			preferred = append(preferred, target.Provider)
+		}
+	}
+
+	result := make([]Provider, 0, len(ordered))
+	seen := make(map[string]bool, len(ordered))
+	for _, name := range preferred {
+		provider, ok := s.providers.Get(name)
+		if ok && !seen[name] && provider.Capabilities().Supports(channel) {
+			result = append(result, provider)
+			seen[name] = true
+		}
+	}
+	for _, provider := range ordered {
+		if !seen[provider.Name()] {
+			result = append(result, provider)
+		}
+	}
+	return result
+}
+
+// newAttempt builds the delivery record for one provider call.
+func newAttempt(messageID int64, provider string, latency time.Duration, resp *SendResult, err error) domain.MessageAttempt {
+	attempt := domain.MessageAttempt{
+		MessageID:  messageID,
+		Provider:   provider,
+		LatencyMs:  latency.Milliseconds(),
+		ErrorClass: errorClass(err),
+	}
+
+	var (
+		statusCode   int
+		responseBody string
+		retries      int
+	)
+	if resp != nil {
+		statusCode, responseBody, retries = resp.StatusCode, resp.ResponseBody, resp.Attempts
+	}
 
-	resp, err := s.webhookClient.Send(ctx, webhookReq, s.webhookPath)
 	if err != nil {
-		return s.handleSendFailure(ctx, message, err)
+		attempt.Error = sql.NullString{String: err.Error(), Valid: true}
+
+		var sendErr *SendError
+		if errors.As(err, &sendErr) {
+			statusCode, responseBody, retries = sendErr.StatusCode, sendErr.ResponseBody, sendErr.Attempts
+		}
+	}
+
+	if statusCode > 0 {
+		attempt.StatusCode = sql.NullInt64{Int64: int64(statusCode), Valid: true}
+	}
+	if responseBody != "" {
+		attempt.ResponseBody = sql.NullString{String: truncate(responseBody, domain.MaxAttemptResponseBody), Valid: true}
+	}
+	if retries > 0 {
+		attempt.RetryAttempt = sql.NullInt64{Int64: int64(retries), Valid: true}
+	}
+
+	return attempt
+}
+
+func errorClass(err error) domain.AttemptErrorClass {
+	switch {
+	case err == nil:
+		return domain.AttemptErrorNone
+	case errors.Is(err, ErrProviderUnavailable):
+		return domain.AttemptErrorUnavailable
+	case errors.Is(err, ErrTransient):
+		return domain.AttemptErrorTransient
+	default:
+		return domain.AttemptErrorPermanent
 	}
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func (s *MessageService) recordAttempt(ctx context.Context, attempt domain.MessageAttempt) {
+	if err := s.attemptRepo.Create(ctx, &attempt); err != nil {
+		s.logger.ErrorContext(ctx, "Error recording delivery attempt", "message_id", attempt.MessageID, "provider", attempt.Provider, "error", err)
+	}
+}
+
+func (s *MessageService) failedAttempt(provider string, err error) domain.ProviderAttempt {
+	return domain.ProviderAttempt{
+		Provider:    provider,
+		Error:       err.Error(),
+		AttemptedAt: time.Now(),
+	}
+}
+
+// recordHealth scores the provider on its own availability. Permanent errors
+// such as a rejected recipient say nothing about the provider, and an open
+// breaker means it was not called at all, so neither is recorded.
+func (s *MessageService) recordHealth(name string, err error) {
+	switch {
+	case err == nil:
+		s.health.Record(name, true)
+	case errors.Is(err, ErrTransient):
+		s.health.Record(name, false)
+	}
+}
 
-	return s.handleSendSuccess(ctx, message, resp)
+// ProviderHealth returns the current health score per provider.
+func (s *MessageService) ProviderHealth() map[string]float64 {
+	return s.health.Scores()
 }
 
-func (s *MessageService) buildWebhookRequest(message domain.Message) webhook.Request {
-	return webhook.Request{
-		To:      message.Recipient,
-		Content: message.Content,
+func (s *MessageService) buildSendRequest(message domain.Message) SendRequest {
+	return SendRequest{
+		MessageID:      message.ID,
+		Channel:        message.ChannelOrDefault(),
+		To:             message.Recipient,
+		Content:        message.Content,
+		Subject:        message.Subject.String,
+		HTMLContent:    message.HTMLContent.String,
+		IdempotencyKey: fmt.Sprintf("message-%d", message.ID),
 	}
 }
 
-func (s *MessageService) handleSendFailure(ctx context.Context, message domain.Message, sendErr error) error {
+func (s *MessageService) handleSendFailure(ctx context.Context, message domain.Message, failed domain.ProviderAttempts, sendErr error) error {
 	updatedMessage := message
 	updatedMessage.Status = domain.MessageStatusFailed
 	updatedMessage.ErrorMessage = sql.NullString{String: sendErr.Error(), Valid: true}
+	updatedMessage.FailedAttempts = failed
 
-	if updateErr := s.messageRepo.Update(ctx, updatedMessage); updateErr != nil {
-		s.logger.Error("Error updating failed message", "message_id", message.ID, "error", updateErr)
+	if updateErr := s.saveStatus(ctx, updatedMessage); updateErr != nil {
+		s.logger.ErrorContext(ctx, "Error updating failed message", "message_id", message.ID, "error", updateErr)
 	}
 
-	return fmt.Errorf("webhook send failed: %w", sendErr)
+	return fmt.Errorf("send failed: %w", sendErr)
 }
 
-func (s *MessageService) handleSendSuccess(ctx context.Context, message domain.Message, resp *webhook.Response) error {
+func (s *MessageService) handleSendSuccess(ctx context.Context, message domain.Message, provider string, failed domain.ProviderAttempts, resp *SendResult) error {
 	now := time.Now()
 
-	s.logger.Info("Successfully sent message",
+	s.logger.InfoContext(ctx, "Successfully sent message",
 		"message_id", message.ID,
 		"recipient", message.Recipient,
-		"attempt", resp.RetryAttempt)
+		"provider", provider,
+		"attempt", resp.Attempts)
 
-	if err := s.updateMessageAsSuccessful(ctx, message, resp, now); err != nil {
+	updatedMessage := message
+	updatedMessage.Provider = sql.NullString{String: provider, Valid: true}
+	updatedMessage.FailedAttempts = failed
+
+	if err := s.updateMessageAsSuccessful(ctx, updatedMessage, resp, now); err != nil {
 		return err
 	}
 
-	s.cacheMessageResult(ctx, message.ID, resp.MessageID, now)
+	s.cacheMessageResult(ctx, message.ID, resp.ProviderMessageID, now)
 	return nil
 }
 
-func (s *MessageService) updateMessageAsSuccessful(ctx context.Context, message domain.Message, resp *webhook.Response, sentAt time.Time) error {
+func (s *MessageService) updateMessageAsSuccessful(ctx context.Context, message domain.Message, resp *SendResult, sentAt time.Time) error {
 	updatedMessage := message
 	updatedMessage.Status = domain.MessageStatusSent
 	updatedMessage.SentAt = sql.NullTime{Time: sentAt, Valid: true}
-	updatedMessage.ResponseID = sql.NullString{String: resp.MessageID, Valid: true}
-	updatedMessage.RetryCount = resp.RetryAttempt
+	updatedMessage.ResponseID = sql.NullString{String: resp.ProviderMessageID, Valid: true}
+	updatedMessage.RetryCount = resp.Attempts
 
-	if err := s.messageRepo.Update(ctx, updatedMessage); err != nil {
-		s.logger.Error("Error updating sent message", "message_id", message.ID, "error", err)
+	if err := s.saveStatus(ctx, updatedMessage); err != nil {
+		s.logger.ErrorContext(ctx, "Error updating sent message", "message_id", message.ID, "error", err)
 		return fmt.Errorf("failed to update message status: %w", err)
 	}
 
 	return nil
 }
 
+// saveStatus updates the message and records its status event in the outbox
+// within one transaction.
+func (s *MessageService) saveStatus(ctx context.Context, message domain.Message) error {
+	err := s.outbox.Save(ctx, func(txCtx context.Context) (domain.OutboxEvent, error) {
+		if err := s.messageRepo.Update(txCtx, message); err != nil {
+			return domain.OutboxEvent{}, err
+		}
+		return newMessageStatusEvent(message, time.Now())
+	})
+	if err != nil {
+		return err
+	}
+
+	status := metricStatusSent
+	if message.Status == domain.MessageStatusFailed {
+		status = metricStatusFailed
+	}
+	s.metrics.message(ctx, status, message.Provider.String)
+	return nil
+}
+
 func (s *MessageService) cacheMessageResult(ctx context.Context, messageID int64, responseID string, sentAt time.Time) {
 	cacheKey := fmt.Sprintf("message:%d", messageID)
 
@@ -200,15 +573,50 @@ func (s *MessageService) cacheMessageResult(ctx context.Context, messageID int64
 
 	cacheData, err := json.Marshal(data)
 	if err != nil {
-		s.logger.Error("Error marshaling cache data for message", "message_id", messageID, "error", err)
+		s.logger.ErrorContext(ctx, "Error marshaling cache data for message", "message_id", messageID, "error", err)
 		return
 	}
 
 	if err := s.cache.Set(ctx, cacheKey, string(cacheData)); err != nil {
-		s.logger.Error("Error caching message", "message_id", messageID, "error", err)
+		s.logger.ErrorContext(ctx, "Error caching message", "message_id", messageID, "error", err)
 	}
 }
 
-func (s *MessageService) GetSentMessages(ctx context.Context, limit, offset uint) ([]domain.Message, error) {
-	return s.messageRepo.ListByStatus(ctx, string(domain.MessageStatusSent), limit, offset)
+// GetAttempts returns every recorded delivery attempt of a message, oldest
+// first.
+func (s *MessageService) GetAttempts(ctx context.Context, messageID int64) ([]domain.MessageAttempt, error) {
+	return s.attemptRepo.ListByMessage(ctx, messageID)
+}
+
+// CreateMessage stores a new pending message together with its created event.
+// The message remembers the creating span so its dispatch can link to it.
+func (s *MessageService) CreateMessage(ctx context.Context, message *domain.Message) (err error) {
+	ctx, span := tracer().Start(ctx, "message.create",
+		trace.WithAttributes(
+			attrRecipientHash.String(hashRecipient(message.Recipient)),
+			attrChannel.String(string(message.ChannelOrDefault())),
+		),
+	)
+	defer func() { endSpan(span, err) }()
+
+	message.Status = domain.MessageStatusPending
+	setOrigin(ctx, message)
+
+	err = s.outbox.Save(ctx, func(txCtx context.Context) (domain.OutboxEvent, error) {
+		if err := s.messageRepo.Create(txCtx, message); err != nil {
+			return domain.OutboxEvent{}, err
+		}
+		return newMessageStatusEvent(*message, time.Now())
+	})
+	if err != nil {
+		return err
+	}
+
+	span.SetAttributes(attrMessageID.Int64(message.ID))
+	s.metrics.message(ctx, metricStatusCreated, message.Provider.String)
+	return nil
+}
+
+func (s *MessageService) GetSentMessages(ctx context.Context, filter domain.MessageFilter, limit, offset uint) ([]domain.Message, error) {
+	return s.messageRepo.ListByStatus(ctx, string(domain.MessageStatusSent), filter, limit, offset)
 }
@@ -1,2 +1,596 @@
 //go:build unit
-package app_test
\ No newline at end of file
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	mu       sync.Mutex
+	due      []domain.Message
+	claimed  map[int64]bool
+	claims   []int
+	updated  map[int64]domain.Message
+	retried  map[int64]int
+	listed   []domain.Message
+	created  []domain.Message
+	released []int64
+}
+
+func newFakeRepo(due ...domain.Message) *fakeRepo {
+	return &fakeRepo{
+		due:     due,
+		claimed: make(map[int64]bool),
+		updated: make(map[int64]domain.Message),
+		retried: make(map[int64]int),
+	}
+}
+
+// addDue makes messages due after the repo was created, as inserts do.
+func (r *fakeRepo) addDue(messages ...domain.Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.due = append(r.due, messages...)
+}
+
+func (r *fakeRepo) Create(_ context.Context, message *domain.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.created = append(r.created, *message)
+	message.ID = int64(len(r.created))
+	return nil
+}
+
+func (r *fakeRepo) Update(_ context.Context, message domain.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updated[message.ID] = message
+	return nil
+}
+
+func (r *fakeRepo) GetAll(context.Context) ([]domain.Message, error) {
+	return r.due, nil
+}
+
+// FindDue claims up to limit messages that were never claimed before, so
+// every due message is handed out once.
+func (r *fakeRepo) FindDue(_ context.Context, _ time.Time, limit uint) ([]domain.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var messages []domain.Message
+	for _, message := range r.due {
+		if uint(len(messages)) == limit {
+			break
+		}
+		if !r.claimed[message.ID] {
+			r.claimed[message.ID] = true
+			messages = append(messages, message)
+		}
+	}
+	if len(messages) > 0 {
+		r.claims = append(r.claims, len(messages))
+	}
+	return messages, nil
+}
+
+func (r *fakeRepo) FindDueByID(_ context.Context, id int64) (domain.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.updated[id]; ok {
+		return domain.Message{}, domain.ErrMessageNotDue
+	}
+	for _, message := range r.due {
+		if message.ID == id {
+			return message, nil
+		}
+	}
+	return domain.Message{}, domain.ErrMessageNotDue
+}
+
+func (r *fakeRepo) IncrementRetry(_ context.Context, id int64, _ time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retried[id]++
+	return nil
+}
+
+func (r *fakeRepo) ListByStatus(context.Context, string, domain.MessageFilter, uint, uint) ([]domain.Message, error) {
+	return r.listed, nil
+}
+
+func (r *fakeRepo) Backlog(context.Context) (domain.MessageBacklog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return domain.MessageBacklog{Pending: int64(len(r.due))}, nil
+}
+
+func (r *fakeRepo) Release(_ context.Context, ids ...int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.released = append(r.released, ids...)
+	return nil
+}
+
+func (r *fakeRepo) updatedMessage(id int64) (domain.Message, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msg, ok := r.updated[id]
+	return msg, ok
+}
+
+func (r *fakeRepo) retries(id int64) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.retried[id]
+}
+
+type fakeProvider struct {
+	name         string
+	capabilities app.Capabilities
+	send         func(req app.SendRequest) (*app.SendResult, error)
+
+	mu   sync.Mutex
+	sent []app.SendRequest
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) Capabilities() app.Capabilities { return p.capabilities }
+
+func (p *fakeProvider) Send(_ context.Context, req app.SendRequest) (*app.SendResult, error) {
+	p.mu.Lock()
+	p.sent = append(p.sent, req)
+	p.mu.Unlock()
+	return p.send(req)
+}
+
+func (p *fakeProvider) sentCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.sent)
+}
+
+type fakeAttemptRepo struct {
+	mu       sync.Mutex
+	attempts []domain.MessageAttempt
+}
+
+func (r *fakeAttemptRepo) Create(_ context.Context, attempt *domain.MessageAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	attempt.ID = int64(len(r.attempts) + 1)
+	r.attempts = append(r.attempts, *attempt)
+	return nil
+}
+
+func (r *fakeAttemptRepo) ListByMessage(_ context.Context, messageID int64) ([]domain.MessageAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var attempts []domain.MessageAttempt
+	for _, attempt := range r.attempts {
+		if attempt.MessageID == messageID {
+			attempts = append(attempts, attempt)
+		}
+	}
+	return attempts, nil
+}
+
+type fakeCache struct {
+	mu   sync.Mutex
+	data map[string]interface{}
+}
+
+func (c *fakeCache) Set(_ context.Context, key string, value interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.data == nil {
+		c.data = make(map[string]interface{})
+	}
+	c.data[key] = value
+	return nil
+}
+
+func newTestService(t *testing.T, repo *fakeRepo, providers ...app.Provider) *app.MessageService {
+	t.Helper()
+	registry, err := app.NewProviderRegistry(providers...)
+	require.NoError(t, err)
+
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	return app.NewMessageService(repo, &fakeAttemptRepo{}, registry, nil, newTestOutbox(), &fakeCache{}, logger)
+}
+
+func runOnce(t *testing.T, service *app.MessageService) {
+	t.Helper()
+	require.NoError(t, service.StartAutoSending())
+	require.NoError(t, service.StopAutoSending())
+}
+
+func pendingMessage(id int64) domain.Message {
+	return domain.Message{
+		ID:        id,
+		Recipient: "+905551112233",
+		Content:   "hello",
+		Status:    domain.MessageStatusPending,
+	}
+}
+
+func TestMessageService_SendsThroughProvider(t *testing.T) {
+	repo := newFakeRepo(pendingMessage(1))
+	provider := &fakeProvider{
+		name: "primary",
+		send: func(req app.SendRequest) (*app.SendResult, error) {
+			return &app.SendResult{ProviderMessageID: "ext-1", Attempts: 2}, nil
+		},
+	}
+
+	runOnce(t, newTestService(t, repo, provider))
+
+	require.Equal(t, 1, provider.sentCount())
+	assert.Equal(t, "message-1", provider.sent[0].IdempotencyKey)
+
+	msg, ok := repo.updatedMessage(1)
+	require.True(t, ok)
+	assert.Equal(t, domain.MessageStatusSent, msg.Status)
+	assert.Equal(t, "ext-1", msg.ResponseID.String)
+	assert.Equal(t, 2, msg.RetryCount)
+}
+
+func TestMessageService_MarksFailedOnProviderError(t *testing.T) {
+	repo := newFakeRepo(pendingMessage(1))
+	provider := &fakeProvider{
+		name: "primary",
+		send: func(req app.SendRequest) (*app.SendResult, error) {
+			return nil, errors.New("boom")
+		},
+	}
+
+	runOnce(t, newTestService(t, repo, provider))
+
+	msg, ok := repo.updatedMessage(1)
+	require.True(t, ok)
+	assert.Equal(t, domain.MessageStatusFailed, msg.Status)
+	assert.Contains(t, msg.ErrorMessage.String, "boom")
+	assert.Equal(t, 1, repo.retries(1))
+}
+
+func TestMessageService_SkipsTickWhenProviderUnavailable(t *testing.T) {
+	repo := newFakeRepo(pendingMessage(1), pendingMessage(2))
+	provider := &fakeProvider{
+		name: "primary",
+		send: func(req app.SendRequest) (*app.SendResult, error) {
+			return nil, app.ErrProviderUnavailable
+		},
+	}
+
+	runOnce(t, newTestService(t, repo, provider))
+
+	assert.Equal(t, 1, provider.sentCount(), "remaining messages must be deferred")
+	_, updated := repo.updatedMessage(1)
+	assert.False(t, updated, "an unavailable provider must not fail the message")
+	assert.Zero(t, repo.retries(1), "an unavailable provider must not burn a retry")
+	assert.Equal(t, []int64{1, 2}, repo.released, "deferred messages must be released for the next round")
+}
+
+func TestMessageService_ClaimsStartupBacklogInBatches(t *testing.T) {
+	repo := newFakeRepo()
+	for id := int64(1); id <= 5; id++ {
+		repo.addDue(pendingMessage(id))
+	}
+	provider := &fakeProvider{
+		name: "primary",
+		send: func(req app.SendRequest) (*app.SendResult, error) {
+			return &app.SendResult{}, nil
+		},
+	}
+	service := newTestService(t, repo, provider)
+
+	require.NoError(t, service.StartAutoSending())
+	defer func() { _ = service.StopAutoSending() }()
+
+	assert.Equal(t, 5, provider.sentCount(), "the startup pass must send the whole backlog")
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	assert.Equal(t, []int{2, 2, 1}, repo.claims, "each claim must only cover the batch about to be sent")
+}
+
+func TestMessageService_DrainsBurstInOneWakeUp(t *testing.T) {
+	repo := newFakeRepo()
+	provider := &fakeProvider{
+		name: "primary",
+		send: func(req app.SendRequest) (*app.SendResult, error) {
+			return &app.SendResult{}, nil
+		},
+	}
+	service := newTestService(t, repo, provider)
+	require.NoError(t, service.StartAutoSending())
+	defer func() { _ = service.StopAutoSending() }()
+
+	for id := int64(1); id <= 5; id++ {
+		repo.addDue(pendingMessage(id))
+	}
+	service.Wake()
+
+	assert.Eventually(t, func() bool {
+		return provider.sentCount() == 5
+	}, time.Second, 10*time.Millisecond, "a wake-up must drain more than one batch without waiting for the tick")
+}
+
+func TestMessageService_RejectsContentAboveProviderLimit(t *testing.T) {
+	repo := newFakeRepo(pendingMessage(1))
+	provider := &fakeProvider{
+		name:         "primary",
+		capabilities: app.Capabilities{MaxContentLength: 3},
+		send: func(req app.SendRequest) (*app.SendResult, error) {
+			return &app.SendResult{}, nil
+		},
+	}
+
+	runOnce(t, newTestService(t, repo, provider))
+
+	assert.Zero(t, provider.sentCount())
+	msg, ok := repo.updatedMessage(1)
+	require.True(t, ok)
+	assert.Equal(t, domain.MessageStatusFailed, msg.Status)
+}
+
+func TestMessageService_FailsOverOnTransientError(t *testing.T) {
+	repo := newFakeRepo(pendingMessage(1))
+	primary := &fakeProvider{
+		name: "primary",
+		send: func(req app.SendRequest) (*app.SendResult, error) {
+			return nil, fmt.Errorf("%w: 503", app.ErrTransient)
+		},
+	}
+	secondary := &fakeProvider{
+		name: "secondary",
+		send: func(req app.SendRequest) (*app.SendResult, error) {
+			return &app.SendResult{ProviderMessageID: "ext-2", Attempts: 1}, nil
+		},
+	}
+
+	service := newTestService(t, repo, primary, secondary)
+	runOnce(t, service)
+
+	msg, ok := repo.updatedMessage(1)
+	require.True(t, ok)
+	assert.Equal(t, domain.MessageStatusSent, msg.Status)
+	assert.Equal(t, "secondary", msg.Provider.String)
+	require.Len(t, msg.FailedAttempts, 1)
+	assert.Equal(t, "primary", msg.FailedAttempts[0].Provider)
+	assert.Less(t, service.ProviderHealth()["primary"], 1.0)
+}
+
+func TestMessageService_FailsOverWhenPrimaryUnavailable(t *testing.T) {
+	repo := newFakeRepo(pendingMessage(1))
+	primary := &fakeProvider{
+		name: "primary",
+		send: func(req app.SendRequest) (*app.SendResult, error) {
+			return nil, app.ErrProviderUnavailable
+		},
+	}
+	secondary := &fakeProvider{
+		name: "secondary",
+		send: func(req app.SendRequest) (*app.SendResult, error) {
+			return &app.SendResult{ProviderMessageID: "ext-2"}, nil
+		},
+	}
+
+	service := newTestService(t, repo, primary, secondary)
+	runOnce(t, service)
+
+	msg, ok := repo.updatedMessage(1)
+	require.True(t, ok)
+	assert.Equal(t, domain.MessageStatusSent, msg.Status)
+	assert.Equal(t, "secondary", msg.Provider.String)
+	assert.Empty(t, msg.FailedAttempts)
+	assert.NotContains(t, service.ProviderHealth(), "primary", "an open breaker must not lower the provider's health")
+}
+
+func TestMessageService_DoesNotFailOverOnPermanentError(t *testing.T) {
+	repo := newFakeRepo(pendingMessage(1))
+	primary := &fakeProvider{
+		name: "primary",
+		send: func(req app.SendRequest) (*app.SendResult, error) {
+			return nil, errors.New("400 bad request")
+		},
+	}
+	secondary := &fakeProvider{
+		name: "secondary",
+		send: func(req app.SendRequest) (*app.SendResult, error) {
+			return &app.SendResult{}, nil
+		},
+	}
+
+	service := newTestService(t, repo, primary, secondary)
+	runOnce(t, service)
+
+	assert.Zero(t, secondary.sentCount())
+	msg, ok := repo.updatedMessage(1)
+	require.True(t, ok)
+	assert.Equal(t, domain.MessageStatusFailed, msg.Status)
+	require.Len(t, msg.FailedAttempts, 1)
+	assert.NotContains(t, service.ProviderHealth(), "primary", "a permanent error must not lower the provider's health")
+}
+
+func TestMessageService_RecordsEveryFailedProvider(t *testing.T) {
+	repo := newFakeRepo(pendingMessage(1))
+	transient := func(req app.SendRequest) (*app.SendResult, error) {
+		return nil, fmt.Errorf("%w: timeout", app.ErrTransient)
+	}
+
+	runOnce(t, newTestService(t, repo,
+		&fakeProvider{name: "primary", send: transient},
+		&fakeProvider{name: "secondary", send: transient},
+	))
+
+	msg, ok := repo.updatedMessage(1)
+	require.True(t, ok)
+	assert.Equal(t, domain.MessageStatusFailed, msg.Status)
+	require.Len(t, msg.FailedAttempts, 2)
+	assert.Equal(t, "primary", msg.FailedAttempts[0].Provider)
+	assert.Equal(t, "secondary", msg.FailedAttempts[1].Provider)
+}
+
+func emailMessage(id int64) domain.Message {
+	return domain.Message{
+		ID:        id,
+		Channel:   domain.MessageChannelEmail,
+		Recipient: "user@example.com",
+		Subject:   sql.NullString{String: "Welcome", Valid: true},
+		Content:   "hello",
+		Status:    domain.MessageStatusPending,
+	}
+}
+
+func TestMessageService_PicksProviderByChannel(t *testing.T) {
+	repo := newFakeRepo(pendingMessage(1), emailMessage(2))
+	sendOK := func(req app.SendRequest) (*app.SendResult, error) {
+		return &app.SendResult{ProviderMessageID: "ext", Attempts: 1}, nil
+	}
+	sms := &fakeProvider{name: "sms", send: sendOK}
+	email := &fakeProvider{
+		name:         "email",
+		capabilities: app.Capabilities{Channel: domain.MessageChannelEmail},
+		send:         sendOK,
+	}
+
+	runOnce(t, newTestService(t, repo, sms, email))
+
+	require.Equal(t, 1, sms.sentCount())
+	assert.Equal(t, int64(1), sms.sent[0].MessageID)
+	require.Equal(t, 1, email.sentCount())
+	assert.Equal(t, domain.MessageChannelEmail, email.sent[0].Channel)
+	assert.Equal(t, "Welcome", email.sent[0].Subject)
+
+	msg, ok := repo.updatedMessage(2)
+	require.True(t, ok)
+	assert.Equal(t, domain.MessageStatusSent, msg.Status)
+	assert.Equal(t, "email", msg.Provider.String)
+}
+
+func TestMessageService_FailsMessageWithoutChannelProvider(t *testing.T) {
+	repo := newFakeRepo(emailMessage(1))
+	sms := &fakeProvider{name: "sms", send: func(req app.SendRequest) (*app.SendResult, error) {
+		return &app.SendResult{}, nil
+	}}
+
+	runOnce(t, newTestService(t, repo, sms))
+
+	assert.Zero(t, sms.sentCount())
+	msg, ok := repo.updatedMessage(1)
+	require.True(t, ok)
+	assert.Equal(t, domain.MessageStatusFailed, msg.Status)
+	assert.Contains(t, msg.ErrorMessage.String, app.ErrNoProviderForChannel.Error())
+}
+
+func TestMessageService_ValidatesMessagePerChannel(t *testing.T) {
+	noSubject := emailMessage(1)
+	noSubject.Subject = sql.NullString{}
+	badAddress := emailMessage(2)
+	badAddress.Recipient = "+905551112233"
+	badPhone := pendingMessage(3)
+	badPhone.Recipient = "user@example.com"
+
+	repo := newFakeRepo(noSubject, badAddress, badPhone)
+	sendOK := func(req app.SendRequest) (*app.SendResult, error) {
+		return &app.SendResult{}, nil
+	}
+	sms := &fakeProvider{name: "sms", send: sendOK}
+	email := &fakeProvider{name: "email", capabilities: app.Capabilities{Channel: domain.MessageChannelEmail}, send: sendOK}
+
+	runOnce(t, newTestService(t, repo, sms, email))
+
+	assert.Zero(t, sms.sentCount())
+	assert.Zero(t, email.sentCount())
+	for id, want := range map[int64]error{1: domain.ErrMissingSubject, 2: domain.ErrInvalidRecipient, 3: domain.ErrInvalidRecipient} {
+		msg, ok := repo.updatedMessage(id)
+		require.True(t, ok)
+		assert.Equal(t, domain.MessageStatusFailed, msg.Status)
+		assert.Contains(t, msg.ErrorMessage.String, want.Error())
+	}
+}
+
+func TestMessageService_RecordsDeliveryAttempts(t *testing.T) {
+	repo := newFakeRepo(pendingMessage(1))
+	attempts := &fakeAttemptRepo{}
+	longBody := strings.Repeat("ü", domain.MaxAttemptResponseBody)
+
+	primary := &fakeProvider{
+		name: "primary",
+		send: func(req app.SendRequest) (*app.SendResult, error) {
+			return nil, &app.SendError{
+				StatusCode:   503,
+				ResponseBody: longBody,
+				Attempts:     3,
+				Err:          fmt.Errorf("%w: service unavailable", app.ErrTransient),
+			}
+		},
+	}
+	secondary := &fakeProvider{
+		name: "secondary",
+		send: func(req app.SendRequest) (*app.SendResult, error) {
+			return &app.SendResult{ProviderMessageID: "ext-1", Attempts: 1, StatusCode: 202, ResponseBody: `{"ok":true}`}, nil
+		},
+	}
+
+	registry, err := app.NewProviderRegistry(primary, secondary)
+	require.NoError(t, err)
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	runOnce(t, app.NewMessageService(repo, attempts, registry, nil, newTestOutbox(), &fakeCache{}, logger))
+
+	recorded, err := attempts.ListByMessage(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, recorded, 2)
+
+	failed := recorded[0]
+	assert.Equal(t, "primary", failed.Provider)
+	assert.Equal(t, int64(503), failed.StatusCode.Int64)
+	assert.Equal(t, domain.AttemptErrorTransient, failed.ErrorClass)
+	assert.Equal(t, int64(3), failed.RetryAttempt.Int64)
+	assert.LessOrEqual(t, len(failed.ResponseBody.String), domain.MaxAttemptResponseBody)
+	assert.True(t, utf8.ValidString(failed.ResponseBody.String))
+
+	succeeded := recorded[1]
+	assert.Equal(t, "secondary", succeeded.Provider)
+	assert.Equal(t, int64(202), succeeded.StatusCode.Int64)
+	assert.Equal(t, domain.AttemptErrorNone, succeeded.ErrorClass)
+	assert.False(t, succeeded.Error.Valid)
+	assert.Equal(t, `{"ok":true}`, succeeded.ResponseBody.String)
+
+	msg, ok := repo.updatedMessage(1)
+	require.True(t, ok)
+	assert.Equal(t, int64(202), msg.ResponseCode.Int64)
+}
+
+func TestMessageService_ResponseCodeOnPermanentFailure(t *testing.T) {
+	repo := newFakeRepo(pendingMessage(1))
+	provider := &fakeProvider{
+		name: "primary",
+		send: func(req app.SendRequest) (*app.SendResult, error) {
+			return nil, &app.SendError{StatusCode: 400, ResponseBody: "bad number", Err: errors.New("rejected")}
+		},
+	}
+
+	runOnce(t, newTestService(t, repo, provider))
+
+	msg, ok := repo.updatedMessage(1)
+	require.True(t, ok)
+	assert.Equal(t, domain.MessageStatusFailed, msg.Status)
+	assert.Equal(t, int64(400), msg.ResponseCode.Int64)
+}
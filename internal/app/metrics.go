@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+	"github.com/muratdemir0/gopulse-messages/internal/telemetry"
+)
+
+const (
+	meterName      = "github.com/muratdemir0/gopulse-messages/internal/app"
+	backlogTimeout = 5 * time.Second
+)
+
+// Message status labels of the messages counter.
+const (
+	metricStatusCreated = "created"
+	metricStatusSent    = "sent"
+	metricStatusFailed  = "failed"
+)
+
+type serviceMetrics struct {
+	messages     metric.Int64Counter
+	sendDuration metric.Float64Histogram
+}
+
+func newServiceMetrics() *serviceMetrics {
+	meter := otel.Meter(meterName)
+	m := &serviceMetrics{}
+
+	var err error
+	m.messages, err = meter.Int64Counter(
+		"gopulse.messages",
+		metric.WithDescription("Messages created, sent and failed by status and provider"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	m.sendDuration, err = meter.Float64Histogram(
+		"gopulse.provider.send.duration",
+		metric.WithDescription("Latency of provider dispatches such as webhook calls"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(telemetry.DurationBuckets...),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return m
+}
+
+func (m *serviceMetrics) message(ctx context.Context, status, provider string) {
+	m.messages.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("status", status),
+		attribute.String("provider", provider),
+	))
+}
+
+func (m *serviceMetrics) send(ctx context.Context, provider string, latency time.Duration, class domain.AttemptErrorClass) {
+	outcome := string(class)
+	if class == domain.AttemptErrorNone {
+		outcome = "success"
+	}
+
+	m.sendDuration.Record(ctx, latency.Seconds(), metric.WithAttributes(
+		attribute.String("provider", provider),
+		attribute.String("outcome", outcome),
+	))
+}
+
+// registerBacklogMetrics exposes the number of pending messages and the age of
+// the oldest one, read from the repository on every collection.
+func registerBacklogMetrics(repo domain.MessageRepository) {
+	meter := otel.Meter(meterName)
+
+	pending, err := meter.Int64ObservableGauge(
+		"gopulse.messages.pending",
+		metric.WithDescription("Messages waiting to be sent"),
+	)
+	if err != nil {
+		otel.Handle(err)
+		return
+	}
+
+	oldestAge, err := meter.Float64ObservableGauge(
+		"gopulse.messages.oldest_pending_age",
+		metric.WithDescription("Age of the oldest message waiting to be sent"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		otel.Handle(err)
+		return
+	}
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		ctx, cancel := context.WithTimeout(ctx, backlogTimeout)
+		defer cancel()
+
+		backlog, err := repo.Backlog(ctx)
+		if err != nil {
+			return err
+		}
+
+		o.ObserveInt64(pending, backlog.Pending)
+		var age float64
+		if backlog.OldestCreatedAt.Valid {
+			age = time.Since(backlog.OldestCreatedAt.Time).Seconds()
+		}
+		o.ObserveFloat64(oldestAge, age)
+		return nil
+	}, pending, oldestAge)
+	if err != nil {
+		otel.Handle(err)
+	}
+}
+
+type schedulerMetrics struct {
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+	attrs    metric.MeasurementOption
+}
+
+func newSchedulerMetrics(name string) *schedulerMetrics {
+	meter := otel.Meter(meterName)
+	m := &schedulerMetrics{attrs: metric.WithAttributes(attribute.String("scheduler", name))}
+
+	var err error
+	m.duration, err = meter.Float64Histogram(
+		"gopulse.scheduler.tick.duration",
+		metric.WithDescription("Duration of scheduler task runs"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(telemetry.DurationBuckets...),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	m.errors, err = meter.Int64Counter(
+		"gopulse.scheduler.tick.errors",
+		metric.WithDescription("Scheduler task runs that returned an error"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return m
+}
+
+func (m *schedulerMetrics) tick(ctx context.Context, duration time.Duration, err error) {
+	m.duration.Record(ctx, duration.Seconds(), m.attrs)
+	if err != nil {
+		m.errors.Add(ctx, 1, m.attrs)
+	}
+}
@@ -0,0 +1,266 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+)
+
+const (
+	DefaultOutboxInterval    = time.Second
+	DefaultOutboxBatchSize   = 100
+	DefaultOutboxMaxAttempts = 10
+	DefaultOutboxRetention   = 24 * time.Hour
+
+	// outboxCleanupInterval is how often the relay deletes expired events.
+	outboxCleanupInterval = time.Minute
+)
+
+// Transactor scopes repository calls made with the returned context to a
+// database transaction. db.Client implements it.
+type Transactor interface {
+	BeginTx(ctx context.Context) (context.Context, error)
+	CommitTx(ctx context.Context) error
+	RollbackTx(ctx context.Context) error
+}
+
+// EventSink publishes outbox events to downstream consumers.
+type EventSink interface {
+	Publish(ctx context.Context, event domain.OutboxEvent) error
+}
+
+// Outbox stores state changes together with the events describing them.
+type Outbox struct {
+	tx        Transactor
+	repo      domain.OutboxRepository
+	listeners []EventSink
+}
+
+// NewOutbox creates an outbox. Listeners are handed every event right after
+// its transaction commits; they are a best-effort fast path next to the relay
+// and their errors do not fail the save.
+func NewOutbox(tx Transactor, repo domain.OutboxRepository, listeners ...EventSink) *Outbox {
+	return &Outbox{tx: tx, repo: repo, listeners: listeners}
+}
+
+// AddListener registers another listener. It must be called before the outbox
+// is used.
+func (o *Outbox) AddListener(listener EventSink) {
+	o.listeners = append(o.listeners, listener)
+}
+
+// Save runs change and stores the event it returns in one transaction, so the
+// event exists if and only if the change was committed.
+func (o *Outbox) Save(ctx context.Context, change func(ctx context.Context) (domain.OutboxEvent, error)) error {
+	var event domain.OutboxEvent
+	err := withinTx(ctx, o.tx, func(txCtx context.Context) error {
+		var err error
+		if event, err = change(txCtx); err != nil {
+			return err
+		}
+		return o.repo.Create(txCtx, &event)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, listener := range o.listeners {
+		_ = listener.Publish(ctx, event)
+	}
+	return nil
+}
+
+func withinTx(ctx context.Context, tx Transactor, fn func(ctx context.Context) error) error {
+	txCtx, err := tx.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(txCtx); err != nil {
+		_ = tx.RollbackTx(txCtx)
+		return err
+	}
+
+	return tx.CommitTx(txCtx)
+}
+
+func newMessageStatusEvent(message domain.Message, occurredAt time.Time) (domain.OutboxEvent, error) {
+	var eventType string
+	switch message.Status {
+	case domain.MessageStatusPending:
+		eventType = domain.EventMessageCreated
+	case domain.MessageStatusFailed:
+		eventType = domain.EventMessageFailed
+	default:
+		eventType = domain.EventMessageSent
+	}
+
+	payload, err := json.Marshal(domain.MessageStatusEvent{
+		MessageID:    message.ID,
+		Status:       message.Status,
+		Channel:      message.ChannelOrDefault(),
+		Recipient:    message.Recipient,
+		Tenant:       message.Tenant.String,
+		Provider:     message.Provider.String,
+		ResponseID:   message.ResponseID.String,
+		ResponseCode: message.ResponseCode.Int64,
+		Error:        message.ErrorMessage.String,
+		OccurredAt:   occurredAt,
+	})
+	if err != nil {
+		return domain.OutboxEvent{}, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	return domain.OutboxEvent{
+		MessageID: message.ID,
+		EventType: eventType,
+		Payload:   payload,
+		CreatedAt: occurredAt,
+	}, nil
+}
+
+// OutboxRelay publishes stored events to a sink. Events are published at least
+// once and in order per message: when an event fails, later events of the same
+// message wait for the next run. An event that fails maxAttempts times is
+// dead-lettered so it cannot hold back the rest of the outbox. Published events
+// are deleted once older than the retention, which bounds how far back the
+// message stream can replay. A database advisory lock keeps a single relay
+// active across replicas.
+type OutboxRelay struct {
+	tx          Transactor
+	repo        domain.OutboxRepository
+	sink        EventSink
+	batchSize   uint
+	maxAttempts int
+	retention   time.Duration
+	lastCleanup time.Time
+	scheduler   *Scheduler
+	logger      *slog.Logger
+}
+
+func NewOutboxRelay(tx Transactor, repo domain.OutboxRepository, sink EventSink, interval time.Duration, batchSize uint, logger *slog.Logger) *OutboxRelay {
+	if interval <= 0 {
+		interval = DefaultOutboxInterval
+	}
+	if batchSize == 0 {
+		batchSize = DefaultOutboxBatchSize
+	}
+
+	relay := &OutboxRelay{
+		tx:          tx,
+		repo:        repo,
+		sink:        sink,
+		batchSize:   batchSize,
+		maxAttempts: DefaultOutboxMaxAttempts,
+		retention:   DefaultOutboxRetention,
+		logger:      logger.With(slog.String("component", "outbox_relay")),
+	}
+	relay.scheduler = NewScheduler("outbox_relay", interval, relay.RelayOnce, logger)
+
+	return relay
+}
+
+// SetMaxAttempts sets how many times an event is published before it is
+// dead-lettered. It must be called before Start.
+func (r *OutboxRelay) SetMaxAttempts(maxAttempts int) {
+	if maxAttempts > 0 {
+		r.maxAttempts = maxAttempts
+	}
+}
+
+// SetRetention sets how long published events are kept. Zero keeps them
+// forever. It must be called before Start.
+func (r *OutboxRelay) SetRetention(retention time.Duration) {
+	r.retention = max(retention, 0)
+}
+
+func (r *OutboxRelay) Start() {
+	r.scheduler.Start()
+	r.logger.Info("Outbox relay started")
+}
+
+func (r *OutboxRelay) Stop() {
+	r.scheduler.Stop()
+	r.logger.Info("Outbox relay stopped")
+}
+
+// RelayOnce publishes one batch of pending events and, on the relay holding
+// the lock, deletes expired published events.
+func (r *OutboxRelay) RelayOnce(ctx context.Context) error {
+	var locked bool
+	err := withinTx(ctx, r.tx, func(txCtx context.Context) error {
+		var err error
+		if locked, err = r.repo.TryLock(txCtx); err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		events, err := r.repo.ListUnpublished(txCtx, r.batchSize)
+		if err != nil {
+			return err
+		}
+
+		blocked := make(map[int64]bool)
+		for _, event := range events {
+			if blocked[event.MessageID] {
+				continue
+			}
+
+			if err := r.sink.Publish(ctx, event); err != nil {
+				blocked[event.MessageID] = true
+				if err := r.markFailed(ctx, txCtx, event, err); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := r.repo.MarkPublished(txCtx, event.ID, time.Now()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil || !locked {
+		return err
+	}
+
+	// Cleanup runs after the commit so that a failed delete cannot roll back
+	// the events just marked published.
+	return r.cleanup(ctx)
+}
+
+func (r *OutboxRelay) markFailed(ctx, txCtx context.Context, event domain.OutboxEvent, publishErr error) error {
+	if event.Attempts+1 < r.maxAttempts {
+		r.logger.WarnContext(ctx, "Error publishing outbox event", "event_id", event.ID, "message_id", event.MessageID, "attempt", event.Attempts+1, "error", publishErr)
+		return r.repo.MarkFailed(txCtx, event.ID, publishErr.Error())
+	}
+
+	r.logger.ErrorContext(ctx, "Outbox event dead-lettered", "event_id", event.ID, "message_id", event.MessageID, "attempts", event.Attempts+1, "error", publishErr)
+	return r.repo.MarkDeadLettered(txCtx, event.ID, publishErr.Error(), time.Now())
+}
+
+// cleanup deletes events published before the retention window, at most once
+// per outboxCleanupInterval.
+func (r *OutboxRelay) cleanup(ctx context.Context) error {
+	now := time.Now()
+	if r.retention == 0 || now.Sub(r.lastCleanup) < outboxCleanupInterval {
+		return nil
+	}
+
+	deleted, err := r.repo.DeletePublished(ctx, now.Add(-r.retention))
+	if err != nil {
+		return err
+	}
+	r.lastCleanup = now
+	if deleted > 0 {
+		r.logger.InfoContext(ctx, "Deleted published outbox events", "count", deleted, "retention", r.retention.String())
+	}
+	return nil
+}
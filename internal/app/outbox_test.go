@@ -0,0 +1,308 @@
+//go:build unit
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTransactor struct {
+	mu        sync.Mutex
+	commits   int
+	rollbacks int
+}
+
+func (t *fakeTransactor) BeginTx(ctx context.Context) (context.Context, error) { return ctx, nil }
+
+func (t *fakeTransactor) CommitTx(context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.commits++
+	return nil
+}
+
+func (t *fakeTransactor) RollbackTx(context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollbacks++
+	return nil
+}
+
+type fakeOutboxRepo struct {
+	mu           sync.Mutex
+	events       []domain.OutboxEvent
+	locked       bool
+	failures     map[int64]string
+	published    map[int64]bool
+	deadLettered map[int64]bool
+	deletedFrom  []time.Time
+}
+
+func (r *fakeOutboxRepo) Create(_ context.Context, event *domain.OutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event.ID = int64(len(r.events) + 1)
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *fakeOutboxRepo) TryLock(context.Context) (bool, error) {
+	return !r.locked, nil
+}
+
+func (r *fakeOutboxRepo) ListUnpublished(_ context.Context, limit uint) ([]domain.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []domain.OutboxEvent
+	for _, event := range r.events {
+		if !r.published[event.ID] && !r.deadLettered[event.ID] && uint(len(events)) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *fakeOutboxRepo) ListAfter(_ context.Context, afterID int64, limit uint) ([]domain.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []domain.OutboxEvent
+	for _, event := range r.events {
+		if event.ID > afterID && uint(len(events)) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *fakeOutboxRepo) MarkPublished(_ context.Context, id int64, _ time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.published == nil {
+		r.published = make(map[int64]bool)
+	}
+	r.published[id] = true
+	return nil
+}
+
+func (r *fakeOutboxRepo) MarkFailed(_ context.Context, id int64, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures == nil {
+		r.failures = make(map[int64]string)
+	}
+	r.failures[id] = reason
+	r.events[id-1].Attempts++
+	return nil
+}
+
+func (r *fakeOutboxRepo) MarkDeadLettered(ctx context.Context, id int64, reason string, _ time.Time) error {
+	if err := r.MarkFailed(ctx, id, reason); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.deadLettered == nil {
+		r.deadLettered = make(map[int64]bool)
+	}
+	r.deadLettered[id] = true
+	return nil
+}
+
+func (r *fakeOutboxRepo) DeletePublished(_ context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deletedFrom = append(r.deletedFrom, before)
+	return 0, nil
+}
+
+type fakeSink struct {
+	mu        sync.Mutex
+	published []int64
+	fail      map[int64]bool
+}
+
+func (s *fakeSink) Publish(_ context.Context, event domain.OutboxEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail[event.ID] {
+		return errors.New("sink unavailable")
+	}
+	s.published = append(s.published, event.ID)
+	return nil
+}
+
+func newTestOutbox() *app.Outbox {
+	return app.NewOutbox(&fakeTransactor{}, &fakeOutboxRepo{})
+}
+
+func TestMessageService_WritesStatusEvent(t *testing.T) {
+	repo := newFakeRepo(pendingMessage(1), pendingMessage(2))
+	provider := &fakeProvider{
+		name: "primary",
+		send: func(req app.SendRequest) (*app.SendResult, error) {
+			if req.MessageID == 2 {
+				return nil, errors.New("rejected")
+			}
+			return &app.SendResult{ProviderMessageID: "ext-1", Attempts: 1, StatusCode: 202}, nil
+		},
+	}
+	registry, err := app.NewProviderRegistry(provider)
+	require.NoError(t, err)
+
+	tx := &fakeTransactor{}
+	outboxRepo := &fakeOutboxRepo{}
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	runOnce(t, app.NewMessageService(repo, &fakeAttemptRepo{}, registry, nil, app.NewOutbox(tx, outboxRepo), &fakeCache{}, logger))
+
+	assert.Equal(t, 2, tx.commits)
+	require.Len(t, outboxRepo.events, 2)
+
+	types := map[int64]string{}
+	for _, event := range outboxRepo.events {
+		types[event.MessageID] = event.EventType
+
+		var payload domain.MessageStatusEvent
+		require.NoError(t, json.Unmarshal(event.Payload, &payload))
+		assert.Equal(t, event.MessageID, payload.MessageID)
+	}
+	assert.Equal(t, domain.EventMessageSent, types[1])
+	assert.Equal(t, domain.EventMessageFailed, types[2])
+}
+
+func TestOutbox_Save(t *testing.T) {
+	t.Run("Given a failing update, the event is not stored", func(t *testing.T) {
+		tx := &fakeTransactor{}
+		repo := &fakeOutboxRepo{}
+		outbox := app.NewOutbox(tx, repo)
+
+		err := outbox.Save(context.Background(), func(context.Context) (domain.OutboxEvent, error) {
+			return domain.OutboxEvent{}, errors.New("update failed")
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, repo.events)
+		assert.Equal(t, 1, tx.rollbacks)
+		assert.Zero(t, tx.commits)
+	})
+}
+
+func TestOutbox_NotifiesListenersAfterCommit(t *testing.T) {
+	repo := newFakeRepo()
+	registry, err := app.NewProviderRegistry(&fakeProvider{name: "primary"})
+	require.NoError(t, err)
+
+	listener := &fakeSink{}
+	outboxRepo := &fakeOutboxRepo{}
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	service := app.NewMessageService(repo, &fakeAttemptRepo{}, registry, nil, app.NewOutbox(&fakeTransactor{}, outboxRepo, listener), &fakeCache{}, logger)
+
+	message := &domain.Message{Recipient: "+905551112233", Content: "hello", Tenant: sql.NullString{String: "acme", Valid: true}}
+	require.NoError(t, service.CreateMessage(context.Background(), message))
+
+	assert.Equal(t, int64(1), message.ID)
+	assert.Equal(t, domain.MessageStatusPending, message.Status)
+	require.Len(t, outboxRepo.events, 1)
+	assert.Equal(t, domain.EventMessageCreated, outboxRepo.events[0].EventType)
+	assert.Equal(t, []int64{outboxRepo.events[0].ID}, listener.published)
+
+	var payload domain.MessageStatusEvent
+	require.NoError(t, json.Unmarshal(outboxRepo.events[0].Payload, &payload))
+	assert.Equal(t, "acme", payload.Tenant)
+}
+
+func TestOutboxRelay_RelayOnce(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	newRepo := func() *fakeOutboxRepo {
+		repo := &fakeOutboxRepo{}
+		for _, messageID := range []int64{1, 2, 1, 2} {
+			require.NoError(t, repo.Create(context.Background(), &domain.OutboxEvent{MessageID: messageID, EventType: domain.EventMessageSent}))
+		}
+		return repo
+	}
+
+	t.Run("Given pending events, they are published in order", func(t *testing.T) {
+		repo := newRepo()
+		sink := &fakeSink{}
+		relay := app.NewOutboxRelay(&fakeTransactor{}, repo, sink, time.Second, 10, logger)
+
+		require.NoError(t, relay.RelayOnce(context.Background()))
+		assert.Equal(t, []int64{1, 2, 3, 4}, sink.published)
+
+		require.NoError(t, relay.RelayOnce(context.Background()))
+		assert.Len(t, sink.published, 4, "published events are not sent again")
+	})
+
+	t.Run("Given a failed event, later events of the same message wait", func(t *testing.T) {
+		repo := newRepo()
+		sink := &fakeSink{fail: map[int64]bool{1: true}}
+		relay := app.NewOutboxRelay(&fakeTransactor{}, repo, sink, time.Second, 10, logger)
+
+		require.NoError(t, relay.RelayOnce(context.Background()))
+		assert.Equal(t, []int64{2, 4}, sink.published)
+		assert.Contains(t, repo.failures[1], "sink unavailable")
+
+		sink.fail = nil
+		require.NoError(t, relay.RelayOnce(context.Background()))
+		assert.Equal(t, []int64{2, 4, 1, 3}, sink.published)
+	})
+
+	t.Run("Given an event failing max attempts times, it is dead-lettered and stops blocking", func(t *testing.T) {
+		repo := newRepo()
+		sink := &fakeSink{fail: map[int64]bool{1: true}}
+		relay := app.NewOutboxRelay(&fakeTransactor{}, repo, sink, time.Second, 10, logger)
+		relay.SetMaxAttempts(2)
+
+		require.NoError(t, relay.RelayOnce(context.Background()))
+		assert.False(t, repo.deadLettered[1])
+		require.NoError(t, relay.RelayOnce(context.Background()))
+		assert.True(t, repo.deadLettered[1])
+
+		require.NoError(t, relay.RelayOnce(context.Background()))
+		assert.Equal(t, []int64{2, 4, 3}, sink.published, "later events of the message are published")
+	})
+
+	t.Run("Given a retention, published events older than it are deleted once per interval", func(t *testing.T) {
+		repo := newRepo()
+		relay := app.NewOutboxRelay(&fakeTransactor{}, repo, &fakeSink{}, time.Second, 10, logger)
+		relay.SetRetention(time.Hour)
+
+		require.NoError(t, relay.RelayOnce(context.Background()))
+		require.NoError(t, relay.RelayOnce(context.Background()))
+
+		require.Len(t, repo.deletedFrom, 1)
+		assert.WithinDuration(t, time.Now().Add(-time.Hour), repo.deletedFrom[0], time.Minute)
+	})
+
+	t.Run("Given a zero retention, nothing is deleted", func(t *testing.T) {
+		repo := newRepo()
+		relay := app.NewOutboxRelay(&fakeTransactor{}, repo, &fakeSink{}, time.Second, 10, logger)
+		relay.SetRetention(0)
+
+		require.NoError(t, relay.RelayOnce(context.Background()))
+		assert.Empty(t, repo.deletedFrom)
+	})
+
+	t.Run("Given another relay holds the lock, nothing is published", func(t *testing.T) {
+		repo := newRepo()
+		repo.locked = true
+		sink := &fakeSink{}
+		relay := app.NewOutboxRelay(&fakeTransactor{}, repo, sink, time.Second, 10, logger)
+
+		require.NoError(t, relay.RelayOnce(context.Background()))
+		assert.Empty(t, sink.published)
+		assert.Empty(t, repo.deletedFrom)
+	})
+}
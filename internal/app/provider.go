@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+)
+
+var (
+	// ErrProviderUnavailable marks a send that was not attempted because the
+	// provider is known to be down, e.g. its circuit breaker is open. It does
+	// not count as a delivery failure for the message.
+	ErrProviderUnavailable = errors.New("provider unavailable")
+
+	// ErrTransient marks a send failure that may succeed later or through
+	// another provider, e.g. a 5xx response or a network error.
+	ErrTransient = errors.New("transient provider error")
+
+	ErrNoProviders          = errors.New("no providers configured")
+	ErrDuplicateProvider    = errors.New("duplicate provider name")
+	ErrNoProviderForChannel = errors.New("no provider configured for channel")
+)
+
+// Capabilities describes what a provider supports.
+type Capabilities struct {
+	// MaxContentLength is the longest content the provider accepts. Zero means
+	// no limit.
+	MaxContentLength int
+	// Idempotent reports whether the provider deduplicates requests that share
+	// a SendRequest.IdempotencyKey.
+	Idempotent bool
+	// Channel is the channel the provider delivers on. Empty means SMS.
+	Channel domain.MessageChannel
+}
+
+// Supports reports whether the provider delivers on channel.
+func (c Capabilities) Supports(channel domain.MessageChannel) bool {
+	if c.Channel == "" {
+		return channel == domain.MessageChannelSMS
+	}
+	return c.Channel == channel
+}
+
+type SendRequest struct {
+	MessageID int64
+	Channel   domain.MessageChannel
+	To        string
+	// Content is the message text, or the plain text body for email.
+	Content        string
+	Subject        string
+	HTMLContent    string
+	IdempotencyKey string
+}
+
+type SendResult struct {
+	// ProviderMessageID is the identifier the provider assigned to the message.
+	ProviderMessageID string
+	// Attempts is the number of transport attempts it took to deliver.
+	Attempts int
+	// StatusCode is the provider's response code, e.g. the HTTP status.
+	StatusCode int
+	// ResponseBody is the raw provider response.
+	ResponseBody string
+}
+
+// SendError carries what the provider answered when a send failed. Providers
+// return it wrapping the actual error so callers can record the response.
+type SendError struct {
+	StatusCode   int
+	ResponseBody string
+	Attempts     int
+	Err          error
+}
+
+func (e *SendError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+// Provider delivers messages to an external vendor.
+type Provider interface {
+	Name() string
+	Capabilities() Capabilities
+	Send(ctx context.Context, req SendRequest) (*SendResult, error)
+}
+
+// ProviderRegistry holds the configured providers in priority order.
+type ProviderRegistry struct {
+	ordered []Provider
+	byName  map[string]Provider
+}
+
+func NewProviderRegistry(providers ...Provider) (*ProviderRegistry, error) {
+	if len(providers) == 0 {
+		return nil, ErrNoProviders
+	}
+
+	registry := &ProviderRegistry{
+		ordered: make([]Provider, 0, len(providers)),
+		byName:  make(map[string]Provider, len(providers)),
+	}
+
+	for _, provider := range providers {
+		name := provider.Name()
+		if _, ok := registry.byName[name]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateProvider, name)
+		}
+		registry.ordered = append(registry.ordered, provider)
+		registry.byName[name] = provider
+	}
+
+	return registry, nil
+}
+
+// Default returns the highest priority provider.
+func (r *ProviderRegistry) Default() Provider {
+	return r.ordered[0]
+}
+
+func (r *ProviderRegistry) Get(name string) (Provider, bool) {
+	provider, ok := r.byName[name]
+	return provider, ok
+}
+
+// All returns the providers in priority order.
+func (r *ProviderRegistry) All() []Provider {
+	providers := make([]Provider, len(r.ordered))
+	copy(providers, r.ordered)
+	return providers
+}
@@ -0,0 +1,41 @@
+//go:build unit
+
+package app_test
+
+import (
+	"testing"
+
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderRegistry(t *testing.T) {
+	t.Run("given providers, the first one is the default and order is kept", func(t *testing.T) {
+		primary := &fakeProvider{name: "primary"}
+		secondary := &fakeProvider{name: "secondary"}
+
+		registry, err := app.NewProviderRegistry(primary, secondary)
+		require.NoError(t, err)
+
+		assert.Equal(t, "primary", registry.Default().Name())
+		assert.Equal(t, []app.Provider{primary, secondary}, registry.All())
+
+		got, ok := registry.Get("secondary")
+		assert.True(t, ok)
+		assert.Equal(t, secondary, got)
+
+		_, ok = registry.Get("missing")
+		assert.False(t, ok)
+	})
+
+	t.Run("given no providers, it returns an error", func(t *testing.T) {
+		_, err := app.NewProviderRegistry()
+		assert.ErrorIs(t, err, app.ErrNoProviders)
+	})
+
+	t.Run("given duplicate names, it returns an error", func(t *testing.T) {
+		_, err := app.NewProviderRegistry(&fakeProvider{name: "a"}, &fakeProvider{name: "a"})
+		assert.ErrorIs(t, err, app.ErrDuplicateProvider)
+	})
+}
@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+)
+
+const (
+	DefaultQueueWorkers   = 4
+	DefaultQueueBatchSize = 10
+	queueErrorBackoff     = time.Second
+)
+
+// QueueJob is one message handed to a dispatch worker. ID identifies the
+// delivery in the queue and is used to acknowledge it.
+type QueueJob struct {
+	ID        string
+	MessageID int64
+}
+
+// WorkQueue hands message IDs to dispatch workers. Jobs that are received but
+// never acknowledged are handed out again later.
+type WorkQueue interface {
+	Enqueue(ctx context.Context, messageID int64) error
+	// Receive waits a bounded time for up to count jobs and returns an empty
+	// slice when none arrive.
+	Receive(ctx context.Context, count int) ([]QueueJob, error)
+	Ack(ctx context.Context, jobs ...QueueJob) error
+}
+
+// QueueDispatcher sends messages as soon as they are created instead of
+// waiting for the database poller. It listens on the outbox for created
+// events, pushes their message IDs to the queue and runs workers that consume
+// it. Postgres stays the source of truth: a lost job only delays the message
+// until the poller's reconciliation sweep.
+type QueueDispatcher struct {
+	queue     WorkQueue
+	service   *MessageService
+	workers   int
+	batchSize int
+	logger    *slog.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewQueueDispatcher(queue WorkQueue, service *MessageService, workers, batchSize int, logger *slog.Logger) *QueueDispatcher {
+	if workers <= 0 {
+		workers = DefaultQueueWorkers
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultQueueBatchSize
+	}
+
+	return &QueueDispatcher{
+		queue:     queue,
+		service:   service,
+		workers:   workers,
+		batchSize: batchSize,
+		logger:    logger.With(slog.String("component", "queue_dispatcher")),
+	}
+}
+
+// Publish enqueues the message of a created event. It implements EventSink so
+// the dispatcher can listen on the outbox.
+func (d *QueueDispatcher) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	if event.EventType != domain.EventMessageCreated {
+		return nil
+	}
+
+	if err := d.queue.Enqueue(ctx, event.MessageID); err != nil {
+		d.logger.WarnContext(ctx, "Error enqueuing message, leaving it to the poller", "message_id", event.MessageID, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (d *QueueDispatcher) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	for range d.workers {
+		d.wg.Add(1)
+		go d.work(ctx)
+	}
+	d.logger.InfoContext(ctx, "Queue dispatcher started", "workers", d.workers)
+}
+
+// Stop waits for the workers to finish the jobs they hold.
+func (d *QueueDispatcher) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cancel == nil {
+		return
+	}
+
+	d.cancel()
+	d.wg.Wait()
+	d.cancel = nil
+	d.logger.Info("Queue dispatcher stopped")
+}
+
+func (d *QueueDispatcher) work(ctx context.Context) {
+	defer d.wg.Done()
+
+	for ctx.Err() == nil {
+		if err := d.DispatchOnce(ctx); err != nil && ctx.Err() == nil {
+			d.logger.ErrorContext(ctx, "Error consuming work queue", "error", err)
+			select {
+			case <-time.After(queueErrorBackoff):
+			case <-ctx.Done():
+			}
+		}
+	}
+}
+
+// DispatchOnce receives one batch of jobs and sends their messages. Jobs are
+// acknowledged once the message was handled, including failed sends that the
+// poller retries. Jobs that hit unavailable providers stay unacknowledged so
+// the queue hands them out again.
+func (d *QueueDispatcher) DispatchOnce(ctx context.Context) error {
+	jobs, err := d.queue.Receive(ctx, d.batchSize)
+	if err != nil {
+		return err
+	}
+
+	var done []QueueJob
+	for _, job := range jobs {
+		// The message is sent even if ctx is cancelled meanwhile, so a stop
+		// does not leave it half processed.
+		err := d.service.ProcessMessageByID(context.WithoutCancel(ctx), job.MessageID)
+		if errors.Is(err, ErrProviderUnavailable) {
+			d.logger.WarnContext(ctx, "Provider unavailable, leaving job for redelivery", "message_id", job.MessageID)
+			continue
+		}
+		if err != nil {
+			d.logger.ErrorContext(ctx, "Error processing queued message", "message_id", job.MessageID, "error", err)
+			continue
+		}
+		done = append(done, job)
+	}
+
+	if len(done) == 0 {
+		return nil
+	}
+	return d.queue.Ack(context.WithoutCancel(ctx), done...)
+}
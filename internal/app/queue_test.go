@@ -0,0 +1,116 @@
+//go:build unit
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeQueue struct {
+	mu       sync.Mutex
+	enqueued []int64
+	pending  []app.QueueJob
+	acked    []string
+}
+
+func (q *fakeQueue) Enqueue(_ context.Context, messageID int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.enqueued = append(q.enqueued, messageID)
+	return nil
+}
+
+func (q *fakeQueue) Receive(_ context.Context, count int) ([]app.QueueJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := min(count, len(q.pending))
+	jobs := q.pending[:n]
+	q.pending = q.pending[n:]
+	return jobs, nil
+}
+
+func (q *fakeQueue) Ack(_ context.Context, jobs ...app.QueueJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, job := range jobs {
+		q.acked = append(q.acked, job.ID)
+	}
+	return nil
+}
+
+func newTestDispatcher(t *testing.T, repo *fakeRepo, queue *fakeQueue, provider *fakeProvider) *app.QueueDispatcher {
+	t.Helper()
+	registry, err := app.NewProviderRegistry(provider)
+	require.NoError(t, err)
+
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	service := app.NewMessageService(repo, &fakeAttemptRepo{}, registry, nil, newTestOutbox(), &fakeCache{}, logger)
+	return app.NewQueueDispatcher(queue, service, 1, 10, logger)
+}
+
+func TestQueueDispatcher_Publish(t *testing.T) {
+	queue := &fakeQueue{}
+	dispatcher := newTestDispatcher(t, newFakeRepo(), queue, &fakeProvider{name: "primary"})
+
+	ctx := context.Background()
+	require.NoError(t, dispatcher.Publish(ctx, domain.OutboxEvent{MessageID: 1, EventType: domain.EventMessageCreated}))
+	require.NoError(t, dispatcher.Publish(ctx, domain.OutboxEvent{MessageID: 1, EventType: domain.EventMessageSent}))
+
+	assert.Equal(t, []int64{1}, queue.enqueued, "only created events are enqueued")
+}
+
+func TestQueueDispatcher_DispatchOnce(t *testing.T) {
+	sendOK := func(req app.SendRequest) (*app.SendResult, error) {
+		return &app.SendResult{ProviderMessageID: "ext", Attempts: 1}, nil
+	}
+
+	t.Run("Given due and stale jobs, the due message is sent and both are acknowledged", func(t *testing.T) {
+		repo := newFakeRepo(pendingMessage(1))
+		queue := &fakeQueue{pending: []app.QueueJob{{ID: "1-0", MessageID: 1}, {ID: "2-0", MessageID: 99}}}
+		provider := &fakeProvider{name: "primary", send: sendOK}
+
+		require.NoError(t, newTestDispatcher(t, repo, queue, provider).DispatchOnce(context.Background()))
+
+		assert.Equal(t, 1, provider.sentCount())
+		assert.Equal(t, []string{"1-0", "2-0"}, queue.acked)
+		msg, ok := repo.updatedMessage(1)
+		require.True(t, ok)
+		assert.Equal(t, domain.MessageStatusSent, msg.Status)
+	})
+
+	t.Run("Given every provider is unavailable, the job is left for redelivery", func(t *testing.T) {
+		repo := newFakeRepo(pendingMessage(1))
+		queue := &fakeQueue{pending: []app.QueueJob{{ID: "1-0", MessageID: 1}}}
+		provider := &fakeProvider{name: "primary", send: func(app.SendRequest) (*app.SendResult, error) {
+			return nil, app.ErrProviderUnavailable
+		}}
+
+		require.NoError(t, newTestDispatcher(t, repo, queue, provider).DispatchOnce(context.Background()))
+
+		assert.Empty(t, queue.acked)
+		assert.Zero(t, repo.retries(1))
+		assert.Equal(t, []int64{1}, repo.released, "the message must be released for redelivery")
+	})
+
+	t.Run("Given a failed send, the retry is counted and the job acknowledged", func(t *testing.T) {
+		repo := newFakeRepo(pendingMessage(1))
+		queue := &fakeQueue{pending: []app.QueueJob{{ID: "1-0", MessageID: 1}}}
+		provider := &fakeProvider{name: "primary", send: func(app.SendRequest) (*app.SendResult, error) {
+			return nil, assert.AnError
+		}}
+
+		require.NoError(t, newTestDispatcher(t, repo, queue, provider).DispatchOnce(context.Background()))
+
+		assert.Equal(t, []string{"1-0"}, queue.acked)
+		assert.Equal(t, 1, repo.retries(1))
+	})
+}
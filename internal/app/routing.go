@@ -0,0 +1,357 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+)
+
+const DefaultRoutingCacheTTL = 30 * time.Second
+
+var ErrInvalidRoutingRule = errors.New("invalid routing rule")
+
+// ValidationError lists every problem found in a routing rule.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrInvalidRoutingRule, strings.Join(e.Problems, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidRoutingRule
+}
+
+// RouteDecision is the outcome of evaluating the routing rules for a message.
+// Rule is nil when no rule matched and the default provider order applies.
+type RouteDecision struct {
+	Rule     *domain.RoutingRule
+	Provider string
+}
+
+// Router picks a provider for each message based on the routing rules stored
+// in the database. Rules are cached for DefaultRoutingCacheTTL and reloaded
+// immediately after a change made through this Router.
+type Router struct {
+	repo      domain.RoutingRuleRepository
+	providers *ProviderRegistry
+	ttl       time.Duration
+	pick      func(n int) int
+	logger    *slog.Logger
+
+	mu       sync.Mutex
+	rules    []domain.RoutingRule
+	loadedAt time.Time
+}
+
+func NewRouter(repo domain.RoutingRuleRepository, providers *ProviderRegistry, logger *slog.Logger) *Router {
+	return &Router{
+		repo:      repo,
+		providers: providers,
+		ttl:       DefaultRoutingCacheTTL,
+		pick:      rand.IntN,
+		logger:    logger.With(slog.String("component", "router")),
+	}
+}
+
+// Route returns the decision for message. A failure to load the rules is
+// logged and treated as "no rule matched" so dispatch can continue.
+func (r *Router) Route(ctx context.Context, message domain.Message) RouteDecision {
+	rules, err := r.loadRules(ctx)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error loading routing rules, using default provider order", "error", err)
+		return RouteDecision{}
+	}
+
+	return r.evaluate(rules, message)
+}
+
+// DryRun reports which rule and provider message would be routed to.
+func (r *Router) DryRun(ctx context.Context, message domain.Message) (RouteDecision, error) {
+	rules, err := r.loadRules(ctx)
+	if err != nil {
+		return RouteDecision{}, err
+	}
+
+	return r.evaluate(rules, message), nil
+}
+
+func (r *Router) ListRules(ctx context.Context) ([]domain.RoutingRule, error) {
+	return r.repo.List(ctx)
+}
+
+func (r *Router) GetRule(ctx context.Context, id int64) (domain.RoutingRule, error) {
+	return r.repo.Get(ctx, id)
+}
+
+func (r *Router) CreateRule(ctx context.Context, rule *domain.RoutingRule) error {
+	normalizeRule(rule)
+	if err := r.Validate(*rule); err != nil {
+		return err
+	}
+
+	if err := r.repo.Create(ctx, rule); err != nil {
+		return err
+	}
+
+	r.invalidate()
+	return nil
+}
+
+func (r *Router) UpdateRule(ctx context.Context, rule domain.RoutingRule) error {
+	normalizeRule(&rule)
+	if err := r.Validate(rule); err != nil {
+		return err
+	}
+
+	if err := r.repo.Update(ctx, rule); err != nil {
+		return err
+	}
+
+	r.invalidate()
+	return nil
+}
+
+func (r *Router) DeleteRule(ctx context.Context, id int64) error {
+	if err := r.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	r.invalidate()
+	return nil
+}
+
+// Validate checks a rule against the configured providers and returns a
+// *ValidationError listing every problem.
+func (r *Router) Validate(rule domain.RoutingRule) error {
+	var problems []string
+
+	if strings.TrimSpace(rule.Name) == "" {
+		problems = append(problems, "name is required")
+	}
+
+	for _, prefix := range rule.Match.RecipientPrefixes {
+		if !isValidPrefix(prefix) {
+			problems = append(problems, fmt.Sprintf("recipient prefix %q must be digits with an optional leading +", prefix))
+		}
+	}
+
+	for _, country := range rule.Match.Countries {
+		if _, ok := countryCallingCodes[country]; !ok {
+			problems = append(problems, fmt.Sprintf("unknown country %q", country))
+		}
+	}
+
+	for _, priority := range rule.Match.Priorities {
+		if !priority.Valid() {
+			problems = append(problems, fmt.Sprintf("unknown priority %q", priority))
+		}
+	}
+
+	if len(rule.Targets) == 0 {
+		problems = append(problems, "at least one target is required")
+	}
+
+	seen := make(map[string]bool, len(rule.Targets))
+	for _, target := range rule.Targets {
+		if _, ok := r.providers.Get(target.Provider); !ok {
+			problems = append(problems, fmt.Sprintf("unknown provider %q", target.Provider))
+		}
+		if seen[target.Provider] {
+			problems = append(problems, fmt.Sprintf("provider %q is listed more than once", target.Provider))
+		}
+		seen[target.Provider] = true
+		if len(rule.Targets) > 1 && target.Weight <= 0 {
+			problems = append(problems, fmt.Sprintf("weight for provider %q must be positive", target.Provider))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func (r *Router) evaluate(rules []domain.RoutingRule, message domain.Message) RouteDecision {
+	for i := range rules {
+		rule := rules[i]
+		if !rule.Enabled || !matches(rule.Match, message) {
+			continue
+		}
+
+		provider := r.chooseTarget(rule.Targets, message.ChannelOrDefault())
+		if provider == "" {
+			continue
+		}
+		return RouteDecision{Rule: &rule, Provider: provider}
+	}
+
+	return RouteDecision{}
+}
+
+// chooseTarget picks a provider among the targets that are still configured
+// and deliver on channel, weighted by Target.Weight.
+func (r *Router) chooseTarget(targets domain.RouteTargets, channel domain.MessageChannel) string {
+	var available domain.RouteTargets
+	total := 0
+	for _, target := range targets {
+		provider, ok := r.providers.Get(target.Provider)
+		if !ok || !provider.Capabilities().Supports(channel) {
+			continue
+		}
+		weight := max(target.Weight, 1)
+		available = append(available, domain.RouteTarget{Provider: target.Provider, Weight: weight})
+		total += weight
+	}
+
+	if len(available) == 0 {
+		return ""
+	}
+	if len(available) == 1 {
+		return available[0].Provider
+	}
+
+	n := r.pick(total)
+	for _, target := range available {
+		if n < target.Weight {
+			return target.Provider
+		}
+		n -= target.Weight
+	}
+	return available[len(available)-1].Provider
+}
+
+func (r *Router) loadRules(ctx context.Context) ([]domain.RoutingRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rules != nil && time.Since(r.loadedAt) < r.ttl {
+		return r.rules, nil
+	}
+
+	rules, err := r.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Position < rules[j].Position
+	})
+
+	if rules == nil {
+		rules = []domain.RoutingRule{}
+	}
+	r.rules = rules
+	r.loadedAt = time.Now()
+	return rules, nil
+}
+
+func (r *Router) invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rules = nil
+}
+
+func matches(match domain.RouteMatch, message domain.Message) bool {
+	hasPhoneCriteria := len(match.RecipientPrefixes) > 0 || len(match.Countries) > 0
+	if hasPhoneCriteria && message.ChannelOrDefault() != domain.MessageChannelSMS {
+		return false
+	}
+
+	recipient := normalizePhone(message.Recipient)
+
+	if len(match.RecipientPrefixes) > 0 && !anyPrefix(recipient, match.RecipientPrefixes) {
+		return false
+	}
+
+	if len(match.Countries) > 0 {
+		var prefixes []string
+		for _, country := range match.Countries {
+			prefixes = append(prefixes, "+"+countryCallingCodes[country])
+		}
+		if !anyPrefix(recipient, prefixes) {
+			return false
+		}
+	}
+
+	if len(match.Priorities) > 0 {
+		priority := message.Priority
+		if priority == "" {
+			priority = domain.MessagePriorityNormal
+		}
+		if !slices.Contains(match.Priorities, priority) {
+			return false
+		}
+	}
+
+	if len(match.Tenants) > 0 && (!message.Tenant.Valid || !slices.Contains(match.Tenants, message.Tenant.String)) {
+		return false
+	}
+
+	if len(match.Tags) > 0 && !slices.ContainsFunc(message.Tags, func(tag string) bool {
+		return slices.Contains(match.Tags, tag)
+	}) {
+		return false
+	}
+
+	return true
+}
+
+func normalizeRule(rule *domain.RoutingRule) {
+	rule.Name = strings.TrimSpace(rule.Name)
+	for i, prefix := range rule.Match.RecipientPrefixes {
+		rule.Match.RecipientPrefixes[i] = normalizePhone(prefix)
+	}
+	for i, country := range rule.Match.Countries {
+		rule.Match.Countries[i] = strings.ToUpper(strings.TrimSpace(country))
+	}
+}
+
+// normalizePhone strips formatting characters and turns a leading 00 into +.
+func normalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r == '+' || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+
+	normalized := b.String()
+	if strings.HasPrefix(normalized, "00") {
+		normalized = "+" + normalized[2:]
+	}
+	return normalized
+}
+
+func isValidPrefix(prefix string) bool {
+	digits := strings.TrimPrefix(prefix, "+")
+	if digits == "" {
+		return false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func anyPrefix(value string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package app
+
+// countryCallingCodes maps ISO 3166-1 alpha-2 codes to ITU calling codes for
+// country based routing rules. Countries sharing a code (e.g. the NANP +1)
+// cannot be told apart by number alone.
+var countryCallingCodes = map[string]string{
+	"AE": "971",
+	"AR": "54",
+	"AT": "43",
+	"AU": "61",
+	"AZ": "994",
+	"BE": "32",
+	"BG": "359",
+	"BR": "55",
+	"CA": "1",
+	"CH": "41",
+	"CL": "56",
+	"CN": "86",
+	"CO": "57",
+	"CY": "357",
+	"CZ": "420",
+	"DE": "49",
+	"DK": "45",
+	"EG": "20",
+	"ES": "34",
+	"FI": "358",
+	"FR": "33",
+	"GB": "44",
+	"GE": "995",
+	"GR": "30",
+	"HK": "852",
+	"HR": "385",
+	"HU": "36",
+	"ID": "62",
+	"IE": "353",
+	"IL": "972",
+	"IN": "91",
+	"IQ": "964",
+	"IR": "98",
+	"IT": "39",
+	"JP": "81",
+	"KR": "82",
+	"KZ": "7",
+	"MX": "52",
+	"MY": "60",
+	"NG": "234",
+	"NL": "31",
+	"NO": "47",
+	"NZ": "64",
+	"PH": "63",
+	"PK": "92",
+	"PL": "48",
+	"PT": "351",
+	"QA": "974",
+	"RO": "40",
+	"RS": "381",
+	"RU": "7",
+	"SA": "966",
+	"SE": "46",
+	"SG": "65",
+	"TH": "66",
+	"TR": "90",
+	"UA": "380",
+	"US": "1",
+	"VN": "84",
+	"ZA": "27",
+}
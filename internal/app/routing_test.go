@@ -0,0 +1,285 @@
+//go:build unit
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRuleRepo struct {
+	mu     sync.Mutex
+	rules  []domain.RoutingRule
+	lists  int
+	nextID int64
+}
+
+func (r *fakeRuleRepo) List(context.Context) ([]domain.RoutingRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lists++
+	return append([]domain.RoutingRule(nil), r.rules...), nil
+}
+
+func (r *fakeRuleRepo) Get(_ context.Context, id int64) (domain.RoutingRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rule := range r.rules {
+		if rule.ID == id {
+			return rule, nil
+		}
+	}
+	return domain.RoutingRule{}, domain.ErrRoutingRuleNotFound
+}
+
+func (r *fakeRuleRepo) Create(_ context.Context, rule *domain.RoutingRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	rule.ID = r.nextID
+	r.rules = append(r.rules, *rule)
+	return nil
+}
+
+func (r *fakeRuleRepo) Update(_ context.Context, rule domain.RoutingRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.rules {
+		if r.rules[i].ID == rule.ID {
+			r.rules[i] = rule
+			return nil
+		}
+	}
+	return domain.ErrRoutingRuleNotFound
+}
+
+func (r *fakeRuleRepo) Delete(_ context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.rules {
+		if r.rules[i].ID == id {
+			r.rules = append(r.rules[:i], r.rules[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrRoutingRuleNotFound
+}
+
+func newTestRouter(t *testing.T, repo *fakeRuleRepo, names ...string) (*app.Router, *app.ProviderRegistry) {
+	t.Helper()
+	var providers []app.Provider
+	for _, name := range names {
+		providers = append(providers, &fakeProvider{name: name})
+	}
+	registry, err := app.NewProviderRegistry(providers...)
+	require.NoError(t, err)
+
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	return app.NewRouter(repo, registry, logger), registry
+}
+
+func rule(name string, position int, match domain.RouteMatch, targets ...domain.RouteTarget) domain.RoutingRule {
+	return domain.RoutingRule{
+		Name:     name,
+		Position: position,
+		Enabled:  true,
+		Match:    match,
+		Targets:  targets,
+	}
+}
+
+func target(provider string, weight int) domain.RouteTarget {
+	return domain.RouteTarget{Provider: provider, Weight: weight}
+}
+
+func TestRouter_Route(t *testing.T) {
+	tr := rule("turkey", 1, domain.RouteMatch{Countries: []string{"TR"}}, target("turkcell", 1))
+	urgent := rule("urgent", 0, domain.RouteMatch{Priorities: []domain.MessagePriority{domain.MessagePriorityHigh}}, target("premium", 1))
+	acme := rule("acme-otp", 2, domain.RouteMatch{Tenants: []string{"acme"}, Tags: []string{"otp", "login"}}, target("premium", 1))
+	disabled := rule("disabled", -1, domain.RouteMatch{}, target("premium", 1))
+	disabled.Enabled = false
+	prefix := rule("uk-mobile", 3, domain.RouteMatch{RecipientPrefixes: []string{"+447"}}, target("turkcell", 1))
+
+	repo := &fakeRuleRepo{rules: []domain.RoutingRule{acme, tr, urgent, disabled, prefix}}
+	router, _ := newTestRouter(t, repo, "default", "turkcell", "premium")
+
+	tests := []struct {
+		name     string
+		message  domain.Message
+		rule     string
+		provider string
+	}{
+		{
+			name:     "Given a Turkish number, it matches the country rule",
+			message:  domain.Message{Recipient: "+90 555 111 22 33"},
+			rule:     "turkey",
+			provider: "turkcell",
+		},
+		{
+			name:     "Given a 00 international prefix, it is normalized before matching",
+			message:  domain.Message{Recipient: "0090-555-111-2233"},
+			rule:     "turkey",
+			provider: "turkcell",
+		},
+		{
+			name:     "Given a high priority message, the lower position wins",
+			message:  domain.Message{Recipient: "+905551112233", Priority: domain.MessagePriorityHigh},
+			rule:     "urgent",
+			provider: "premium",
+		},
+		{
+			name:     "Given tenant and one of the tags, all fields must match",
+			message:  domain.Message{Recipient: "+15551112233", Tenant: sql.NullString{String: "acme", Valid: true}, Tags: domain.Tags{"login"}},
+			rule:     "acme-otp",
+			provider: "premium",
+		},
+		{
+			name:    "Given the tenant without a matching tag, no rule matches",
+			message: domain.Message{Recipient: "+15551112233", Tenant: sql.NullString{String: "acme", Valid: true}, Tags: domain.Tags{"marketing"}},
+		},
+		{
+			name:     "Given a recipient prefix, it matches formatted numbers",
+			message:  domain.Message{Recipient: "+44 (7700) 900123"},
+			rule:     "uk-mobile",
+			provider: "turkcell",
+		},
+		{
+			name:    "Given no matching rule, the decision is empty",
+			message: domain.Message{Recipient: "+4930123456"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := router.Route(context.Background(), tt.message)
+			if tt.rule == "" {
+				assert.Nil(t, decision.Rule)
+				assert.Empty(t, decision.Provider)
+				return
+			}
+			require.NotNil(t, decision.Rule)
+			assert.Equal(t, tt.rule, decision.Rule.Name)
+			assert.Equal(t, tt.provider, decision.Provider)
+		})
+	}
+}
+
+func TestRouter_WeightedTargets(t *testing.T) {
+	repo := &fakeRuleRepo{rules: []domain.RoutingRule{
+		rule("split", 0, domain.RouteMatch{}, target("a", 80), target("b", 20), target("gone", 1000)),
+	}}
+	router, _ := newTestRouter(t, repo, "a", "b")
+
+	counts := map[string]int{}
+	const draws = 10000
+	for range draws {
+		counts[router.Route(context.Background(), domain.Message{Recipient: "+1"}).Provider]++
+	}
+
+	assert.Zero(t, counts["gone"], "targets that are no longer configured are skipped")
+	assert.InDelta(t, 0.8, float64(counts["a"])/draws, 0.05)
+	assert.InDelta(t, 0.2, float64(counts["b"])/draws, 0.05)
+}
+
+func TestRouter_CachesRulesUntilChanged(t *testing.T) {
+	repo := &fakeRuleRepo{}
+	router, _ := newTestRouter(t, repo, "a")
+	ctx := context.Background()
+
+	router.Route(ctx, domain.Message{Recipient: "+1"})
+	router.Route(ctx, domain.Message{Recipient: "+1"})
+	assert.Equal(t, 1, repo.lists)
+
+	r := rule("all", 0, domain.RouteMatch{}, target("a", 1))
+	require.NoError(t, router.CreateRule(ctx, &r))
+
+	decision := router.Route(ctx, domain.Message{Recipient: "+1"})
+	require.NotNil(t, decision.Rule)
+	assert.Equal(t, "all", decision.Rule.Name)
+	assert.Equal(t, 2, repo.lists)
+}
+
+func TestRouter_Validate(t *testing.T) {
+	router, _ := newTestRouter(t, &fakeRuleRepo{}, "a", "b")
+
+	t.Run("Given a valid rule, it is accepted", func(t *testing.T) {
+		r := rule("ok", 0, domain.RouteMatch{
+			RecipientPrefixes: []string{"+90"},
+			Countries:         []string{"TR"},
+			Priorities:        []domain.MessagePriority{domain.MessagePriorityLow},
+		}, target("a", 1), target("b", 3))
+		assert.NoError(t, router.Validate(r))
+	})
+
+	t.Run("Given an invalid rule, every problem is reported", func(t *testing.T) {
+		r := rule(" ", 0, domain.RouteMatch{
+			RecipientPrefixes: []string{"+9x"},
+			Countries:         []string{"XX"},
+			Priorities:        []domain.MessagePriority{"urgent"},
+		}, target("a", 0), target("missing", 1), target("a", 1))
+
+		err := router.Validate(r)
+		require.ErrorIs(t, err, app.ErrInvalidRoutingRule)
+
+		var validationErr *app.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.ElementsMatch(t, []string{
+			"name is required",
+			`recipient prefix "+9x" must be digits with an optional leading +`,
+			`unknown country "XX"`,
+			`unknown priority "urgent"`,
+			`weight for provider "a" must be positive`,
+			`unknown provider "missing"`,
+			`provider "a" is listed more than once`,
+		}, validationErr.Problems)
+	})
+
+	t.Run("Given a rule without targets, it is rejected", func(t *testing.T) {
+		err := router.Validate(rule("empty", 0, domain.RouteMatch{}))
+		assert.ErrorIs(t, err, app.ErrInvalidRoutingRule)
+	})
+
+	t.Run("Given an invalid rule, CreateRule does not store it", func(t *testing.T) {
+		repo := &fakeRuleRepo{}
+		router, _ := newTestRouter(t, repo, "a")
+		r := rule("bad", 0, domain.RouteMatch{}, target("missing", 1))
+
+		assert.ErrorIs(t, router.CreateRule(context.Background(), &r), app.ErrInvalidRoutingRule)
+		assert.Empty(t, repo.rules)
+	})
+}
+
+func TestMessageService_RoutesByRule(t *testing.T) {
+	repo := newFakeRepo(pendingMessage(1))
+	sendOK := func(req app.SendRequest) (*app.SendResult, error) {
+		return &app.SendResult{ProviderMessageID: "ext", Attempts: 1}, nil
+	}
+	primary := &fakeProvider{name: "primary", send: sendOK}
+	turkcell := &fakeProvider{name: "turkcell", send: sendOK}
+
+	registry, err := app.NewProviderRegistry(primary, turkcell)
+	require.NoError(t, err)
+
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	router := app.NewRouter(&fakeRuleRepo{rules: []domain.RoutingRule{
+		rule("turkey", 0, domain.RouteMatch{Countries: []string{"TR"}}, target("turkcell", 1)),
+	}}, registry, logger)
+
+	runOnce(t, app.NewMessageService(repo, &fakeAttemptRepo{}, registry, router, newTestOutbox(), &fakeCache{}, logger))
+
+	assert.Equal(t, 0, primary.sentCount())
+	assert.Equal(t, 1, turkcell.sentCount())
+
+	msg, ok := repo.updatedMessage(1)
+	require.True(t, ok)
+	assert.Equal(t, "turkcell", msg.Provider.String)
+}
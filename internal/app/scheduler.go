@@ -4,30 +4,61 @@ import (
 	"context"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Scheduler struct {
-	interval time.Duration
+	name     string
+	interval atomic.Int64
 	task     func(ctx context.Context) error
 	mu       sync.RWMutex
 	running  bool
+	lastTick atomic.Int64
 	stopCh   chan struct{}
+	trigger  chan struct{}
+	reset    chan struct{}
 	ctx      context.Context
 	cancel   context.CancelFunc
+	metrics  *schedulerMetrics
 	logger   *slog.Logger
 }
 
-func NewScheduler(interval time.Duration, task func(ctx context.Context) error, logger *slog.Logger) *Scheduler {
+// NewScheduler runs task every interval. The name labels the scheduler's log
+// entries and tick metrics.
+func NewScheduler(name string, interval time.Duration, task func(ctx context.Context) error, logger *slog.Logger) *Scheduler {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &Scheduler{
-		interval: interval,
-		task:     task,
-		running:  false,
-		stopCh:   make(chan struct{}),
-		ctx:      ctx,
-		cancel:   cancel,
-		logger:   logger.With(slog.String("component", "scheduler")),
+	s := &Scheduler{
+		name:    name,
+		task:    task,
+		running: false,
+		stopCh:  make(chan struct{}),
+		trigger: make(chan struct{}, 1),
+		reset:   make(chan struct{}, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+		metrics: newSchedulerMetrics(name),
+		logger:  logger.With(slog.String("component", "scheduler"), slog.String("scheduler", name)),
+	}
+	s.interval.Store(int64(interval))
+	return s
+}
+
+func (s *Scheduler) Interval() time.Duration {
+	return time.Duration(s.interval.Load())
+}
+
+// SetInterval changes the time between ticks. A running scheduler waits the
+// new interval from now on; non-positive intervals are ignored.
+func (s *Scheduler) SetInterval(interval time.Duration) {
+	if interval <= 0 || s.interval.Swap(int64(interval)) == int64(interval) {
+		return
+	}
+	select {
+	case s.reset <- struct{}{}:
+	default:
 	}
 }
 
@@ -42,6 +73,7 @@ func (s *Scheduler) Start() {
 	s.ctx, s.cancel = context.WithCancel(context.Background())
 	s.stopCh = make(chan struct{})
 	s.running = true
+	s.lastTick.Store(time.Now().UnixNano())
 	go s.run()
 }
 
@@ -61,27 +93,61 @@ func (s *Scheduler) Stop() {
 	s.stopCh = make(chan struct{})
 }
 
+func (s *Scheduler) Running() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.running
+}
+
+// LastTick returns when the task last finished, or when the scheduler started
+// if it has not finished yet.
+func (s *Scheduler) LastTick() time.Time {
+	return time.Unix(0, s.lastTick.Load())
+}
+
+// Trigger runs the task as soon as the current run finishes instead of waiting
+// for the next tick. Triggers that arrive while one is pending are merged; a
+// stopped scheduler keeps one pending trigger for its next start.
+func (s *Scheduler) Trigger() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
 func (s *Scheduler) run() {
 	localStopCh := s.stopCh
 	defer func() {
 		close(localStopCh)
 	}()
 
-	ticker := time.NewTicker(s.interval)
+	ticker := time.NewTicker(s.Interval())
 	defer ticker.Stop()
 
-	if err := s.task(s.ctx); err != nil {
-		s.logger.Error("failed to execute task", "error", err)
-	}
+	s.tick()
 
 	for {
 		select {
 		case <-ticker.C:
-			if err := s.task(s.ctx); err != nil {
-				s.logger.Error("failed to execute task", "error", err)
-			}
+			s.tick()
+		case <-s.trigger:
+			s.tick()
+		case <-s.reset:
+			ticker.Reset(s.Interval())
 		case <-s.ctx.Done():
 			return
 		}
 	}
 }
+
+func (s *Scheduler) tick() {
+	ctx, span := tracer().Start(s.ctx, "scheduler.tick", trace.WithAttributes(attrScheduler.String(s.name)))
+	start := time.Now()
+	err := s.task(ctx)
+	s.metrics.tick(ctx, time.Since(start), err)
+	s.lastTick.Store(time.Now().UnixNano())
+	endSpan(span, err)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to execute task", "error", err)
+	}
+}
@@ -23,7 +23,7 @@ func TestScheduler_StartStop(t *testing.T) {
 	}
 
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
-	scheduler := app.NewScheduler(10*time.Millisecond, task, logger)
+	scheduler := app.NewScheduler("test", 10*time.Millisecond, task, logger)
 
 	scheduler.Start()
 	time.Sleep(25 * time.Millisecond)
@@ -45,7 +45,7 @@ func TestScheduler_TaskIsExecutedImmediately(t *testing.T) {
 	}
 
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
-	scheduler := app.NewScheduler(1*time.Hour, task, logger)
+	scheduler := app.NewScheduler("test", 1*time.Hour, task, logger)
 
 	scheduler.Start()
 	defer scheduler.Stop()
@@ -65,7 +65,7 @@ func TestScheduler_IdempotentStart(t *testing.T) {
 	}
 
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
-	scheduler := app.NewScheduler(10*time.Millisecond, task, logger)
+	scheduler := app.NewScheduler("test", 10*time.Millisecond, task, logger)
 
 	scheduler.Start()
 	scheduler.Start()
@@ -83,7 +83,7 @@ func TestScheduler_IdempotentStop(t *testing.T) {
 	}
 
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
-	scheduler := app.NewScheduler(10*time.Millisecond, task, logger)
+	scheduler := app.NewScheduler("test", 10*time.Millisecond, task, logger)
 
 	scheduler.Start()
 	time.Sleep(5 * time.Millisecond)
@@ -104,7 +104,7 @@ func TestScheduler_TaskErrorLogging(t *testing.T) {
 		return testErr
 	}
 
-	scheduler := app.NewScheduler(10*time.Millisecond, task, logger)
+	scheduler := app.NewScheduler("test", 10*time.Millisecond, task, logger)
 
 	scheduler.Start()
 	time.Sleep(25 * time.Millisecond)
@@ -124,7 +124,7 @@ func TestScheduler_StopCancelsContext(t *testing.T) {
 	}
 
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
-	scheduler := app.NewScheduler(100*time.Millisecond, task, logger)
+	scheduler := app.NewScheduler("test", 100*time.Millisecond, task, logger)
 
 	scheduler.Start()
 
@@ -155,7 +155,7 @@ func TestScheduler_MultipleStartStopCycles(t *testing.T) {
 	}
 
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
-	scheduler := app.NewScheduler(10*time.Millisecond, task, logger)
+	scheduler := app.NewScheduler("test", 10*time.Millisecond, task, logger)
 
 
 	scheduler.Start()
@@ -179,3 +179,47 @@ func TestScheduler_MultipleStartStopCycles(t *testing.T) {
 	finalExecutions := atomic.LoadInt32(&executions)
 	assert.True(t, finalExecutions > totalExecutions, "third cycle should have additional executions")
 }
+
+func TestScheduler_Trigger(t *testing.T) {
+	executed := make(chan struct{}, 4)
+	task := func(ctx context.Context) error {
+		executed <- struct{}{}
+		return nil
+	}
+
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	scheduler := app.NewScheduler("test", time.Hour, task, logger)
+
+	scheduler.Start()
+	defer scheduler.Stop()
+	<-executed
+
+	scheduler.Trigger()
+	select {
+	case <-executed:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("task was not executed on trigger")
+	}
+}
+
+func TestScheduler_SetInterval(t *testing.T) {
+	var executions int32
+	task := func(ctx context.Context) error {
+		atomic.AddInt32(&executions, 1)
+		return nil
+	}
+
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	scheduler := app.NewScheduler("test", 1*time.Hour, task, logger)
+
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	scheduler.SetInterval(10 * time.Millisecond)
+	scheduler.SetInterval(0)
+
+	assert.Equal(t, 10*time.Millisecond, scheduler.Interval())
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&executions) >= 3
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,232 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+)
+
+const (
+	DefaultStreamReplayLimit = 500
+	streamBufferSize         = 64
+)
+
+// StreamBroker carries message events between replicas.
+type StreamBroker interface {
+	Publish(ctx context.Context, event domain.OutboxEvent) error
+	// Subscribe delivers the events published by any replica until ctx is
+	// done.
+	Subscribe(ctx context.Context) (<-chan domain.OutboxEvent, error)
+}
+
+// StreamFilter selects the events a stream subscriber receives. Empty Types
+// match every event type.
+type StreamFilter struct {
+	domain.MessageFilter
+	Types []string
+}
+
+func (f StreamFilter) matches(event domain.OutboxEvent) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, event.EventType) {
+		return false
+	}
+	if f.MessageFilter.IsZero() {
+		return true
+	}
+
+	var payload domain.MessageStatusEvent
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return false
+	}
+	return f.MessageFilter.Matches(payload)
+}
+
+// MessageStream pushes message events to live subscribers. Events reach every
+// replica through the broker and are fanned out locally. A subscriber that
+// falls behind is disconnected and can resume from the last event it saw,
+// since the outbox keeps the event history.
+type MessageStream struct {
+	broker      StreamBroker
+	repo        domain.OutboxRepository
+	replayLimit uint
+	logger      *slog.Logger
+
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+type subscriber struct {
+	events chan domain.OutboxEvent
+	filter StreamFilter
+}
+
+func NewMessageStream(broker StreamBroker, repo domain.OutboxRepository, replayLimit uint, logger *slog.Logger) *MessageStream {
+	if replayLimit == 0 {
+		replayLimit = DefaultStreamReplayLimit
+	}
+
+	return &MessageStream{
+		broker:      broker,
+		repo:        repo,
+		replayLimit: replayLimit,
+		logger:      logger.With(slog.String("component", "message_stream")),
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Publish hands a committed event to the broker. It implements EventSink so
+// the stream can listen on the outbox.
+func (s *MessageStream) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	if err := s.broker.Publish(ctx, event); err != nil {
+		s.logger.WarnContext(ctx, "Error publishing live event", "event_id", event.ID, "message_id", event.MessageID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// Start subscribes to the broker and begins fanning events out.
+func (s *MessageStream) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := s.broker.Subscribe(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	s.mu.Lock()
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.mu.Unlock()
+
+	go s.dispatch(events)
+	s.logger.InfoContext(ctx, "Message stream started")
+	return nil
+}
+
+// Stop ends the broker subscription and closes every subscriber.
+func (s *MessageStream) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+
+	s.mu.Lock()
+	for sub := range s.subscribers {
+		s.removeLocked(sub)
+	}
+	s.mu.Unlock()
+	s.logger.Info("Message stream stopped")
+}
+
+func (s *MessageStream) dispatch(events <-chan domain.OutboxEvent) {
+	defer close(s.done)
+
+	for event := range events {
+		s.mu.Lock()
+		for sub := range s.subscribers {
+			if !sub.filter.matches(event) {
+				continue
+			}
+			select {
+			case sub.events <- event:
+			default:
+				s.logger.Warn("Dropping slow stream subscriber", "event_id", event.ID)
+				s.removeLocked(sub)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Subscribe returns the events matching filter. When lastEventID is positive
+// up to the replay limit of stored events after it are sent first. The
+// channel is closed when ctx is done, the stream stops or the subscriber falls
+// too far behind.
+func (s *MessageStream) Subscribe(ctx context.Context, filter StreamFilter, lastEventID int64) (<-chan domain.OutboxEvent, error) {
+	sub := &subscriber{
+		events: make(chan domain.OutboxEvent, streamBufferSize),
+		filter: filter,
+	}
+
+	// Register before reading the history so nothing committed in between is
+	// missed; duplicates are dropped below.
+	s.mu.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+
+	var replay []domain.OutboxEvent
+	if lastEventID > 0 {
+		var err error
+		replay, err = s.repo.ListAfter(ctx, lastEventID, s.replayLimit)
+		if err != nil {
+			s.remove(sub)
+			return nil, err
+		}
+	}
+
+	out := make(chan domain.OutboxEvent)
+	go func() {
+		defer close(out)
+		defer s.remove(sub)
+
+		send := func(event domain.OutboxEvent) bool {
+			select {
+			case out <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		replayed := make(map[int64]bool, len(replay))
+		for _, event := range replay {
+			replayed[event.ID] = true
+			if filter.matches(event) && !send(event) {
+				return
+			}
+		}
+
+		for {
+			select {
+			case event, ok := <-sub.events:
+				if !ok {
+					return
+				}
+				if event.ID <= lastEventID || replayed[event.ID] {
+					continue
+				}
+				if !send(event) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (s *MessageStream) remove(sub *subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeLocked(sub)
+}
+
+func (s *MessageStream) removeLocked(sub *subscriber) {
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub.events)
+	}
+}
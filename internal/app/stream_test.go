@@ -0,0 +1,142 @@
+//go:build unit
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBroker loops published events back to its single subscriber, like a
+// pub/sub channel shared by all replicas.
+type fakeBroker struct {
+	events chan domain.OutboxEvent
+}
+
+func newFakeBroker() *fakeBroker {
+	return &fakeBroker{events: make(chan domain.OutboxEvent, 16)}
+}
+
+func (b *fakeBroker) Publish(_ context.Context, event domain.OutboxEvent) error {
+	b.events <- event
+	return nil
+}
+
+func (b *fakeBroker) Subscribe(ctx context.Context) (<-chan domain.OutboxEvent, error) {
+	out := make(chan domain.OutboxEvent)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case event := <-b.events:
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func streamEvent(t *testing.T, id int64, eventType string, status domain.MessageStatusEvent) domain.OutboxEvent {
+	t.Helper()
+	payload, err := json.Marshal(status)
+	require.NoError(t, err)
+	return domain.OutboxEvent{ID: id, MessageID: status.MessageID, EventType: eventType, Payload: payload}
+}
+
+func receive(t *testing.T, events <-chan domain.OutboxEvent) domain.OutboxEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "stream closed")
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return domain.OutboxEvent{}
+	}
+}
+
+func newTestStream(t *testing.T, repo *fakeOutboxRepo) *app.MessageStream {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	stream := app.NewMessageStream(newFakeBroker(), repo, 10, logger)
+	require.NoError(t, stream.Start())
+	t.Cleanup(stream.Stop)
+	return stream
+}
+
+func TestMessageStream_Subscribe(t *testing.T) {
+	t.Run("Given a filter, only matching events are delivered", func(t *testing.T) {
+		stream := newTestStream(t, &fakeOutboxRepo{})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		events, err := stream.Subscribe(ctx, app.StreamFilter{
+			MessageFilter: domain.MessageFilter{Tenant: "acme"},
+			Types:         []string{domain.EventMessageSent},
+		}, 0)
+		require.NoError(t, err)
+
+		ctxBg := context.Background()
+		require.NoError(t, stream.Publish(ctxBg, streamEvent(t, 1, domain.EventMessageSent, domain.MessageStatusEvent{MessageID: 1, Tenant: "other"})))
+		require.NoError(t, stream.Publish(ctxBg, streamEvent(t, 2, domain.EventMessageFailed, domain.MessageStatusEvent{MessageID: 2, Tenant: "acme"})))
+		require.NoError(t, stream.Publish(ctxBg, streamEvent(t, 3, domain.EventMessageSent, domain.MessageStatusEvent{MessageID: 3, Tenant: "acme"})))
+
+		assert.Equal(t, int64(3), receive(t, events).ID)
+	})
+
+	t.Run("Given a last event ID, missed events are replayed before live ones", func(t *testing.T) {
+		repo := &fakeOutboxRepo{}
+		for id := int64(1); id <= 3; id++ {
+			event := streamEvent(t, 0, domain.EventMessageSent, domain.MessageStatusEvent{MessageID: id})
+			require.NoError(t, repo.Create(context.Background(), &event))
+		}
+		stream := newTestStream(t, repo)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		events, err := stream.Subscribe(ctx, app.StreamFilter{}, 1)
+		require.NoError(t, err)
+
+		// Event 3 arrives live as well and must not be delivered twice.
+		require.NoError(t, stream.Publish(context.Background(), repo.events[2]))
+		require.NoError(t, stream.Publish(context.Background(), streamEvent(t, 4, domain.EventMessageSent, domain.MessageStatusEvent{MessageID: 4})))
+
+		var ids []int64
+		for range 3 {
+			ids = append(ids, receive(t, events).ID)
+		}
+		assert.Equal(t, []int64{2, 3, 4}, ids)
+	})
+
+	t.Run("Given the stream stops, subscriber channels are closed", func(t *testing.T) {
+		logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+		stream := app.NewMessageStream(newFakeBroker(), &fakeOutboxRepo{}, 10, logger)
+		require.NoError(t, stream.Start())
+
+		events, err := stream.Subscribe(context.Background(), app.StreamFilter{}, 0)
+		require.NoError(t, err)
+
+		stream.Stop()
+		select {
+		case _, ok := <-events:
+			assert.False(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("stream not closed")
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+)
+
+const tracerName = "github.com/muratdemir0/gopulse-messages/internal/app"
+
+// Span attribute keys of the dispatch pipeline.
+const (
+	attrScheduler     = attribute.Key("gopulse.scheduler")
+	attrBatchSize     = attribute.Key("gopulse.batch.size")
+	attrMessageID     = attribute.Key("gopulse.message.id")
+	attrRecipientHash = attribute.Key("gopulse.message.recipient_hash")
+	attrChannel       = attribute.Key("gopulse.message.channel")
+	attrAttempt       = attribute.Key("gopulse.message.attempt")
+	attrProvider      = attribute.Key("gopulse.provider")
+	attrOutcome       = attribute.Key("gopulse.message.outcome")
+)
+
+// Dispatch outcomes recorded on message spans.
+const (
+	outcomeSent     = "sent"
+	outcomeFailed   = "failed"
+	outcomeDeferred = "deferred"
+)
+
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// endSpan records err on the span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// hashRecipient identifies a recipient on spans without exposing the address
+// or phone number.
+func hashRecipient(recipient string) string {
+	sum := sha256.Sum256([]byte(recipient))
+	return hex.EncodeToString(sum[:8])
+}
+
+// setOrigin stores the span in ctx on the message so its dispatch can link
+// back to the request that created it.
+func setOrigin(ctx context.Context, message *domain.Message) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	message.TraceID = sql.NullString{String: sc.TraceID().String(), Valid: true}
+	message.SpanID = sql.NullString{String: sc.SpanID().String(), Valid: true}
+}
+
+// originLinks returns a link to the span that created the message, if it was
+// recorded.
+func originLinks(message domain.Message) []trace.Link {
+	if !message.TraceID.Valid || !message.SpanID.Valid {
+		return nil
+	}
+
+	traceID, err := trace.TraceIDFromHex(message.TraceID.String)
+	if err != nil {
+		return nil
+	}
+	spanID, err := trace.SpanIDFromHex(message.SpanID.String)
+	if err != nil {
+		return nil
+	}
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	return []trace.Link{{SpanContext: sc}}
+}
@@ -0,0 +1,99 @@
+//go:build unit
+
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func findSpan(t *testing.T, recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+	t.Fatalf("span %s not recorded", name)
+	return nil
+}
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key string) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestMessageService_Tracing(t *testing.T) {
+	t.Run("Given a created message, its dispatch span links to the creating span", func(t *testing.T) {
+		recorder := newSpanRecorder(t)
+		repo := newFakeRepo()
+		provider := &fakeProvider{
+			name: "primary",
+			send: func(req app.SendRequest) (*app.SendResult, error) {
+				return &app.SendResult{ProviderMessageID: "ext-1"}, nil
+			},
+		}
+		service := newTestService(t, repo, provider)
+
+		message := &domain.Message{Recipient: "+905551112233", Content: "hello"}
+		require.NoError(t, service.CreateMessage(context.Background(), message))
+
+		create := findSpan(t, recorder, "message.create")
+		assert.Equal(t, create.SpanContext().TraceID().String(), message.TraceID.String)
+		assert.Equal(t, create.SpanContext().SpanID().String(), message.SpanID.String)
+
+		repo.due = append(repo.due, *message)
+		require.NoError(t, service.ProcessMessageByID(context.Background(), message.ID))
+
+		dispatch := findSpan(t, recorder, "message.dispatch")
+		require.Len(t, dispatch.Links(), 1)
+		assert.Equal(t, create.SpanContext().SpanID(), dispatch.Links()[0].SpanContext.SpanID())
+		assert.Equal(t, message.ID, spanAttribute(dispatch, "gopulse.message.id").AsInt64())
+		assert.Equal(t, "primary", spanAttribute(dispatch, "gopulse.provider").AsString())
+		assert.Equal(t, "sent", spanAttribute(dispatch, "gopulse.message.outcome").AsString())
+		assert.NotContains(t, spanAttribute(dispatch, "gopulse.message.recipient_hash").AsString(), "5551112233")
+	})
+
+	t.Run("Given a failing provider, the dispatch span records the error", func(t *testing.T) {
+		recorder := newSpanRecorder(t)
+		repo := newFakeRepo(pendingMessage(1))
+		provider := &fakeProvider{
+			name: "primary",
+			send: func(req app.SendRequest) (*app.SendResult, error) {
+				return nil, errors.New("boom")
+			},
+		}
+
+		require.NoError(t, newTestService(t, repo, provider).ProcessMessageByID(context.Background(), 1))
+
+		dispatch := findSpan(t, recorder, "message.dispatch")
+		assert.Empty(t, dispatch.Links(), "messages without a recorded origin have no link")
+		assert.Equal(t, codes.Error, dispatch.Status().Code)
+		assert.Equal(t, "failed", spanAttribute(dispatch, "gopulse.message.outcome").AsString())
+		assert.NotEmpty(t, dispatch.Events(), "the error must be recorded on the span")
+	})
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const DefaultWakeDebounce = 50 * time.Millisecond
+
+// WakeListener wakes the dispatcher when the database announces new
+// messages, so they do not wait for the next scheduler tick. Signals arriving
+// within the debounce window of the first one cause a single wake-up.
+type WakeListener struct {
+	signals  <-chan struct{}
+	wake     func()
+	debounce time.Duration
+	logger   *slog.Logger
+}
+
+func NewWakeListener(signals <-chan struct{}, wake func(), debounce time.Duration, logger *slog.Logger) *WakeListener {
+	if debounce <= 0 {
+		debounce = DefaultWakeDebounce
+	}
+
+	return &WakeListener{
+		signals:  signals,
+		wake:     wake,
+		debounce: debounce,
+		logger:   logger.With(slog.String("component", "wake_listener")),
+	}
+}
+
+// Run wakes the dispatcher until ctx is done or the signal channel closes.
+func (l *WakeListener) Run(ctx context.Context) {
+	timer := time.NewTimer(l.debounce)
+	timer.Stop()
+	pending := false
+
+	for {
+		select {
+		case _, ok := <-l.signals:
+			if !ok {
+				return
+			}
+			if !pending {
+				pending = true
+				timer.Reset(l.debounce)
+			}
+		case <-timer.C:
+			pending = false
+			l.logger.DebugContext(ctx, "Waking dispatcher")
+			l.wake()
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
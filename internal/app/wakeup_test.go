@@ -0,0 +1,42 @@
+//go:build unit
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWakeListener_DebouncesBursts(t *testing.T) {
+	signals := make(chan struct{})
+	var wakes atomic.Int32
+
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	listener := app.NewWakeListener(signals, func() { wakes.Add(1) }, 20*time.Millisecond, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		listener.Run(ctx)
+		close(done)
+	}()
+
+	for range 5 {
+		signals <- struct{}{}
+	}
+	assert.Eventually(t, func() bool { return wakes.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	signals <- struct{}{}
+	assert.Eventually(t, func() bool { return wakes.Load() == 2 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+	assert.Equal(t, int32(2), wakes.Load())
+}
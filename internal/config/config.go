@@ -16,8 +16,104 @@ type App struct {
 }
 
 type Webhook struct {
-	Host string `mapstructure:"host"`
-	Path string `mapstructure:"path"`
+	Host           string         `mapstructure:"host"`
+	Path           string         `mapstructure:"path"`
+	CircuitBreaker CircuitBreaker `mapstructure:"circuit_breaker"`
+	Signing        Signing        `mapstructure:"signing"`
+}
+
+// Signing holds the HMAC secrets for outbound webhooks. During a rotation the
+// new secret goes into Secret and the old one into PreviousSecret until every
+// receiver accepts the new one.
+type Signing struct {
+	Secret         string `mapstructure:"secret"`
+	PreviousSecret string `mapstructure:"previous_secret"`
+}
+
+func (s Signing) Secrets() []string {
+	var secrets []string
+	for _, secret := range []string{s.Secret, s.PreviousSecret} {
+		if secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+type CircuitBreaker struct {
+	Enabled              bool    `mapstructure:"enabled"`
+	FailureRateThreshold float64 `mapstructure:"failure_rate_threshold"`
+	MinRequests          int     `mapstructure:"min_requests"`
+	WindowSize           int     `mapstructure:"window_size"`
+	CoolDownSec          int     `mapstructure:"cool_down_sec"`
+	HalfOpenMaxRequests  int     `mapstructure:"half_open_max_requests"`
+}
+
+const (
+	ProviderTypeWebhook = "webhook"
+	ProviderTypeSMTP    = "smtp"
+)
+
+// Provider configures one named delivery provider. Providers are tried in the
+// order they are listed.
+type Provider struct {
+	Name             string         `mapstructure:"name"`
+	Type             string         `mapstructure:"type"`
+	Host             string         `mapstructure:"host"`
+	Path             string         `mapstructure:"path"`
+	MaxContentLength int            `mapstructure:"max_content_length"`
+	Auth             Auth           `mapstructure:"auth"`
+	Retry            Retry          `mapstructure:"retry"`
+	CircuitBreaker   CircuitBreaker `mapstructure:"circuit_breaker"`
+	Signing          Signing        `mapstructure:"signing"`
+	SMTP             SMTP           `mapstructure:"smtp"`
+	Request          Request        `mapstructure:"request"`
+	Response         Response       `mapstructure:"response"`
+}
+
+// Request shapes the outbound webhook body. Format is json (default), form or
+// xml; Body and header values are Go templates over the message.
+type Request struct {
+	Format  string            `mapstructure:"format"`
+	Body    string            `mapstructure:"body"`
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+// Response maps fields of the provider's JSON response using dot separated
+// paths such as data.id. Empty paths keep the default messageId and message.
+type Response struct {
+	MessageIDPath    string   `mapstructure:"message_id_path"`
+	StatusPath       string   `mapstructure:"status_path"`
+	AcceptedStatuses []string `mapstructure:"accepted_statuses"`
+}
+
+// SMTP holds the settings of an smtp provider. The server address is taken
+// from Provider.Host. TLS is one of none, starttls (default) or tls.
+type SMTP struct {
+	Port       int    `mapstructure:"port"`
+	From       string `mapstructure:"from"`
+	Username   string `mapstructure:"username"`
+	Password   string `mapstructure:"password"`
+	TLS        string `mapstructure:"tls"`
+	TimeoutSec int    `mapstructure:"timeout_sec"`
+}
+
+type Auth struct {
+	Type     string `mapstructure:"type"`
+	Token    string `mapstructure:"token"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	Header   string `mapstructure:"header"`
+	Value    string `mapstructure:"value"`
+}
+
+type Retry struct {
+	MaxRetries          int     `mapstructure:"max_retries"`
+	InitialIntervalMs   int     `mapstructure:"initial_interval_ms"`
+	RandomizationFactor float64 `mapstructure:"randomization_factor"`
+	Multiplier          float64 `mapstructure:"multiplier"`
+	MaxIntervalMs       int     `mapstructure:"max_interval_ms"`
+	MaxElapsedTimeMs    int     `mapstructure:"max_elapsed_time_ms"`
 }
 
 type Redis struct {
@@ -27,29 +123,235 @@ type Redis struct {
 }
 
 type Database struct {
-	DSN string `mapstructure:"dsn"`
+	DSN    string `mapstructure:"dsn"`
+	Notify Notify `mapstructure:"notify"`
+}
+
+// Notify wakes the dispatcher through Postgres LISTEN/NOTIFY when messages are
+// inserted. The scheduler tick keeps running as a safety net.
+type Notify struct {
+	Enabled    bool `mapstructure:"enabled"`
+	DebounceMs int  `mapstructure:"debounce_ms"`
+}
+
+const (
+	OutboxSinkRedisStream = "redis_stream"
+	OutboxSinkHTTP        = "http"
+)
+
+// Outbox configures the relay that publishes message status events. Sink is
+// redis_stream (default) or http. Events failing MaxAttempts times are
+// dead-lettered, and published events are deleted after RetentionSec; zero
+// keeps them forever.
+type Outbox struct {
+	Enabled      bool              `mapstructure:"enabled"`
+	Sink         string            `mapstructure:"sink"`
+	IntervalMs   int               `mapstructure:"interval_ms"`
+	BatchSize    int               `mapstructure:"batch_size"`
+	MaxAttempts  int               `mapstructure:"max_attempts"`
+	RetentionSec int               `mapstructure:"retention_sec"`
+	RedisStream  OutboxRedisStream `mapstructure:"redis_stream"`
+	HTTP         OutboxHTTP        `mapstructure:"http"`
+}
+
+type OutboxRedisStream struct {
+	Stream string `mapstructure:"stream"`
+	MaxLen int64  `mapstructure:"max_len"`
+}
+
+type OutboxHTTP struct {
+	URL        string  `mapstructure:"url"`
+	TimeoutSec int     `mapstructure:"timeout_sec"`
+	Signing    Signing `mapstructure:"signing"`
+}
+
+// Queue enables dispatching new messages through a Redis stream work queue.
+// The database poller keeps running as a reconciliation sweep and skips
+// messages younger than SweepDelaySec. Consumer defaults to hostname-pid.
+type Queue struct {
+	Enabled         bool   `mapstructure:"enabled"`
+	Stream          string `mapstructure:"stream"`
+	Group           string `mapstructure:"group"`
+	Consumer        string `mapstructure:"consumer"`
+	Workers         int    `mapstructure:"workers"`
+	BatchSize       int    `mapstructure:"batch_size"`
+	BlockMs         int    `mapstructure:"block_ms"`
+	ClaimMinIdleSec int    `mapstructure:"claim_min_idle_sec"`
+	MaxLen          int64  `mapstructure:"max_len"`
+	SweepDelaySec   int    `mapstructure:"sweep_delay_sec"`
+}
+
+// Stream configures the live message event stream. Channel is the Redis
+// pub/sub channel shared by all replicas.
+type Stream struct {
+	Channel      string `mapstructure:"channel"`
+	ReplayLimit  int    `mapstructure:"replay_limit"`
+	HeartbeatSec int    `mapstructure:"heartbeat_sec"`
+}
+
+// Health configures the /livez and /readyz checks. CheckWebhook adds a TCP
+// reachability check per webhook provider host to readiness. On shutdown
+// /readyz fails for DrainDelayMs before the server stops accepting requests,
+// so load balancers can notice and stop routing here.
+type Health struct {
+	TimeoutMs          int  `mapstructure:"timeout_ms"`
+	CacheMs            int  `mapstructure:"cache_ms"`
+	SchedulerMaxMissed int  `mapstructure:"scheduler_max_missed"`
+	CheckWebhook       bool `mapstructure:"check_webhook"`
+	DrainDelayMs       int  `mapstructure:"drain_delay_ms"`
+}
+
+// APIAuth protects the HTTP API. Callers present one of APIKeys in the
+// X-API-Key header or a JWT bearer token. Routes override the role required
+// for a mux pattern such as "POST /messages/stop"; role is public, reader,
+// operator or admin.
+type APIAuth struct {
+	Enabled bool        `mapstructure:"enabled"`
+	APIKeys []APIKey    `mapstructure:"api_keys"`
+	JWT     JWT         `mapstructure:"jwt"`
+	Routes  []RouteRole `mapstructure:"routes"`
+}
+
+type APIKey struct {
+	Name string `mapstructure:"name"`
+	Key  string `mapstructure:"key"`
+	Role string `mapstructure:"role"`
+}
+
+// JWT enables bearer tokens when Algorithm is set. HS256 uses Secret; RS256
+// uses PublicKeyFile (PEM) or JWKSFile.
+type JWT struct {
+	Algorithm     string `mapstructure:"algorithm"`
+	Secret        string `mapstructure:"secret"`
+	PublicKeyFile string `mapstructure:"public_key_file"`
+	JWKSFile      string `mapstructure:"jwks_file"`
+	Issuer        string `mapstructure:"issuer"`
+	Audience      string `mapstructure:"audience"`
+	RolesClaim    string `mapstructure:"roles_claim"`
+}
+
+type RouteRole struct {
+	Pattern string `mapstructure:"pattern"`
+	Role    string `mapstructure:"role"`
+}
+
+const (
+	RateLimitKeyClient = "client"
+	RateLimitKeyTenant = "tenant"
+	RateLimitKeyIP     = "ip"
+)
+
+// RateLimit limits requests per client across all replicas using Redis.
+// KeyBy is client (authenticated principal, else IP; default), tenant (the
+// TenantHeader, else client) or ip. Routes override Default for a mux pattern;
+// zero requests means unlimited. PerIP is checked per client IP before
+// authentication, so requests with invalid credentials are throttled too;
+// Routes override it as well.
+// With TrustProxy the client IP is read from X-Forwarded-For, TrustedHops
+// addresses from the right, since the entries left of it can be forged.
+type RateLimit struct {
+	Enabled      bool             `mapstructure:"enabled"`
+	KeyBy        string           `mapstructure:"key_by"`
+	TenantHeader string           `mapstructure:"tenant_header"`
+	TrustProxy   bool             `mapstructure:"trust_proxy"`
+	TrustedHops  int              `mapstructure:"trusted_hops"`
+	Default      RateLimitRule    `mapstructure:"default"`
+	PerIP        RateLimitRule    `mapstructure:"per_ip"`
+	Routes       []RouteRateLimit `mapstructure:"routes"`
+}
+
+type RateLimitRule struct {
+	Requests  int `mapstructure:"requests"`
+	WindowSec int `mapstructure:"window_sec"`
+}
+
+type RouteRateLimit struct {
+	Pattern   string `mapstructure:"pattern"`
+	Requests  int    `mapstructure:"requests"`
+	WindowSec int    `mapstructure:"window_sec"`
+}
+
+// Log sets the minimum level logged: debug, info, warn or error. Message
+// content is left out of logs unless DebugContent is set.
+type Log struct {
+	Level        string `mapstructure:"level"`
+	DebugContent bool   `mapstructure:"debug_content"`
+}
+
+// Scheduler sets how often the dispatcher polls the database for due
+// messages.
+type Scheduler struct {
+	IntervalSec int `mapstructure:"interval_sec"`
+}
+
+type AccessLog struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	ExcludePaths []string `mapstructure:"exclude_paths"`
+}
+
+type Metrics struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path"`
 }
 
 type Telemetry struct {
-	ServiceName  string  `mapstructure:"service_name"`
-	OTLPEndpoint string  `mapstructure:"otlp_endpoint"`
-	Enabled      bool    `mapstructure:"enabled"`
-	SampleRate   float64 `mapstructure:"sample_rate"`
+	ServiceName        string  `mapstructure:"service_name"`
+	OTLPEndpoint       string  `mapstructure:"otlp_endpoint"`
+	Enabled            bool    `mapstructure:"enabled"`
+	SampleRate         float64 `mapstructure:"sample_rate"`
+	MetricsIntervalSec int     `mapstructure:"metrics_interval_sec"`
 }
 
 type Config struct {
-	App       App       `mapstructure:"app"`
-	Webhook   Webhook   `mapstructure:"webhook"`
-	Redis     Redis     `mapstructure:"redis"`
-	Database  Database  `mapstructure:"database"`
-	Telemetry Telemetry `mapstructure:"telemetry"`
+	App       App        `mapstructure:"app"`
+	Webhook   Webhook    `mapstructure:"webhook"`
+	Providers []Provider `mapstructure:"providers"`
+	Redis     Redis      `mapstructure:"redis"`
+	Database  Database   `mapstructure:"database"`
+	Scheduler Scheduler  `mapstructure:"scheduler"`
+	Outbox    Outbox     `mapstructure:"outbox"`
+	Stream    Stream     `mapstructure:"stream"`
+	Queue     Queue      `mapstructure:"queue"`
+	Health    Health     `mapstructure:"health"`
+	Auth      APIAuth    `mapstructure:"auth"`
+	RateLimit RateLimit  `mapstructure:"rate_limit"`
+	Log       Log        `mapstructure:"log"`
+	AccessLog AccessLog  `mapstructure:"access_log"`
+	Metrics   Metrics    `mapstructure:"metrics"`
+	Telemetry Telemetry  `mapstructure:"telemetry"`
 }
 
+// ProviderConfigs returns the configured providers. When none are listed the
+// legacy webhook section is used as a single provider named "webhook".
+func (c *Config) ProviderConfigs() []Provider {
+	if len(c.Providers) > 0 {
+		return c.Providers
+	}
+
+	return []Provider{{
+		Name:           ProviderTypeWebhook,
+		Type:           ProviderTypeWebhook,
+		Host:           c.Webhook.Host,
+		Path:           c.Webhook.Path,
+		CircuitBreaker: c.Webhook.CircuitBreaker,
+		Signing:        c.Webhook.Signing,
+	}}
+}
+
+// Load reads the config file at path, applies the defaults and overrides
+// each key from its environment variable (see EnvPrefix). It does not
+// validate the result; call Validate for that.
 func Load(path string) (*Config, error) {
 	v := viper.New()
 	v.SetConfigFile(path)
 	v.SetConfigType("yaml")
-	v.AutomaticEnv()
+
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+	if err := bindEnv(v); err != nil {
+		return nil, fmt.Errorf("failed to read environment: %w", err)
+	}
 
 	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
@@ -60,5 +362,9 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if err := bindListEnv(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to read environment: %w", err)
+	}
+
 	return &cfg, nil
 }
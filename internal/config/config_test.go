@@ -3,10 +3,13 @@
 package config_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/muratdemir0/gopulse-messages/internal/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLoad(t *testing.T) {
@@ -19,9 +22,134 @@ func TestLoad(t *testing.T) {
 		assert.Equal(t, 8080, cfg.App.Port)
 		assert.Equal(t, "https://webhook.site", cfg.Webhook.Host)
 		assert.Equal(t, "/unique-webhook-id", cfg.Webhook.Path)
+		assert.True(t, cfg.Webhook.CircuitBreaker.Enabled)
+		assert.Equal(t, 0.5, cfg.Webhook.CircuitBreaker.FailureRateThreshold)
+		assert.Equal(t, 30, cfg.Webhook.CircuitBreaker.CoolDownSec)
+		assert.Equal(t, []string{"current-secret", "previous-secret"}, cfg.Webhook.Signing.Secrets())
 		assert.Equal(t, "localhost:6379", cfg.Redis.Addr)
 		assert.Equal(t, "", cfg.Redis.Password)
 		assert.Equal(t, 0, cfg.Redis.DB)
+		assert.True(t, cfg.Database.Notify.Enabled)
+		assert.Equal(t, 25, cfg.Database.Notify.DebounceMs)
+	})
+
+	t.Run("given a providers list, it should take precedence over the webhook section", func(t *testing.T) {
+		cfg, err := config.Load("../../testdata/dev.yaml")
+		assert.NoError(t, err)
+
+		providers := cfg.ProviderConfigs()
+		assert.Len(t, providers, 3)
+		assert.Equal(t, "primary", providers[0].Name)
+		assert.Equal(t, "bearer", providers[0].Auth.Type)
+		assert.Equal(t, 160, providers[0].MaxContentLength)
+		assert.Equal(t, 3, providers[0].Retry.MaxRetries)
+		assert.Equal(t, "secondary", providers[1].Name)
+		assert.Equal(t, "X-Api-Key", providers[1].Auth.Header)
+		assert.Equal(t, "form", providers[1].Request.Format)
+		assert.Equal(t, "{{.IdempotencyKey}}", providers[1].Request.Headers["x-reference"])
+		assert.Equal(t, "data.id", providers[1].Response.MessageIDPath)
+		assert.Equal(t, []string{"queued", "sent"}, providers[1].Response.AcceptedStatuses)
+		assert.Equal(t, config.ProviderTypeSMTP, providers[2].Type)
+		assert.Equal(t, 1025, providers[2].SMTP.Port)
+		assert.Equal(t, "none", providers[2].SMTP.TLS)
+	})
+
+	t.Run("given outbox and stream sections, it should load them", func(t *testing.T) {
+		cfg, err := config.Load("../../testdata/dev.yaml")
+		assert.NoError(t, err)
+
+		assert.True(t, cfg.Outbox.Enabled)
+		assert.Equal(t, config.OutboxSinkHTTP, cfg.Outbox.Sink)
+		assert.Equal(t, 500, cfg.Outbox.IntervalMs)
+		assert.Equal(t, 50, cfg.Outbox.BatchSize)
+		assert.Equal(t, 5, cfg.Outbox.MaxAttempts)
+		assert.Equal(t, 3600, cfg.Outbox.RetentionSec)
+		assert.Equal(t, int64(100000), cfg.Outbox.RedisStream.MaxLen)
+		assert.Equal(t, "https://callbacks.example.com/events", cfg.Outbox.HTTP.URL)
+		assert.Equal(t, []string{"callback-secret"}, cfg.Outbox.HTTP.Signing.Secrets())
+		assert.Equal(t, "gopulse:message-events:live", cfg.Stream.Channel)
+		assert.Equal(t, 200, cfg.Stream.ReplayLimit)
+		assert.Equal(t, 20, cfg.Stream.HeartbeatSec)
+	})
+
+	t.Run("given a queue section, it should load the work queue settings", func(t *testing.T) {
+		cfg, err := config.Load("../../testdata/dev.yaml")
+		assert.NoError(t, err)
+
+		assert.True(t, cfg.Queue.Enabled)
+		assert.Equal(t, "gopulse:message-queue", cfg.Queue.Stream)
+		assert.Equal(t, "dispatchers", cfg.Queue.Group)
+		assert.Equal(t, 8, cfg.Queue.Workers)
+		assert.Equal(t, 2000, cfg.Queue.BlockMs)
+		assert.Equal(t, 300, cfg.Queue.SweepDelaySec)
+	})
+
+	t.Run("given a health section, it should load the check settings", func(t *testing.T) {
+		cfg, err := config.Load("../../testdata/dev.yaml")
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1500, cfg.Health.TimeoutMs)
+		assert.Equal(t, 3000, cfg.Health.CacheMs)
+		assert.Equal(t, 5, cfg.Health.SchedulerMaxMissed)
+		assert.True(t, cfg.Health.CheckWebhook)
+		assert.Equal(t, 2000, cfg.Health.DrainDelayMs)
+	})
+
+	t.Run("given an auth section, it should load keys, JWT settings and route roles", func(t *testing.T) {
+		cfg, err := config.Load("../../testdata/dev.yaml")
+		assert.NoError(t, err)
+
+		assert.True(t, cfg.Auth.Enabled)
+		assert.Equal(t, []config.APIKey{{Name: "ci", Key: "ci-operator-key", Role: "operator"}}, cfg.Auth.APIKeys)
+		assert.Equal(t, "RS256", cfg.Auth.JWT.Algorithm)
+		assert.Equal(t, "/etc/gopulse/jwks.json", cfg.Auth.JWT.JWKSFile)
+		assert.Equal(t, "https://auth.example.com", cfg.Auth.JWT.Issuer)
+		assert.Equal(t, "gopulse-messages", cfg.Auth.JWT.Audience)
+		assert.Equal(t, "scope", cfg.Auth.JWT.RolesClaim)
+		assert.Equal(t, []config.RouteRole{{Pattern: "GET /messages", Role: "public"}}, cfg.Auth.Routes)
+	})
+
+	t.Run("given a rate limit section, it should load the default and route limits", func(t *testing.T) {
+		cfg, err := config.Load("../../testdata/dev.yaml")
+		assert.NoError(t, err)
+
+		assert.True(t, cfg.RateLimit.Enabled)
+		assert.Equal(t, config.RateLimitKeyTenant, cfg.RateLimit.KeyBy)
+		assert.Equal(t, "X-Tenant-ID", cfg.RateLimit.TenantHeader)
+		assert.True(t, cfg.RateLimit.TrustProxy)
+		assert.Equal(t, 1, cfg.RateLimit.TrustedHops)
+		assert.Equal(t, config.RateLimitRule{Requests: 100, WindowSec: 60}, cfg.RateLimit.Default)
+		assert.Equal(t, config.RateLimitRule{Requests: 30, WindowSec: 60}, cfg.RateLimit.PerIP)
+		assert.Equal(t, []config.RouteRateLimit{
+			{Pattern: "GET /messages", Requests: 20, WindowSec: 10},
+			{Pattern: "GET /messages/stream", Requests: 0},
+		}, cfg.RateLimit.Routes)
+	})
+
+	t.Run("given an access log section, it should load the excluded paths", func(t *testing.T) {
+		cfg, err := config.Load("../../testdata/dev.yaml")
+		assert.NoError(t, err)
+
+		assert.True(t, cfg.AccessLog.Enabled)
+		assert.Equal(t, []string{"/health", "/livez", "/readyz", "/internal/metrics"}, cfg.AccessLog.ExcludePaths)
+	})
+
+	t.Run("given a metrics section, it should load the metrics settings", func(t *testing.T) {
+		cfg, err := config.Load("../../testdata/dev.yaml")
+		assert.NoError(t, err)
+
+		assert.True(t, cfg.Metrics.Enabled)
+		assert.Equal(t, "/internal/metrics", cfg.Metrics.Path)
+		assert.Equal(t, 30, cfg.Telemetry.MetricsIntervalSec)
+	})
+
+	t.Run("given no providers list, the webhook section becomes the only provider", func(t *testing.T) {
+		cfg := &config.Config{Webhook: config.Webhook{Host: "https://webhook.site", Path: "/id"}}
+
+		providers := cfg.ProviderConfigs()
+		assert.Len(t, providers, 1)
+		assert.Equal(t, config.ProviderTypeWebhook, providers[0].Name)
+		assert.Equal(t, "https://webhook.site", providers[0].Host)
 	})
 
 	t.Run("given a non-existent config file, it should return an error", func(t *testing.T) {
@@ -30,3 +158,142 @@ func TestLoad(t *testing.T) {
 		assert.Nil(t, cfg)
 	})
 }
+
+func TestLoad_Env(t *testing.T) {
+	t.Run("given a prefixed variable, it should override a nested key", func(t *testing.T) {
+		t.Setenv("GOPULSE_WEBHOOK_HOST", "https://hooks.example.com")
+		t.Setenv("GOPULSE_DATABASE_NOTIFY_DEBOUNCE_MS", "75")
+		t.Setenv("GOPULSE_ACCESS_LOG_EXCLUDE_PATHS", "/a,/b")
+
+		cfg, err := config.Load("../../testdata/dev.yaml")
+		require.NoError(t, err)
+
+		assert.Equal(t, "https://hooks.example.com", cfg.Webhook.Host)
+		assert.Equal(t, 75, cfg.Database.Notify.DebounceMs)
+		assert.Equal(t, []string{"/a", "/b"}, cfg.AccessLog.ExcludePaths)
+	})
+
+	t.Run("given a key missing from the file, it should still be overridable", func(t *testing.T) {
+		t.Setenv("GOPULSE_TELEMETRY_OTLP_ENDPOINT", "collector:4317")
+
+		cfg, err := config.Load("../../testdata/dev.yaml")
+		require.NoError(t, err)
+
+		assert.Equal(t, "collector:4317", cfg.Telemetry.OTLPEndpoint)
+	})
+
+	t.Run("given a legacy variable, the prefixed one should win", func(t *testing.T) {
+		t.Setenv("REDIS_ADDR", "legacy:6379")
+
+		cfg, err := config.Load("../../testdata/dev.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, "legacy:6379", cfg.Redis.Addr)
+
+		t.Setenv("GOPULSE_REDIS_ADDR", "redis:6379")
+
+		cfg, err = config.Load("../../testdata/dev.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, "redis:6379", cfg.Redis.Addr)
+	})
+
+	t.Run("given a _FILE variable, it should read the value from the file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dsn")
+		require.NoError(t, os.WriteFile(path, []byte("postgres://secret@db/gopulse\n"), 0o600))
+		t.Setenv("GOPULSE_DATABASE_DSN_FILE", path)
+
+		cfg, err := config.Load("../../testdata/dev.yaml")
+		require.NoError(t, err)
+
+		assert.Equal(t, "postgres://secret@db/gopulse", cfg.Database.DSN)
+	})
+
+	t.Run("given both a variable and its _FILE variable, it should fail", func(t *testing.T) {
+		t.Setenv("GOPULSE_DATABASE_DSN", "postgres://db/gopulse")
+		t.Setenv("GOPULSE_DATABASE_DSN_FILE", "/run/secrets/dsn")
+
+		_, err := config.Load("../../testdata/dev.yaml")
+
+		assert.ErrorContains(t, err, "GOPULSE_DATABASE_DSN_FILE")
+	})
+
+	t.Run("given indexed variables, they should override fields of list elements", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "password")
+		require.NoError(t, os.WriteFile(path, []byte("smtp-secret\n"), 0o600))
+		t.Setenv("GOPULSE_PROVIDERS_0_AUTH_TOKEN", "env-token")
+		t.Setenv("GOPULSE_PROVIDERS_1_SIGNING_SECRET", "env-signing")
+		t.Setenv("GOPULSE_PROVIDERS_2_SMTP_PASSWORD_FILE", path)
+		t.Setenv("GOPULSE_PROVIDERS_2_SMTP_PORT", "2525")
+		t.Setenv("GOPULSE_AUTH_API_KEYS_0_KEY", "env-api-key")
+
+		cfg, err := config.Load("../../testdata/dev.yaml")
+		require.NoError(t, err)
+
+		assert.Equal(t, "env-token", cfg.Providers[0].Auth.Token)
+		assert.Equal(t, "env-signing", cfg.Providers[1].Signing.Secret)
+		assert.Equal(t, "smtp-secret", cfg.Providers[2].SMTP.Password)
+		assert.Equal(t, 2525, cfg.Providers[2].SMTP.Port)
+		assert.Equal(t, "env-api-key", cfg.Auth.APIKeys[0].Key)
+		assert.Equal(t, "secondary-key", cfg.Providers[1].Auth.Value)
+	})
+
+	t.Run("given an invalid indexed variable, it should fail", func(t *testing.T) {
+		t.Setenv("GOPULSE_PROVIDERS_2_SMTP_PORT", "smtp")
+
+		_, err := config.Load("../../testdata/dev.yaml")
+
+		assert.ErrorContains(t, err, "GOPULSE_PROVIDERS_2_SMTP_PORT")
+	})
+
+	t.Run("given keys missing from the file, the defaults should apply", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "minimal.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("database:\n  dsn: postgres://db/gopulse\n"), 0o600))
+
+		cfg, err := config.Load(path)
+		require.NoError(t, err)
+
+		assert.Equal(t, 8080, cfg.App.Port)
+		assert.Equal(t, 30, cfg.App.ReadTimeout)
+		assert.Equal(t, "/metrics", cfg.Metrics.Path)
+		assert.Equal(t, 1.0, cfg.Telemetry.SampleRate)
+		assert.Equal(t, config.OutboxSinkRedisStream, cfg.Outbox.Sink)
+		assert.Equal(t, 10, cfg.Outbox.MaxAttempts)
+		assert.Equal(t, 86400, cfg.Outbox.RetentionSec)
+		assert.Equal(t, "postgres://db/gopulse", cfg.Database.DSN)
+	})
+}
+
+func TestConfig_Validate(t *testing.T) {
+	for _, path := range []string{"../../testdata/dev.yaml", "../../.config/dev.yaml", "../../.config/test.yaml"} {
+		t.Run("given "+path+", it should be valid", func(t *testing.T) {
+			cfg, err := config.Load(path)
+			require.NoError(t, err)
+
+			assert.NoError(t, cfg.Validate())
+		})
+	}
+
+	t.Run("given several invalid fields, it should report all of them", func(t *testing.T) {
+		cfg, err := config.Load("../../testdata/dev.yaml")
+		require.NoError(t, err)
+		cfg.App.Port = 0
+		cfg.Redis.Addr = ""
+		cfg.Providers[0].Host = "not a url"
+		cfg.Auth.APIKeys[0].Role = "root"
+		cfg.RateLimit.KeyBy = "cookie"
+		cfg.Telemetry.SampleRate = 2
+
+		err = cfg.Validate()
+
+		require.Error(t, err)
+		for _, field := range []string{
+			"app.port",
+			"redis.addr: is required",
+			"providers[0].host",
+			"auth.api_keys[0].role",
+			"rate_limit.key_by",
+			"telemetry.sample_rate",
+		} {
+			assert.ErrorContains(t, err, field)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package config
+
+// defaults apply to every key the config file and environment leave unset.
+// Connection settings such as database.dsn and redis.addr have no default so
+// that a missing value is reported by Validate.
+var defaults = map[string]any{
+	"app.name":          "gopulse-messages",
+	"app.port":          8080,
+	"app.read_timeout":  30,
+	"app.write_timeout": 30,
+	"app.idle_timeout":  120,
+	"app.max_header_mb": 1,
+
+	"database.notify.debounce_ms": 50,
+
+	"scheduler.interval_sec": 120,
+
+	"outbox.sink":                 OutboxSinkRedisStream,
+	"outbox.interval_ms":          1000,
+	"outbox.batch_size":           100,
+	"outbox.max_attempts":         10,
+	"outbox.retention_sec":        86400,
+	"outbox.redis_stream.stream":  "gopulse:message-events",
+	"outbox.redis_stream.max_len": 100000,
+	"outbox.http.timeout_sec":     5,
+
+	"stream.channel":       "gopulse:message-events:live",
+	"stream.replay_limit":  500,
+	"stream.heartbeat_sec": 15,
+
+	"queue.stream":             "gopulse:message-queue",
+	"queue.group":              "dispatchers",
+	"queue.workers":            4,
+	"queue.batch_size":         10,
+	"queue.block_ms":           5000,
+	"queue.claim_min_idle_sec": 60,
+	"queue.max_len":            100000,
+	"queue.sweep_delay_sec":    120,
+
+	"health.timeout_ms":           2000,
+	"health.cache_ms":             5000,
+	"health.scheduler_max_missed": 3,
+	"health.drain_delay_ms":       5000,
+
+	"auth.jwt.roles_claim": "roles",
+
+	"rate_limit.key_by":            RateLimitKeyClient,
+	"rate_limit.tenant_header":     "X-Tenant-ID",
+	"rate_limit.trusted_hops":      1,
+	"rate_limit.per_ip.requests":   600,
+	"rate_limit.per_ip.window_sec": 60,
+
+	"log.level":         "info",
+	"log.debug_content": false,
+
+	"metrics.path": "/metrics",
+
+	"telemetry.service_name":         "gopulse-messages",
+	"telemetry.sample_rate":          1.0,
+	"telemetry.metrics_interval_sec": 15,
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix prefixes the environment variable of every config key, with dots
+// replaced by underscores: database.dsn is read from GOPULSE_DATABASE_DSN.
+// Appending _FILE to the name reads the value from the named file instead,
+// for secrets mounted as files. Fields of list elements take their index:
+// providers[0].auth.token is read from GOPULSE_PROVIDERS_0_AUTH_TOKEN.
+const EnvPrefix = "GOPULSE"
+
+// legacyEnv lists the unprefixed variables read before EnvPrefix existed. The
+// prefixed variable wins when both are set.
+var legacyEnv = map[string][]string{
+	"database.dsn":            {"DATABASE_DSN"},
+	"redis.addr":              {"REDIS_ADDR"},
+	"redis.password":          {"REDIS_PASSWORD"},
+	"redis.db":                {"REDIS_DB"},
+	"telemetry.otlp_endpoint": {"TELEMETRY_OTLP_ENDPOINT"},
+	"telemetry.enabled":       {"TELEMETRY_ENABLED"},
+}
+
+// EnvName returns the environment variable that overrides key.
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// bindEnv binds every scalar and string list field of Config to its
+// environment variable and resolves _FILE variables. Fields of lists of
+// structs, such as providers, are set by bindListEnv once the file is read.
+func bindEnv(v *viper.Viper) error {
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	var errs []error
+	for _, key := range keys(reflect.TypeOf(Config{}), "") {
+		name := EnvName(key)
+		if err := v.BindEnv(append([]string{key, name}, legacyEnv[key]...)...); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		value, ok, err := envFile(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		if ok {
+			v.Set(key, value)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// bindListEnv overrides the scalar fields of struct list elements from their
+// indexed variable or its _FILE variable: providers[0].auth.token is read
+// from GOPULSE_PROVIDERS_0_AUTH_TOKEN. Viper cannot bind these because the
+// list length is only known once the file is read, so only elements listed
+// in the file can be overridden.
+func bindListEnv(cfg *Config) error {
+	var errs []error
+	setListEnv(reflect.ValueOf(cfg).Elem(), "", false, &errs)
+	return errors.Join(errs...)
+}
+
+func setListEnv(v reflect.Value, prefix string, inList bool, errs *[]error) {
+	for i := 0; i < v.NumField(); i++ {
+		tag := v.Type().Field(i).Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Struct:
+			setListEnv(field, key, inList, errs)
+		case reflect.Slice:
+			if field.Type().Elem().Kind() == reflect.Struct {
+				for j := 0; j < field.Len(); j++ {
+					setListEnv(field.Index(j), fmt.Sprintf("%s.%d", key, j), true, errs)
+				}
+			}
+		case reflect.Map:
+		default:
+			if inList {
+				if err := setEnv(field, key); err != nil {
+					*errs = append(*errs, err)
+				}
+			}
+		}
+	}
+}
+
+// setEnv sets field from the environment variable of key, if set.
+func setEnv(field reflect.Value, key string) error {
+	name := EnvName(key)
+	value, ok, err := envFile(name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	if !ok {
+		if value, ok = os.LookupEnv(name); !ok {
+			return nil
+		}
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: invalid %s: %w", key, name, err)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: invalid %s: %w", key, name, err)
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s: invalid %s: %w", key, name, err)
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("%s: %s is not supported for %s fields", key, name, field.Kind())
+	}
+	return nil
+}
+
+// envFile returns the contents of the file named by the _FILE variable of
+// name, without trailing newlines. It fails if name is set as well.
+func envFile(name string) (string, bool, error) {
+	path, ok := os.LookupEnv(name + "_FILE")
+	if !ok {
+		return "", false, nil
+	}
+	if _, set := os.LookupEnv(name); set {
+		return "", false, fmt.Errorf("both %s and %s_FILE are set", name, name)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s_FILE: %w", name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// keys returns the dotted mapstructure keys of the bindable fields of t.
+func keys(t reflect.Type, prefix string) []string {
+	var out []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			out = append(out, keys(field.Type, key)...)
+		case reflect.Slice:
+			if field.Type.Elem().Kind() == reflect.String {
+				out = append(out, key)
+			}
+		case reflect.Map:
+		default:
+			out = append(out, key)
+		}
+	}
+	return out
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Validate reports every invalid or missing field at once, one error per
+// field, joined with errors.Join.
+func (c *Config) Validate() error {
+	var v validator
+
+	v.check(c.App.Port > 0 && c.App.Port <= 65535, "app.port", "must be between 1 and 65535")
+	v.positive("app.read_timeout", c.App.ReadTimeout)
+	v.positive("app.write_timeout", c.App.WriteTimeout)
+	v.positive("app.idle_timeout", c.App.IdleTimeout)
+	v.positive("app.max_header_mb", c.App.MaxHeaderMB)
+
+	v.required("database.dsn", c.Database.DSN)
+	v.required("redis.addr", c.Redis.Addr)
+
+	v.positive("scheduler.interval_sec", c.Scheduler.IntervalSec)
+
+	c.validateProviders(&v)
+	c.validateOutbox(&v)
+
+	if c.Queue.Enabled {
+		v.required("queue.stream", c.Queue.Stream)
+		v.required("queue.group", c.Queue.Group)
+		v.positive("queue.workers", c.Queue.Workers)
+		v.positive("queue.batch_size", c.Queue.BatchSize)
+	}
+
+	v.required("stream.channel", c.Stream.Channel)
+	v.positive("stream.heartbeat_sec", c.Stream.HeartbeatSec)
+
+	v.positive("health.timeout_ms", c.Health.TimeoutMs)
+	v.check(c.Health.CacheMs >= 0, "health.cache_ms", "must not be negative")
+	v.positive("health.scheduler_max_missed", c.Health.SchedulerMaxMissed)
+	v.check(c.Health.DrainDelayMs >= 0, "health.drain_delay_ms", "must not be negative")
+
+	c.validateAuth(&v)
+	c.validateRateLimit(&v)
+
+	v.oneOf("log.level", c.Log.Level, "debug", "info", "warn", "error")
+	v.check(strings.HasPrefix(c.Metrics.Path, "/"), "metrics.path", "must start with /")
+
+	v.check(c.Telemetry.SampleRate >= 0 && c.Telemetry.SampleRate <= 1, "telemetry.sample_rate", "must be between 0 and 1")
+	v.check(c.Telemetry.MetricsIntervalSec >= 0, "telemetry.metrics_interval_sec", "must not be negative")
+
+	return v.err()
+}
+
+func (c *Config) validateProviders(v *validator) {
+	names := make(map[string]bool)
+	for i, pc := range c.ProviderConfigs() {
+		field := fmt.Sprintf("providers[%d]", i)
+		if len(c.Providers) == 0 {
+			field = "webhook"
+		}
+
+		v.required(field+".name", pc.Name)
+		v.check(!names[pc.Name], field+".name", fmt.Sprintf("duplicate provider %q", pc.Name))
+		names[pc.Name] = true
+
+		switch pc.Type {
+		case ProviderTypeWebhook, "":
+			v.url(field+".host", pc.Host)
+			v.oneOf(field+".auth.type", pc.Auth.Type, "", "none", "bearer", "basic", "header")
+			v.oneOf(field+".request.format", pc.Request.Format, "", "json", "form", "xml")
+		case ProviderTypeSMTP:
+			v.required(field+".host", pc.Host)
+			v.required(field+".smtp.from", pc.SMTP.From)
+			v.check(pc.SMTP.Port >= 0 && pc.SMTP.Port <= 65535, field+".smtp.port", "must be a valid port")
+			v.oneOf(field+".smtp.tls", pc.SMTP.TLS, "", "none", "starttls", "tls")
+		default:
+			v.add(field+".type", fmt.Sprintf("unsupported provider type %q", pc.Type))
+		}
+	}
+}
+
+func (c *Config) validateOutbox(v *validator) {
+	if !c.Outbox.Enabled {
+		return
+	}
+
+	v.positive("outbox.interval_ms", c.Outbox.IntervalMs)
+	v.positive("outbox.batch_size", c.Outbox.BatchSize)
+	v.positive("outbox.max_attempts", c.Outbox.MaxAttempts)
+	v.check(c.Outbox.RetentionSec >= 0, "outbox.retention_sec", "must not be negative")
+
+	switch c.Outbox.Sink {
+	case OutboxSinkRedisStream, "":
+		v.required("outbox.redis_stream.stream", c.Outbox.RedisStream.Stream)
+	case OutboxSinkHTTP:
+		v.url("outbox.http.url", c.Outbox.HTTP.URL)
+	default:
+		v.add("outbox.sink", fmt.Sprintf("unsupported sink %q", c.Outbox.Sink))
+	}
+}
+
+var roles = []string{"public", "reader", "operator", "admin"}
+
+func (c *Config) validateAuth(v *validator) {
+	if !c.Auth.Enabled {
+		return
+	}
+
+	jwt := c.Auth.JWT
+	v.check(len(c.Auth.APIKeys) > 0 || jwt.Algorithm != "", "auth", "enabled without api_keys or jwt")
+
+	for i, key := range c.Auth.APIKeys {
+		field := fmt.Sprintf("auth.api_keys[%d]", i)
+		v.required(field+".name", key.Name)
+		v.required(field+".key", key.Key)
+		v.oneOf(field+".role", key.Role, roles[1:]...)
+	}
+
+	switch jwt.Algorithm {
+	case "":
+	case "HS256":
+		v.required("auth.jwt.secret", jwt.Secret)
+	case "RS256":
+		v.check(jwt.PublicKeyFile != "" || jwt.JWKSFile != "", "auth.jwt", "RS256 requires public_key_file or jwks_file")
+	default:
+		v.add("auth.jwt.algorithm", fmt.Sprintf("unsupported algorithm %q", jwt.Algorithm))
+	}
+
+	for i, route := range c.Auth.Routes {
+		field := fmt.Sprintf("auth.routes[%d]", i)
+		v.required(field+".pattern", route.Pattern)
+		v.oneOf(field+".role", route.Role, roles...)
+	}
+}
+
+func (c *Config) validateRateLimit(v *validator) {
+	rl := c.RateLimit
+	if !rl.Enabled {
+		return
+	}
+
+	v.oneOf("rate_limit.key_by", rl.KeyBy, RateLimitKeyClient, RateLimitKeyTenant, RateLimitKeyIP)
+	if rl.KeyBy == RateLimitKeyTenant {
+		v.required("rate_limit.tenant_header", rl.TenantHeader)
+	}
+	if rl.TrustProxy {
+		v.positive("rate_limit.trusted_hops", rl.TrustedHops)
+	}
+
+	v.rateLimitRule("rate_limit.default", rl.Default.Requests, rl.Default.WindowSec)
+	v.rateLimitRule("rate_limit.per_ip", rl.PerIP.Requests, rl.PerIP.WindowSec)
+	for i, route := range rl.Routes {
+		field := fmt.Sprintf("rate_limit.routes[%d]", i)
+		v.required(field+".pattern", route.Pattern)
+		v.rateLimitRule(field, route.Requests, route.WindowSec)
+	}
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) add(field, message string) {
+	v.errs = append(v.errs, fmt.Errorf("%s: %s", field, message))
+}
+
+func (v *validator) check(ok bool, field, message string) {
+	if !ok {
+		v.add(field, message)
+	}
+}
+
+func (v *validator) required(field, value string) {
+	v.check(value != "", field, "is required")
+}
+
+func (v *validator) positive(field string, value int) {
+	v.check(value > 0, field, "must be greater than 0")
+}
+
+func (v *validator) oneOf(field, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		v.add(field, fmt.Sprintf("must be one of %s, got %q", strings.Join(nonEmpty(allowed), ", "), value))
+	}
+}
+
+func (v *validator) url(field, value string) {
+	if value == "" {
+		v.add(field, "is required")
+		return
+	}
+	u, err := url.Parse(value)
+	v.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", field, "must be an http or https URL")
+}
+
+func (v *validator) rateLimitRule(field string, requests, windowSec int) {
+	v.check(requests >= 0, field+".requests", "must not be negative")
+	if requests > 0 {
+		v.positive(field+".window_sec", windowSec)
+	}
+}
+
+func (v *validator) err() error {
+	return errors.Join(v.errs...)
+}
+
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, value := range values {
+		if value != "" {
+			out = append(out, value)
+		}
+	}
+	return out
+}
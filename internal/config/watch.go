@@ -0,0 +1,220 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadable lists the keys applied without a restart, with list indexes
+// written as []. Changes to any other key are published but only take effect
+// after a restart.
+var reloadable = []string{
+	"log.level",
+	"telemetry.sample_rate",
+	"scheduler.interval_sec",
+	"queue.sweep_delay_sec",
+	"webhook.host",
+	"webhook.path",
+	"providers[].host",
+	"providers[].path",
+}
+
+var listIndex = regexp.MustCompile(`\[\d+\]`)
+
+// Reloadable reports whether a change to key takes effect without a restart.
+func Reloadable(key string) bool {
+	return slices.Contains(reloadable, listIndex.ReplaceAllString(key, "[]"))
+}
+
+// Watcher holds the current config and replaces it when the file changes or
+// Reload is called. A new config is published only if it loads and validates;
+// otherwise the current one is kept.
+type Watcher struct {
+	path        string
+	current     atomic.Pointer[Config]
+	mu          sync.Mutex
+	subscribers []func(*Config)
+	logger      *slog.Logger
+}
+
+func NewWatcher(path string, initial *Config, logger *slog.Logger) *Watcher {
+	w := &Watcher{
+		path:   path,
+		logger: logger.With(slog.String("component", "config_watcher"), slog.String("path", path)),
+	}
+	w.current.Store(initial)
+	return w
+}
+
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+// Subscribe calls fn with every config published after this call. Subscribers
+// run in order on the reloading goroutine and should apply changes quickly.
+func (w *Watcher) Subscribe(fn func(*Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload reads, validates and publishes the config file. It returns the error
+// that made it keep the current config.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	next, err := Load(w.path)
+	if err == nil {
+		err = next.Validate()
+	}
+	if err != nil {
+		w.logger.Error("config reload rejected, keeping the current config", slog.String("error", err.Error()))
+		return err
+	}
+
+	changed := Diff(w.current.Load(), next)
+	if len(changed) == 0 {
+		w.logger.Debug("config reloaded without changes")
+		return nil
+	}
+
+	var restart []string
+	for _, key := range changed {
+		if !Reloadable(key) {
+			restart = append(restart, key)
+		}
+	}
+
+	w.current.Store(next)
+	w.logger.Info("config reloaded", slog.Any("changed", changed))
+	if len(restart) > 0 {
+		w.logger.Warn("changed keys take effect after a restart", slog.Any("keys", restart))
+	}
+
+	for _, fn := range w.subscribers {
+		fn(next)
+	}
+	return nil
+}
+
+// Watch reloads whenever the config file changes until ctx is done. It
+// watches the file's directory so that it follows atomic replacements such as
+// mounted ConfigMap updates, which swap a symlink instead of writing the file.
+func (w *Watcher) Watch(ctx context.Context) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	if err := fsw.Add(filepath.Dir(w.path)); err != nil {
+		_ = fsw.Close()
+		return fmt.Errorf("failed to watch %s: %w", w.path, err)
+	}
+
+	go w.watch(ctx, fsw)
+	return nil
+}
+
+func (w *Watcher) watch(ctx context.Context, fsw *fsnotify.Watcher) {
+	defer fsw.Close()
+
+	file := filepath.Clean(w.path)
+	target, _ := filepath.EvalSymlinks(file)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return
+			}
+			current, _ := filepath.EvalSymlinks(file)
+			written := filepath.Clean(event.Name) == file && event.Op&(fsnotify.Write|fsnotify.Create) != 0
+			if !written && current == target {
+				continue
+			}
+			target = current
+			w.logger.Info("config file changed", slog.String("op", event.Op.String()))
+			_ = w.Reload()
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Warn("config watcher error", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// Diff returns the sorted keys whose values differ between old and new. List
+// elements are keyed by index, such as providers[0].host.
+func Diff(old, new *Config) []string {
+	before := flatten(reflect.ValueOf(*old), "")
+	after := flatten(reflect.ValueOf(*new), "")
+
+	var changed []string
+	for key, value := range after {
+		if previous, ok := before[key]; !ok || previous != value {
+			changed = append(changed, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+
+	sort.Strings(changed)
+	return changed
+}
+
+// flatten maps the dotted key of every leaf value of v to its formatted value.
+func flatten(v reflect.Value, prefix string) map[string]string {
+	out := make(map[string]string)
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			tag := v.Type().Field(i).Tag.Get("mapstructure")
+			if tag == "" || tag == "-" {
+				continue
+			}
+			for key, value := range flatten(v.Field(i), join(tag)) {
+				out[key] = value
+			}
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Struct {
+			out[prefix] = fmt.Sprint(v.Interface())
+			break
+		}
+		for i := 0; i < v.Len(); i++ {
+			for key, value := range flatten(v.Index(i), fmt.Sprintf("%s[%d]", prefix, i)) {
+				out[key] = value
+			}
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			out[join(strings.ToLower(fmt.Sprint(key.Interface())))] = fmt.Sprint(v.MapIndex(key).Interface())
+		}
+	default:
+		out[prefix] = fmt.Sprint(v.Interface())
+	}
+	return out
+}
@@ -0,0 +1,161 @@
+//go:build unit
+
+package config_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const watchedConfig = `
+database:
+  dsn: postgres://db/gopulse
+redis:
+  addr: localhost:6379
+webhook:
+  host: https://old.example.com
+  path: /hook
+log:
+  level: info
+`
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func newWatcher(t *testing.T) (*config.Watcher, string, *bytes.Buffer) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, watchedConfig)
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate())
+
+	var logs bytes.Buffer
+	return config.NewWatcher(path, cfg, slog.New(slog.NewTextHandler(&logs, nil))), path, &logs
+}
+
+func TestDiff(t *testing.T) {
+	old := &config.Config{
+		Webhook:   config.Webhook{Host: "https://a.example.com"},
+		Providers: []config.Provider{{Name: "primary", Host: "https://a.example.com"}},
+	}
+	updated := &config.Config{
+		Webhook:   config.Webhook{Host: "https://a.example.com"},
+		Providers: []config.Provider{{Name: "primary", Host: "https://b.example.com"}, {Name: "backup"}},
+		Log:       config.Log{Level: "debug"},
+	}
+
+	changed := config.Diff(old, updated)
+
+	assert.Contains(t, changed, "providers[0].host")
+	assert.Contains(t, changed, "providers[1].name")
+	assert.Contains(t, changed, "log.level")
+	assert.NotContains(t, changed, "webhook.host")
+	assert.Empty(t, config.Diff(old, old))
+}
+
+func TestReloadable(t *testing.T) {
+	assert.True(t, config.Reloadable("providers[3].host"))
+	assert.True(t, config.Reloadable("log.level"))
+	assert.False(t, config.Reloadable("database.dsn"))
+	assert.False(t, config.Reloadable("providers[0].name"))
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	t.Run("Given a valid change, it should publish the new config to subscribers", func(t *testing.T) {
+		watcher, path, logs := newWatcher(t)
+		var published *config.Config
+		watcher.Subscribe(func(cfg *config.Config) { published = cfg })
+
+		writeConfig(t, path, strings.Replace(watchedConfig, "old.example.com", "new.example.com", 1))
+		require.NoError(t, watcher.Reload())
+
+		require.NotNil(t, published)
+		assert.Equal(t, "https://new.example.com", published.Webhook.Host)
+		assert.Same(t, published, watcher.Current())
+		assert.Contains(t, logs.String(), "webhook.host")
+	})
+
+	t.Run("Given a change to a structural key, it should warn that a restart is needed", func(t *testing.T) {
+		watcher, path, logs := newWatcher(t)
+
+		writeConfig(t, path, strings.Replace(watchedConfig, "postgres://db/gopulse", "postgres://other/gopulse", 1))
+		require.NoError(t, watcher.Reload())
+
+		assert.Contains(t, logs.String(), "take effect after a restart")
+		assert.Equal(t, "postgres://other/gopulse", watcher.Current().Database.DSN)
+	})
+
+	t.Run("Given an invalid config, it should keep the current one", func(t *testing.T) {
+		watcher, path, _ := newWatcher(t)
+		current := watcher.Current()
+		var calls int
+		watcher.Subscribe(func(*config.Config) { calls++ })
+
+		writeConfig(t, path, strings.Replace(watchedConfig, "level: info", "level: loud", 1))
+		err := watcher.Reload()
+
+		assert.ErrorContains(t, err, "log.level")
+		assert.Same(t, current, watcher.Current())
+		assert.Zero(t, calls)
+	})
+
+	t.Run("Given an unchanged file, it should not notify subscribers", func(t *testing.T) {
+		watcher, _, _ := newWatcher(t)
+		var calls int
+		watcher.Subscribe(func(*config.Config) { calls++ })
+
+		require.NoError(t, watcher.Reload())
+
+		assert.Zero(t, calls)
+	})
+}
+
+func TestWatcher_Watch(t *testing.T) {
+	t.Run("Given a watched file, writing it should reload the config", func(t *testing.T) {
+		watcher, path, _ := newWatcher(t)
+		var host atomic.Value
+		watcher.Subscribe(func(cfg *config.Config) { host.Store(cfg.Webhook.Host) })
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		require.NoError(t, watcher.Watch(ctx))
+
+		writeConfig(t, path, strings.Replace(watchedConfig, "old.example.com", "new.example.com", 1))
+
+		assert.Eventually(t, func() bool {
+			return host.Load() == "https://new.example.com"
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Given the context is cancelled, it should stop reloading", func(t *testing.T) {
+		watcher, path, _ := newWatcher(t)
+		var calls atomic.Int32
+		watcher.Subscribe(func(*config.Config) { calls.Add(1) })
+
+		ctx, cancel := context.WithCancel(context.Background())
+		require.NoError(t, watcher.Watch(ctx))
+		cancel()
+		time.Sleep(50 * time.Millisecond)
+
+		writeConfig(t, path, strings.Replace(watchedConfig, "old.example.com", "new.example.com", 1))
+
+		assert.Never(t, func() bool {
+			return calls.Load() > 0
+		}, 200*time.Millisecond, 10*time.Millisecond)
+	})
+}
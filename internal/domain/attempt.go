@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// MaxAttemptResponseBody is how much of a provider response is kept on a
+// MessageAttempt.
+const MaxAttemptResponseBody = 2048
+
+// AttemptErrorClass groups failed attempts by how they were handled.
+type AttemptErrorClass string
+
+const (
+	AttemptErrorNone        AttemptErrorClass = ""
+	AttemptErrorTransient   AttemptErrorClass = "transient"
+	AttemptErrorPermanent   AttemptErrorClass = "permanent"
+	AttemptErrorUnavailable AttemptErrorClass = "unavailable"
+)
+
+// MessageAttempt records a single dispatch of a message to a provider.
+type MessageAttempt struct {
+	ID           int64             `db:"id"`
+	MessageID    int64             `db:"message_id"`
+	Provider     string            `db:"provider"`
+	StatusCode   sql.NullInt64     `db:"status_code"`
+	LatencyMs    int64             `db:"latency_ms"`
+	ResponseBody sql.NullString    `db:"response_body"`
+	ErrorClass   AttemptErrorClass `db:"error_class"`
+	Error        sql.NullString    `db:"error"`
+	// RetryAttempt is the number of transport attempts the provider client
+	// made, as reported in the X-Retry-Attempt header.
+	RetryAttempt sql.NullInt64 `db:"retry_attempt"`
+	CreatedAt    time.Time     `db:"created_at"`
+}
+
+type MessageAttemptRepository interface {
+	Create(ctx context.Context, attempt *MessageAttempt) error
+	ListByMessage(ctx context.Context, messageID int64) ([]MessageAttempt, error)
+}
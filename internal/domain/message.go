@@ -3,9 +3,20 @@ package domain
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/mail"
 	"time"
 )
 
+var (
+	ErrUnknownChannel   = errors.New("unknown channel")
+	ErrInvalidRecipient = errors.New("invalid recipient")
+	ErrMissingSubject   = errors.New("email subject is required")
+	ErrMessageNotDue    = errors.New("message not found or no longer due")
+)
+
 type MessageStatus string
 
 const (
@@ -14,6 +25,40 @@ const (
 	MessageStatusFailed  MessageStatus = "failed"
 )
 
+type MessagePriority string
+
+const (
+	MessagePriorityLow    MessagePriority = "low"
+	MessagePriorityNormal MessagePriority = "normal"
+	MessagePriorityHigh   MessagePriority = "high"
+)
+
+func (p MessagePriority) Valid() bool {
+	switch p {
+	case MessagePriorityLow, MessagePriorityNormal, MessagePriorityHigh:
+		return true
+	default:
+		return false
+	}
+}
+
+// MessageChannel is the medium a message is delivered through.
+type MessageChannel string
+
+const (
+	MessageChannelSMS   MessageChannel = "sms"
+	MessageChannelEmail MessageChannel = "email"
+)
+
+func (c MessageChannel) Valid() bool {
+	switch c {
+	case MessageChannelSMS, MessageChannelEmail:
+		return true
+	default:
+		return false
+	}
+}
+
 type Message struct {
 	ID            int64          `db:"id"`
 	Recipient     string         `db:"recipient"`
@@ -27,13 +72,138 @@ type Message struct {
 	ResponseID    sql.NullString `db:"response_id"`
 	ResponseCode  sql.NullInt64  `db:"response_code"`
 	ErrorMessage  sql.NullString `db:"error_message"`
+	// Provider is the name of the provider that delivered the message.
+	Provider sql.NullString `db:"provider"`
+	// FailedAttempts lists the providers that failed before the message was
+	// delivered or given up on.
+	FailedAttempts ProviderAttempts `db:"failed_attempts"`
+	Priority       MessagePriority  `db:"priority"`
+	Tenant         sql.NullString   `db:"tenant"`
+	Tags           Tags             `db:"tags"`
+	Channel        MessageChannel   `db:"channel"`
+	// Subject and HTMLContent only apply to email. Content is the plain text
+	// body.
+	Subject     sql.NullString `db:"subject"`
+	HTMLContent sql.NullString `db:"html_content"`
+	// TraceID and SpanID identify the span that created the message, so its
+	// dispatch can link back to the originating request.
+	TraceID sql.NullString `db:"trace_id"`
+	SpanID  sql.NullString `db:"span_id"`
+	// ClaimedUntil is set while a dispatcher is sending the message, so other
+	// replicas skip it until the claim is released or expires.
+	ClaimedUntil sql.NullTime `db:"claimed_until"`
+}
+
+// Validate checks the message against the rules of its channel: SMS needs a
+// phone number, email needs an address and a subject.
+func (m Message) Validate() error {
+	switch m.ChannelOrDefault() {
+	case MessageChannelSMS:
+		if !isPhoneNumber(m.Recipient) {
+			return fmt.Errorf("%w: %q is not a phone number", ErrInvalidRecipient, m.Recipient)
+		}
+	case MessageChannelEmail:
+		addr, err := mail.ParseAddress(m.Recipient)
+		if err != nil || addr.Address != m.Recipient {
+			return fmt.Errorf("%w: %q is not an email address", ErrInvalidRecipient, m.Recipient)
+		}
+		if !m.Subject.Valid || m.Subject.String == "" {
+			return ErrMissingSubject
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownChannel, m.Channel)
+	}
+	return nil
+}
+
+// isPhoneNumber accepts 7 to 15 digits with an optional leading + and common
+// separators.
+func isPhoneNumber(s string) bool {
+	digits := 0
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return false
+		}
+	}
+	return digits >= 7 && digits <= 15
+}
+
+// ChannelOrDefault returns the message channel, treating an empty value as SMS.
+func (m Message) ChannelOrDefault() MessageChannel {
+	if m.Channel == "" {
+		return MessageChannelSMS
+	}
+	return m.Channel
+}
+
+// Tags is stored as a JSONB array of strings.
+type Tags []string
+
+func (t Tags) Value() (driver.Value, error) {
+	return jsonValue(t, "[]")
+}
+
+func (t *Tags) Scan(src any) error {
+	return scanJSON(src, t)
+}
+
+type ProviderAttempt struct {
+	Provider    string    `json:"provider"`
+	Error       string    `json:"error"`
+	AttemptedAt time.Time `json:"attemptedAt"`
+}
+
+// ProviderAttempts is stored as a JSONB array.
+type ProviderAttempts []ProviderAttempt
+
+func (a ProviderAttempts) Value() (driver.Value, error) {
+	return jsonValue(a, "[]")
+}
+
+func (a *ProviderAttempts) Scan(src any) error {
+	return scanJSON(src, a)
+}
+
+// MessageFilter narrows message listings and event streams. Empty fields
+// match every message.
+type MessageFilter struct {
+	Channel  MessageChannel
+	Tenant   string
+	Provider string
+}
+
+func (f MessageFilter) IsZero() bool {
+	return f == MessageFilter{}
+}
+
+// MessageBacklog summarizes the pending messages that still have retries left.
+type MessageBacklog struct {
+	Pending         int64        `db:"pending"`
+	OldestCreatedAt sql.NullTime `db:"oldest_created_at"`
 }
 
 type MessageRepository interface {
+	// Create stores a new message and sets its ID.
+	Create(ctx context.Context, message *Message) error
 	Update(ctx context.Context, message Message) error
 	GetAll(ctx context.Context) ([]Message, error)
-	GetAllDue(ctx context.Context) ([]Message, error)
-	FindDue(ctx context.Context, limit uint) ([]Message, error)
+	// FindDue claims and returns up to limit pending messages created before
+	// createdBefore that have retries left and are not claimed by another
+	// dispatcher, oldest first.
+	FindDue(ctx context.Context, createdBefore time.Time, limit uint) ([]Message, error)
+	// FindDueByID claims and returns the message if it is still due and not
+	// claimed, or ErrMessageNotDue.
+	FindDueByID(ctx context.Context, id int64) (Message, error)
+	// Update and IncrementRetry also release the message's claim.
 	IncrementRetry(ctx context.Context, id int64, attemptTime time.Time) error
-	ListByStatus(ctx context.Context, status string, limit, offset uint) ([]Message, error)
+	// Release drops the claims on messages that were not sent, so the next
+	// dispatch round can pick them up again.
+	Release(ctx context.Context, ids ...int64) error
+	ListByStatus(ctx context.Context, status string, filter MessageFilter, limit, offset uint) ([]Message, error)
+	Backlog(ctx context.Context) (MessageBacklog, error)
 }
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"time"
+)
+
+const (
+	EventMessageCreated = "message.created"
+	EventMessageSent    = "message.sent"
+	EventMessageFailed  = "message.failed"
+	// EventMessageDelivered is reserved for provider delivery receipts.
+	EventMessageDelivered = "message.delivered"
+)
+
+// MessageEventTypes lists every message event type.
+var MessageEventTypes = []string{
+	EventMessageCreated,
+	EventMessageSent,
+	EventMessageFailed,
+	EventMessageDelivered,
+}
+
+// OutboxEvent is a domain event stored in the same transaction as the state
+// change it describes and published later by the outbox relay.
+type OutboxEvent struct {
+	ID          int64          `db:"id"`
+	MessageID   int64          `db:"message_id"`
+	EventType   string         `db:"event_type"`
+	Payload     EventPayload   `db:"payload"`
+	CreatedAt   time.Time      `db:"created_at"`
+	PublishedAt sql.NullTime   `db:"published_at"`
+	Attempts    int            `db:"attempts"`
+	LastError   sql.NullString `db:"last_error"`
+	// DeadLetteredAt is set once the relay gives up on the event. Dead-lettered
+	// events are kept for inspection but no longer published.
+	DeadLetteredAt sql.NullTime `db:"dead_lettered_at"`
+}
+
+// EventPayload is a raw JSON document stored as JSONB.
+type EventPayload []byte
+
+func (p EventPayload) Value() (driver.Value, error) {
+	if len(p) == 0 {
+		return "{}", nil
+	}
+	return string(p), nil
+}
+
+func (p *EventPayload) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*p = nil
+	case []byte:
+		*p = append(EventPayload(nil), v...)
+	case string:
+		*p = EventPayload(v)
+	}
+	return nil
+}
+
+func (p EventPayload) MarshalJSON() ([]byte, error) {
+	if len(p) == 0 {
+		return []byte("{}"), nil
+	}
+	return p, nil
+}
+
+func (p *EventPayload) UnmarshalJSON(data []byte) error {
+	*p = append((*p)[:0], data...)
+	return nil
+}
+
+// MessageStatusEvent is the payload of message status events.
+type MessageStatusEvent struct {
+	MessageID    int64          `json:"messageId"`
+	Status       MessageStatus  `json:"status"`
+	Channel      MessageChannel `json:"channel"`
+	Recipient    string         `json:"recipient"`
+	Tenant       string         `json:"tenant,omitempty"`
+	Provider     string         `json:"provider,omitempty"`
+	ResponseID   string         `json:"responseId,omitempty"`
+	ResponseCode int64          `json:"responseCode,omitempty"`
+	Error        string         `json:"error,omitempty"`
+	OccurredAt   time.Time      `json:"occurredAt"`
+}
+
+// Matches reports whether the event concerns a message selected by f.
+func (f MessageFilter) Matches(e MessageStatusEvent) bool {
+	return (f.Channel == "" || f.Channel == e.Channel) &&
+		(f.Tenant == "" || f.Tenant == e.Tenant) &&
+		(f.Provider == "" || f.Provider == e.Provider)
+}
+
+type OutboxRepository interface {
+	Create(ctx context.Context, event *OutboxEvent) error
+	// TryLock takes a transaction scoped lock so only one relay publishes at
+	// a time. It must be called inside a transaction.
+	TryLock(ctx context.Context) (bool, error)
+	// ListUnpublished returns unpublished events that are not dead-lettered,
+	// in insertion order.
+	ListUnpublished(ctx context.Context, limit uint) ([]OutboxEvent, error)
+	// ListAfter returns events with an ID greater than afterID in insertion
+	// order, published or not.
+	ListAfter(ctx context.Context, afterID int64, limit uint) ([]OutboxEvent, error)
+	MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error
+	MarkFailed(ctx context.Context, id int64, reason string) error
+	// MarkDeadLettered records the last failure and stops publishing the event.
+	MarkDeadLettered(ctx context.Context, id int64, reason string, at time.Time) error
+	// DeletePublished removes events published before the given time and
+	// returns how many were deleted.
+	DeletePublished(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+var ErrRoutingRuleNotFound = errors.New("routing rule not found")
+
+// RoutingRule sends messages matching Match to one of Targets. Rules are
+// evaluated by ascending Position and the first enabled match wins.
+type RoutingRule struct {
+	ID        int64        `db:"id"`
+	Name      string       `db:"name"`
+	Position  int          `db:"position"`
+	Enabled   bool         `db:"enabled"`
+	Match     RouteMatch   `db:"match"`
+	Targets   RouteTargets `db:"targets"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt sql.NullTime `db:"updated_at"`
+}
+
+// RouteMatch lists the criteria a message must meet. Every non-empty field
+// must match; within a field any value matches. An empty RouteMatch matches
+// every message.
+type RouteMatch struct {
+	RecipientPrefixes []string          `json:"recipientPrefixes,omitempty"`
+	Countries         []string          `json:"countries,omitempty"`
+	Priorities        []MessagePriority `json:"priorities,omitempty"`
+	Tenants           []string          `json:"tenants,omitempty"`
+	Tags              []string          `json:"tags,omitempty"`
+}
+
+func (m RouteMatch) Value() (driver.Value, error) {
+	return jsonValue(m, "{}")
+}
+
+func (m *RouteMatch) Scan(src any) error {
+	return scanJSON(src, m)
+}
+
+// RouteTarget is a provider with a relative weight. Traffic is split between
+// the targets of a rule in proportion to their weights.
+type RouteTarget struct {
+	Provider string `json:"provider"`
+	Weight   int    `json:"weight"`
+}
+
+type RouteTargets []RouteTarget
+
+func (t RouteTargets) Value() (driver.Value, error) {
+	return jsonValue(t, "[]")
+}
+
+func (t *RouteTargets) Scan(src any) error {
+	return scanJSON(src, t)
+}
+
+type RoutingRuleRepository interface {
+	List(ctx context.Context) ([]RoutingRule, error)
+	Get(ctx context.Context, id int64) (RoutingRule, error)
+	Create(ctx context.Context, rule *RoutingRule) error
+	Update(ctx context.Context, rule RoutingRule) error
+	Delete(ctx context.Context, id int64) error
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Role grants access to a class of routes. Roles are ordered: each role
+// includes the permissions of the roles below it.
+type Role string
+
+const (
+	// RolePublic marks routes that need no credentials.
+	RolePublic   Role = "public"
+	RoleReader   Role = "reader"
+	RoleOperator Role = "operator"
+	RoleAdmin    Role = "admin"
+)
+
+var roleRank = map[Role]int{
+	RoleReader:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+var (
+	ErrMissingCredentials = errors.New("missing credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if role == RolePublic {
+		return role, nil
+	}
+	if _, ok := roleRank[role]; !ok {
+		return "", fmt.Errorf("unknown role %q", s)
+	}
+	return role, nil
+}
+
+// Includes reports whether r grants the permissions of required.
+func (r Role) Includes(required Role) bool {
+	if required == RolePublic {
+		return true
+	}
+	rank, ok := roleRank[r]
+	return ok && rank >= roleRank[required]
+}
+
+// Principal is the authenticated caller.
+type Principal struct {
+	Subject string
+	Method  string
+	Roles   []Role
+}
+
+// Has reports whether any of the principal's roles grants required.
+func (p Principal) Has(required Role) bool {
+	for _, role := range p.Roles {
+		if role.Includes(required) {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the caller authenticated for the request, if
+// any.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// APIKey is a static key granting one role.
+type APIKey struct {
+	Name string
+	Key  string
+	Role Role
+}
+
+// Authenticator identifies callers by an X-API-Key header or a JWT bearer
+// token.
+type Authenticator struct {
+	apiKeys map[[sha256.Size]byte]Principal
+	jwt     *jwtVerifier
+}
+
+// NewAuthenticator accepts the given API keys and, when jwtConfig is not nil,
+// bearer tokens verified against it.
+func NewAuthenticator(apiKeys []APIKey, jwtConfig *JWTConfig) (*Authenticator, error) {
+	a := &Authenticator{apiKeys: make(map[[sha256.Size]byte]Principal, len(apiKeys))}
+
+	for _, key := range apiKeys {
+		if key.Key == "" {
+			return nil, fmt.Errorf("api key %s: empty key", key.Name)
+		}
+		if _, err := ParseRole(string(key.Role)); err != nil || key.Role == RolePublic {
+			return nil, fmt.Errorf("api key %s: invalid role %q", key.Name, key.Role)
+		}
+		// Keys are looked up by hash so the lookup does not leak timing
+		// information about the stored keys.
+		a.apiKeys[sha256.Sum256([]byte(key.Key))] = Principal{
+			Subject: key.Name,
+			Method:  MethodAPIKey,
+			Roles:   []Role{key.Role},
+		}
+	}
+
+	if jwtConfig != nil {
+		verifier, err := newJWTVerifier(*jwtConfig)
+		if err != nil {
+			return nil, err
+		}
+		a.jwt = verifier
+	}
+
+	return a, nil
+}
+
+// Authenticate returns the caller of r. It returns ErrMissingCredentials when
+// r carries none and ErrInvalidCredentials when they are not accepted.
+func (a *Authenticator) Authenticate(r *http.Request) (Principal, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		principal, ok := a.apiKeys[sha256.Sum256([]byte(key))]
+		if !ok {
+			return Principal{}, ErrInvalidCredentials
+		}
+		return principal, nil
+	}
+
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return Principal{}, ErrMissingCredentials
+	}
+
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || a.jwt == nil {
+		return Principal{}, ErrInvalidCredentials
+	}
+
+	principal, err := a.jwt.verify(strings.TrimSpace(token))
+	if err != nil {
+		return Principal{}, errors.Join(ErrInvalidCredentials, err)
+	}
+	return principal, nil
+}
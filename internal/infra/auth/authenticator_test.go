@@ -0,0 +1,256 @@
+//go:build unit
+
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/muratdemir0/gopulse-messages/internal/infra/auth"
+)
+
+func TestRole_Includes(t *testing.T) {
+	assert.True(t, auth.RoleAdmin.Includes(auth.RoleOperator))
+	assert.True(t, auth.RoleOperator.Includes(auth.RoleReader))
+	assert.True(t, auth.RoleReader.Includes(auth.RolePublic))
+	assert.False(t, auth.RoleReader.Includes(auth.RoleOperator))
+	assert.False(t, auth.RoleOperator.Includes(auth.RoleAdmin))
+	assert.False(t, auth.Role("unknown").Includes(auth.RoleReader))
+}
+
+func TestAuthenticator_APIKey(t *testing.T) {
+	authenticator, err := auth.NewAuthenticator([]auth.APIKey{
+		{Name: "ops", Key: "ops-key", Role: auth.RoleOperator},
+	}, nil)
+	require.NoError(t, err)
+
+	t.Run("Given a known key, it should return its principal", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(requestWith(auth.APIKeyHeader, "ops-key"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "ops", principal.Subject)
+		assert.Equal(t, auth.MethodAPIKey, principal.Method)
+		assert.True(t, principal.Has(auth.RoleReader))
+		assert.False(t, principal.Has(auth.RoleAdmin))
+	})
+
+	t.Run("Given an unknown key, it should fail", func(t *testing.T) {
+		_, err := authenticator.Authenticate(requestWith(auth.APIKeyHeader, "other-key"))
+
+		assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+	})
+
+	t.Run("Given no credentials, it should report them missing", func(t *testing.T) {
+		_, err := authenticator.Authenticate(httptest.NewRequest(http.MethodGet, "/messages", nil))
+
+		assert.ErrorIs(t, err, auth.ErrMissingCredentials)
+	})
+
+	t.Run("Given a bearer token without JWT configured, it should fail", func(t *testing.T) {
+		_, err := authenticator.Authenticate(requestWith("Authorization", "Bearer token"))
+
+		assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+	})
+
+	t.Run("Given a key with an unknown role, it should not build", func(t *testing.T) {
+		_, err := auth.NewAuthenticator([]auth.APIKey{{Name: "bad", Key: "k", Role: "root"}}, nil)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestAuthenticator_HS256(t *testing.T) {
+	authenticator, err := auth.NewAuthenticator(nil, &auth.JWTConfig{
+		Algorithm: auth.AlgorithmHS256,
+		Secret:    "secret",
+		Issuer:    "gopulse",
+	})
+	require.NoError(t, err)
+
+	sign := func(claims jwt.MapClaims, secret string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("Given a valid token, it should return the subject and roles", func(t *testing.T) {
+		token := sign(jwt.MapClaims{
+			"sub":   "alice",
+			"iss":   "gopulse",
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"roles": []string{"reader", "operator", "unknown"},
+		}, "secret")
+
+		principal, err := authenticator.Authenticate(requestWith("Authorization", "Bearer "+token))
+
+		require.NoError(t, err)
+		assert.Equal(t, "alice", principal.Subject)
+		assert.Equal(t, auth.MethodJWT, principal.Method)
+		assert.Equal(t, []auth.Role{auth.RoleReader, auth.RoleOperator}, principal.Roles)
+	})
+
+	t.Run("Given roles as a space separated string, they should be split", func(t *testing.T) {
+		token := sign(jwt.MapClaims{
+			"iss":   "gopulse",
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"roles": "reader admin",
+		}, "secret")
+
+		principal, err := authenticator.Authenticate(requestWith("Authorization", "Bearer "+token))
+
+		require.NoError(t, err)
+		assert.True(t, principal.Has(auth.RoleAdmin))
+	})
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		secret string
+	}{
+		{
+			name:   "Given a token signed with another secret, it should fail",
+			claims: jwt.MapClaims{"iss": "gopulse", "exp": time.Now().Add(time.Minute).Unix()},
+			secret: "other",
+		},
+		{
+			name:   "Given an expired token, it should fail",
+			claims: jwt.MapClaims{"iss": "gopulse", "exp": time.Now().Add(-time.Minute).Unix()},
+			secret: "secret",
+		},
+		{
+			name:   "Given a token without expiry, it should fail",
+			claims: jwt.MapClaims{"iss": "gopulse"},
+			secret: "secret",
+		},
+		{
+			name:   "Given a token from another issuer, it should fail",
+			claims: jwt.MapClaims{"iss": "someone", "exp": time.Now().Add(time.Minute).Unix()},
+			secret: "secret",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authenticator.Authenticate(requestWith("Authorization", "Bearer "+sign(tt.claims, tt.secret)))
+
+			assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+		})
+	}
+
+	t.Run("Given an unsigned token, it should fail", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+			"iss": "gopulse",
+			"exp": time.Now().Add(time.Minute).Unix(),
+		}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		require.NoError(t, err)
+
+		_, err = authenticator.Authenticate(requestWith("Authorization", "Bearer "+token))
+
+		assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+	})
+}
+
+func TestAuthenticator_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	sign := func(kid string, signer *rsa.PrivateKey) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"sub":   "svc",
+			"aud":   "gopulse-messages",
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"scope": "operator",
+		})
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(signer)
+		require.NoError(t, err)
+		return signed
+	}
+
+	t.Run("Given a JWKS file, it should verify tokens by key ID", func(t *testing.T) {
+		authenticator, err := auth.NewAuthenticator(nil, &auth.JWTConfig{
+			Algorithm:  auth.AlgorithmRS256,
+			JWKSFile:   writeJWKS(t, "key-1", &key.PublicKey),
+			Audience:   "gopulse-messages",
+			RolesClaim: "scope",
+		})
+		require.NoError(t, err)
+
+		principal, err := authenticator.Authenticate(requestWith("Authorization", "Bearer "+sign("key-1", key)))
+		require.NoError(t, err)
+		assert.Equal(t, "svc", principal.Subject)
+		assert.True(t, principal.Has(auth.RoleOperator))
+
+		_, err = authenticator.Authenticate(requestWith("Authorization", "Bearer "+sign("key-2", key)))
+		assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+	})
+
+	t.Run("Given a PEM public key, it should reject tokens signed by another key", func(t *testing.T) {
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "public.pem")
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+		authenticator, err := auth.NewAuthenticator(nil, &auth.JWTConfig{
+			Algorithm:     auth.AlgorithmRS256,
+			PublicKeyFile: path,
+		})
+		require.NoError(t, err)
+
+		_, err = authenticator.Authenticate(requestWith("Authorization", "Bearer "+sign("", key)))
+		assert.NoError(t, err)
+
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		_, err = authenticator.Authenticate(requestWith("Authorization", "Bearer "+sign("", other)))
+		assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+	})
+
+	t.Run("Given RS256 without a key source, it should not build", func(t *testing.T) {
+		_, err := auth.NewAuthenticator(nil, &auth.JWTConfig{Algorithm: auth.AlgorithmRS256})
+
+		assert.Error(t, err)
+	})
+}
+
+func requestWith(header, value string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/messages", nil)
+	r.Header.Set(header, value)
+	return r
+}
+
+func writeJWKS(t *testing.T, kid string, key *rsa.PublicKey) string {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+
+	DefaultRolesClaim = "roles"
+)
+
+// JWTConfig verifies bearer tokens. HS256 tokens are checked against Secret;
+// RS256 tokens against the key in PublicKeyFile (PEM) or the key matching
+// their kid in JWKSFile. Issuer and Audience are enforced when set. The roles
+// claim holds a list of roles or a space separated string.
+type JWTConfig struct {
+	Algorithm     string
+	Secret        string
+	PublicKeyFile string
+	JWKSFile      string
+	Issuer        string
+	Audience      string
+	RolesClaim    string
+}
+
+type jwtVerifier struct {
+	parser     *jwt.Parser
+	hmacKey    []byte
+	rsaKeys    map[string]*rsa.PublicKey
+	rolesClaim string
+}
+
+func newJWTVerifier(cfg JWTConfig) (*jwtVerifier, error) {
+	v := &jwtVerifier{rolesClaim: cfg.RolesClaim}
+	if v.rolesClaim == "" {
+		v.rolesClaim = DefaultRolesClaim
+	}
+
+	switch cfg.Algorithm {
+	case AlgorithmHS256:
+		if cfg.Secret == "" {
+			return nil, errors.New("jwt: HS256 requires a secret")
+		}
+		v.hmacKey = []byte(cfg.Secret)
+	case AlgorithmRS256:
+		keys, err := loadRSAKeys(cfg.PublicKeyFile, cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		v.rsaKeys = keys
+	default:
+		return nil, fmt.Errorf("jwt: unsupported algorithm %q", cfg.Algorithm)
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{cfg.Algorithm}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(opts...)
+
+	return v, nil
+}
+
+func (v *jwtVerifier) verify(tokenString string) (Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(tokenString, claims, v.key); err != nil {
+		return Principal{}, err
+	}
+
+	subject, _ := claims.GetSubject()
+	return Principal{
+		Subject: subject,
+		Method:  MethodJWT,
+		Roles:   rolesFromClaim(claims[v.rolesClaim]),
+	}, nil
+}
+
+func (v *jwtVerifier) key(token *jwt.Token) (any, error) {
+	if v.hmacKey != nil {
+		return v.hmacKey, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	if key, ok := v.rsaKeys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(v.rsaKeys) == 1 {
+		for _, key := range v.rsaKeys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("jwt: unknown key id %q", kid)
+}
+
+// rolesFromClaim reads known roles from a list or space separated string,
+// ignoring unknown ones.
+func rolesFromClaim(claim any) []Role {
+	var names []string
+	switch value := claim.(type) {
+	case string:
+		names = strings.Fields(value)
+	case []any:
+		for _, item := range value {
+			if name, ok := item.(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+
+	var roles []Role
+	for _, name := range names {
+		if role, err := ParseRole(name); err == nil && role != RolePublic {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+func loadRSAKeys(publicKeyFile, jwksFile string) (map[string]*rsa.PublicKey, error) {
+	switch {
+	case publicKeyFile != "":
+		data, err := os.ReadFile(publicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: failed to read public key: %w", err)
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: failed to parse public key: %w", err)
+		}
+		return map[string]*rsa.PublicKey{"": key}, nil
+	case jwksFile != "":
+		data, err := os.ReadFile(jwksFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: failed to read JWKS: %w", err)
+		}
+		return parseJWKS(data)
+	default:
+		return nil, errors.New("jwt: RS256 requires a public key file or a JWKS file")
+	}
+}
+
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// parseJWKS returns the RSA signing keys of a JWK set by key ID.
+func parseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("jwt: failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: key %s: invalid modulus: %w", jwk.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: key %s: invalid exponent: %w", jwk.Kid, err)
+		}
+
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("jwt: JWKS has no RSA signing keys")
+	}
+	return keys, nil
+}
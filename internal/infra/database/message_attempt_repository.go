@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/db"
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+)
+
+const messageAttemptsTable = "message_attempts"
+
+type MessageAttemptRepository struct {
+	db *db.Client
+}
+
+func NewMessageAttemptRepository(db *db.Client) *MessageAttemptRepository {
+	return &MessageAttemptRepository{db: db}
+}
+
+func (r *MessageAttemptRepository) Create(ctx context.Context, attempt *domain.MessageAttempt) error {
+	ds := goqu.Insert(messageAttemptsTable).Rows(goqu.Record{
+		"message_id":    attempt.MessageID,
+		"provider":      attempt.Provider,
+		"status_code":   attempt.StatusCode,
+		"latency_ms":    attempt.LatencyMs,
+		"response_body": attempt.ResponseBody,
+		"error_class":   attempt.ErrorClass,
+		"error":         attempt.Error,
+		"retry_attempt": attempt.RetryAttempt,
+	})
+
+	result, err := r.db.Insert(ctx, ds)
+	if err != nil {
+		return fmt.Errorf("error creating attempt for message id %d: %w", attempt.MessageID, err)
+	}
+
+	attempt.ID, _ = result.LastInsertId()
+	return nil
+}
+
+func (r *MessageAttemptRepository) ListByMessage(ctx context.Context, messageID int64) ([]domain.MessageAttempt, error) {
+	ds := goqu.From(messageAttemptsTable).
+		Where(goqu.Ex{"message_id": messageID}).
+		Order(goqu.C("id").Asc())
+
+	var attempts []domain.MessageAttempt
+	if err := r.db.Select(ctx, &attempts, ds); err != nil {
+		return nil, fmt.Errorf("error listing attempts for message id %d: %w", messageID, err)
+	}
+	return attempts, nil
+}
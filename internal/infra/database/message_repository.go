@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/muratdemir0/gopulse-messages/internal/adapters/db"
 	"github.com/muratdemir0/gopulse-messages/internal/domain"
 )
@@ -14,8 +16,18 @@ import (
 const (
 	tableName     = "messages"
 	maxRetryCount = 5
+
+	// claimLease bounds how long a claimed message is hidden from other
+	// dispatchers. It outlasts sending a dispatch batch with retries and
+	// failover, and lets a message claimed by a crashed replica be picked up
+	// again.
+	claimLease = 5 * time.Minute
 )
 
+// MessagesCreatedChannel is the NOTIFY channel the messages insert trigger
+// publishes new message IDs on.
+const MessagesCreatedChannel = "messages_created"
+
 var (
 	ErrMessageNotFound = fmt.Errorf("message not found or not in pending state")
 )
@@ -30,12 +42,16 @@ func NewMessageRepository(db *db.Client) *MessageRepository {
 
 func (r *MessageRepository) Update(ctx context.Context, message domain.Message) error {
 	record := goqu.Record{
-		"status":        message.Status,
-		"sent_at":       message.SentAt,
-		"response_id":   message.ResponseID,
-		"error_message": message.ErrorMessage,
-		"retry_count":   message.RetryCount,
-		"updated_at":    sql.NullTime{Time: time.Now(), Valid: true},
+		"status":          message.Status,
+		"sent_at":         message.SentAt,
+		"response_id":     message.ResponseID,
+		"response_code":   message.ResponseCode,
+		"error_message":   message.ErrorMessage,
+		"retry_count":     message.RetryCount,
+		"provider":        message.Provider,
+		"failed_attempts": message.FailedAttempts,
+		"updated_at":      sql.NullTime{Time: time.Now(), Valid: true},
+		"claimed_until":   nil,
 	}
 
 	ds := goqu.Update(tableName).
@@ -70,44 +86,104 @@ func (r *MessageRepository) GetAll(ctx context.Context) ([]domain.Message, error
 	return messages, nil
 }
 
-func (r *MessageRepository) GetAllDue(ctx context.Context) ([]domain.Message, error) {
-	ds := goqu.From(tableName).
+func (r *MessageRepository) FindDue(ctx context.Context, createdBefore time.Time, limit uint) ([]domain.Message, error) {
+	messages, err := r.claim(ctx, dueMessages(createdBefore).Limit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("error finding due messages: %w", err)
+	}
+	return messages, nil
+}
+
+func (r *MessageRepository) FindDueByID(ctx context.Context, id int64) (domain.Message, error) {
+	messages, err := r.claim(ctx, dueMessages(time.Now()).Where(goqu.C("id").Eq(id)))
+	if err != nil {
+		return domain.Message{}, fmt.Errorf("error finding due message id %d: %w", id, err)
+	}
+	if len(messages) == 0 {
+		return domain.Message{}, domain.ErrMessageNotDue
+	}
+	return messages[0], nil
+}
+
+// dueMessages selects the IDs of unclaimed pending messages with retries
+// left, oldest first. Rows locked by a concurrent claim are skipped.
+func dueMessages(createdBefore time.Time) *goqu.SelectDataset {
+	return goqu.From(tableName).
+		Select("id").
 		Where(
 			goqu.C("status").Eq(domain.MessageStatusPending),
 			goqu.C("retry_count").Lt(maxRetryCount),
+			goqu.C("created_at").Lte(createdBefore),
+			goqu.Or(
+				goqu.C("claimed_until").IsNull(),
+				goqu.C("claimed_until").Lt(goqu.L("NOW()")),
+			),
 		).
-		Order(goqu.C("created_at").Asc())
+		Order(goqu.C("created_at").Asc()).
+		ForUpdate(exp.SkipLocked)
+}
+
+// claim marks the messages selected by ids as claimed for claimLease and
+// returns them oldest first. Concurrent dispatchers never claim the same
+// message, so replicas woken by the same insert do not send it twice.
+func (r *MessageRepository) claim(ctx context.Context, ids *goqu.SelectDataset) ([]domain.Message, error) {
+	ds := goqu.Update(tableName).
+		Set(goqu.Record{
+			"claimed_until": goqu.L("NOW() + ? * INTERVAL '1 second'", int(claimLease.Seconds())),
+		}).
+		Where(goqu.C("id").In(ids)).
+		Returning(goqu.Star())
 
 	var messages []domain.Message
-	err := r.db.Select(ctx, &messages, ds)
-	if err != nil {
-		return nil, fmt.Errorf("error getting all due messages: %w", err)
+	if err := r.db.UpdateReturning(ctx, &messages, ds); err != nil {
+		return nil, err
 	}
+
+	slices.SortFunc(messages, func(a, b domain.Message) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
 	return messages, nil
 }
 
-func (r *MessageRepository) FindDue(ctx context.Context, limit uint) ([]domain.Message, error) {
+func (r *MessageRepository) Release(ctx context.Context, ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	ds := goqu.Update(tableName).
+		Set(goqu.Record{"claimed_until": nil}).
+		Where(goqu.C("id").In(ids))
+
+	if _, err := r.db.Update(ctx, ds); err != nil {
+		return fmt.Errorf("error releasing messages %v: %w", ids, err)
+	}
+	return nil
+}
+
+func (r *MessageRepository) Backlog(ctx context.Context) (domain.MessageBacklog, error) {
 	ds := goqu.From(tableName).
+		Select(
+			goqu.COUNT("*").As("pending"),
+			goqu.MIN("created_at").As("oldest_created_at"),
+		).
 		Where(
 			goqu.C("status").Eq(domain.MessageStatusPending),
 			goqu.C("retry_count").Lt(maxRetryCount),
-		).
-		Order(goqu.C("created_at").Asc()).
-		Limit(limit)
+		)
 
-	var messages []domain.Message
-	err := r.db.Select(ctx, &messages, ds)
-	if err != nil {
-		return nil, fmt.Errorf("error finding due messages: %w", err)
+	var backlog domain.MessageBacklog
+	if err := r.db.QueryRow(ctx, &backlog, ds); err != nil {
+		return domain.MessageBacklog{}, fmt.Errorf("error reading message backlog: %w", err)
 	}
-	return messages, nil
+	return backlog, nil
 }
 
 func (r *MessageRepository) IncrementRetry(ctx context.Context, id int64, attemptTime time.Time) error {
 	ds := goqu.Update(tableName).
 		Set(
 			goqu.Record{
-				"retry_count": goqu.L("retry_count + 1"),
+				"retry_count":   goqu.L("retry_count + 1"),
+				"claimed_until": nil,
 			},
 		).
 		Where(goqu.Ex{"id": id})
@@ -119,9 +195,20 @@ func (r *MessageRepository) IncrementRetry(ctx context.Context, id int64, attemp
 	return nil
 }
 
-func (r *MessageRepository) ListByStatus(ctx context.Context, status string, limit, offset uint) ([]domain.Message, error) {
+func (r *MessageRepository) ListByStatus(ctx context.Context, status string, filter domain.MessageFilter, limit, offset uint) ([]domain.Message, error) {
+	where := []goqu.Expression{goqu.C("status").Eq(status)}
+	if filter.Channel != "" {
+		where = append(where, goqu.C("channel").Eq(filter.Channel))
+	}
+	if filter.Tenant != "" {
+		where = append(where, goqu.C("tenant").Eq(filter.Tenant))
+	}
+	if filter.Provider != "" {
+		where = append(where, goqu.C("provider").Eq(filter.Provider))
+	}
+
 	ds := goqu.From(tableName).
-		Where(goqu.C("status").Eq(status)).
+		Where(where...).
 		Order(goqu.C("created_at").Desc()).
 		Limit(limit).
 		Offset(offset)
@@ -135,18 +222,32 @@ func (r *MessageRepository) ListByStatus(ctx context.Context, status string, lim
 }
 
 func (r *MessageRepository) Create(ctx context.Context, message *domain.Message) error {
+	priority := message.Priority
+	if priority == "" {
+		priority = domain.MessagePriorityNormal
+	}
+
 	record := goqu.Record{
-		"recipient": message.Recipient,
-		"content":   message.Content,
-		"status":    message.Status,
+		"recipient":    message.Recipient,
+		"content":      message.Content,
+		"status":       message.Status,
+		"priority":     priority,
+		"tenant":       message.Tenant,
+		"tags":         message.Tags,
+		"channel":      message.ChannelOrDefault(),
+		"subject":      message.Subject,
+		"html_content": message.HTMLContent,
+		"trace_id":     message.TraceID,
+		"span_id":      message.SpanID,
 	}
 
 	ds := goqu.Insert(tableName).Rows(record)
 
-	_, err := r.db.Insert(ctx, ds)
+	result, err := r.db.Insert(ctx, ds)
 	if err != nil {
 		return fmt.Errorf("error creating message: %w", err)
 	}
 
+	message.ID, _ = result.LastInsertId()
 	return nil
 }
@@ -7,6 +7,8 @@ import (
 	"database/sql"
 	"log"
 	"os"
+	"path/filepath"
+	"sort"
 	"testing"
 	"time"
 
@@ -59,14 +61,22 @@ func TestMain(m *testing.M) {
 		}
 	}()
 
-	migration, err := os.ReadFile("../../../migrations/000001_message.up.sql")
+	migrations, err := filepath.Glob("../../../migrations/*.up.sql")
 	if err != nil {
-		log.Fatalf("failed to read migration file: %s", err)
+		log.Fatalf("failed to list migration files: %s", err)
 	}
+	sort.Strings(migrations)
 
-	_, err = dbClient.Goqu.Exec(string(migration))
-	if err != nil {
-		log.Fatalf("failed to apply migration: %s", err)
+	for _, path := range migrations {
+		migration, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("failed to read migration file: %s", err)
+		}
+
+		_, err = dbClient.Goqu.Exec(string(migration))
+		if err != nil {
+			log.Fatalf("failed to apply migration %s: %s", path, err)
+		}
 	}
 
 	messageRepo = database.NewMessageRepository(dbClient)
@@ -151,12 +161,87 @@ func TestMessageRepository_FindDue(t *testing.T) {
 	createMessage(t, &domain.Message{Recipient: "3", Content: "3", Status: domain.MessageStatusFailed})
 	createMessage(t, &domain.Message{Recipient: "4", Content: "4", Status: domain.MessageStatusPending})
 
-	messages, err := messageRepo.FindDue(ctx, 10)
+	messages, err := messageRepo.FindDue(ctx, time.Now(), 10)
 	assert.NoError(t, err)
 	assert.Len(t, messages, 2)
 	for _, msg := range messages {
 		assert.Equal(t, domain.MessageStatusPending, msg.Status)
 	}
+
+	messages, err = messageRepo.FindDue(ctx, time.Now().Add(-time.Hour), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, messages, "messages created after createdBefore are left to the queue")
+}
+
+func TestMessageRepository_FindDueByID(t *testing.T) {
+	defer cleanup(t)
+	ctx := context.Background()
+
+	pending := createMessage(t, &domain.Message{Recipient: "1", Content: "1", Status: domain.MessageStatusPending})
+	sent := createMessage(t, &domain.Message{Recipient: "2", Content: "2", Status: domain.MessageStatusSent})
+
+	message, err := messageRepo.FindDueByID(ctx, pending)
+	assert.NoError(t, err)
+	assert.Equal(t, pending, message.ID)
+
+	_, err = messageRepo.FindDueByID(ctx, sent)
+	assert.ErrorIs(t, err, domain.ErrMessageNotDue)
+}
+
+func TestMessageRepository_Claim(t *testing.T) {
+	defer cleanup(t)
+	ctx := context.Background()
+
+	first := createMessage(t, &domain.Message{Recipient: "1", Content: "1", Status: domain.MessageStatusPending})
+	second := createMessage(t, &domain.Message{Recipient: "2", Content: "2", Status: domain.MessageStatusPending})
+
+	claimed, err := messageRepo.FindDue(ctx, time.Now(), 1)
+	assert.NoError(t, err)
+	if assert.Len(t, claimed, 1) {
+		assert.Equal(t, first, claimed[0].ID)
+		assert.True(t, claimed[0].ClaimedUntil.Valid)
+	}
+
+	claimed, err = messageRepo.FindDue(ctx, time.Now(), 10)
+	assert.NoError(t, err)
+	if assert.Len(t, claimed, 1, "a claimed message is not handed out again") {
+		assert.Equal(t, second, claimed[0].ID)
+	}
+
+	_, err = messageRepo.FindDueByID(ctx, first)
+	assert.ErrorIs(t, err, domain.ErrMessageNotDue)
+
+	assert.NoError(t, messageRepo.Release(ctx, first))
+	message, err := messageRepo.FindDueByID(ctx, first)
+	assert.NoError(t, err)
+	assert.Equal(t, first, message.ID)
+
+	assert.NoError(t, messageRepo.IncrementRetry(ctx, second, time.Now()))
+	claimed, err = messageRepo.FindDue(ctx, time.Now(), 10)
+	assert.NoError(t, err)
+	if assert.Len(t, claimed, 1, "a retried message is released for the next round") {
+		assert.Equal(t, second, claimed[0].ID)
+	}
+}
+
+func TestMessageRepository_Backlog(t *testing.T) {
+	defer cleanup(t)
+	ctx := context.Background()
+
+	backlog, err := messageRepo.Backlog(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, backlog.Pending)
+	assert.False(t, backlog.OldestCreatedAt.Valid)
+
+	createMessage(t, &domain.Message{Recipient: "1", Content: "1", Status: domain.MessageStatusPending})
+	createMessage(t, &domain.Message{Recipient: "2", Content: "2", Status: domain.MessageStatusSent})
+	createMessage(t, &domain.Message{Recipient: "3", Content: "3", Status: domain.MessageStatusPending})
+
+	backlog, err = messageRepo.Backlog(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), backlog.Pending)
+	assert.True(t, backlog.OldestCreatedAt.Valid)
+	assert.WithinDuration(t, time.Now(), backlog.OldestCreatedAt.Time, time.Minute)
 }
 
 func TestMessageRepository_IncrementRetry(t *testing.T) {
@@ -189,19 +274,29 @@ func TestMessageRepository_ListByStatus(t *testing.T) {
 	createMessage(t, &domain.Message{Recipient: "4", Content: "4", Status: domain.MessageStatusPending})
 
 	t.Run("list pending", func(t *testing.T) {
-		messages, err := messageRepo.ListByStatus(ctx, string(domain.MessageStatusPending), 10, 0)
+		messages, err := messageRepo.ListByStatus(ctx, string(domain.MessageStatusPending), domain.MessageFilter{}, 10, 0)
 		assert.NoError(t, err)
 		assert.Len(t, messages, 2)
 	})
 
 	t.Run("list sent", func(t *testing.T) {
-		messages, err := messageRepo.ListByStatus(ctx, string(domain.MessageStatusSent), 10, 0)
+		messages, err := messageRepo.ListByStatus(ctx, string(domain.MessageStatusSent), domain.MessageFilter{}, 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 1)
+	})
+
+	t.Run("list with filter", func(t *testing.T) {
+		msg := &domain.Message{Recipient: "5", Content: "5", Status: domain.MessageStatusSent, Tenant: sql.NullString{String: "acme", Valid: true}}
+		assert.NoError(t, messageRepo.Create(ctx, msg))
+
+		messages, err := messageRepo.ListByStatus(ctx, string(domain.MessageStatusSent), domain.MessageFilter{Tenant: "acme"}, 10, 0)
 		assert.NoError(t, err)
 		assert.Len(t, messages, 1)
+		assert.Equal(t, msg.ID, messages[0].ID)
 	})
 
 	t.Run("list with limit and offset", func(t *testing.T) {
-		messages, err := messageRepo.ListByStatus(ctx, string(domain.MessageStatusPending), 1, 1)
+		messages, err := messageRepo.ListByStatus(ctx, string(domain.MessageStatusPending), domain.MessageFilter{}, 1, 1)
 		assert.NoError(t, err)
 		assert.Len(t, messages, 1)
 	})
@@ -215,6 +310,8 @@ func TestMessageRepository_Create(t *testing.T) {
 		Recipient: "1234567890",
 		Content:   "Hello, Create!",
 		Status:    domain.MessageStatusPending,
+		TraceID:   sql.NullString{String: "4bf92f3577b34da6a3ce929d0e0e4736", Valid: true},
+		SpanID:    sql.NullString{String: "00f067aa0ba902b7", Valid: true},
 	}
 
 	err := messageRepo.Create(ctx, msg)
@@ -232,5 +329,8 @@ func TestMessageRepository_Create(t *testing.T) {
 	assert.Equal(t, msg.Content, createdMsg.Content)
 	assert.Equal(t, msg.Status, createdMsg.Status)
 	assert.NotZero(t, createdMsg.ID)
+	assert.Equal(t, createdMsg.ID, msg.ID)
 	assert.False(t, createdMsg.CreatedAt.IsZero())
+	assert.Equal(t, msg.TraceID, createdMsg.TraceID)
+	assert.Equal(t, msg.SpanID, createdMsg.SpanID)
 }
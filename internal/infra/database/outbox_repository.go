@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/db"
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+)
+
+const (
+	outboxTable = "outbox_events"
+
+	// outboxLockKey identifies the advisory lock held by the active relay.
+	outboxLockKey = 0x6f7574626f78
+)
+
+type OutboxRepository struct {
+	db *db.Client
+}
+
+func NewOutboxRepository(db *db.Client) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+func (r *OutboxRepository) Create(ctx context.Context, event *domain.OutboxEvent) error {
+	ds := goqu.Insert(outboxTable).Rows(goqu.Record{
+		"message_id": event.MessageID,
+		"event_type": event.EventType,
+		"payload":    event.Payload,
+	})
+
+	result, err := r.db.Insert(ctx, ds)
+	if err != nil {
+		return fmt.Errorf("error creating outbox event for message id %d: %w", event.MessageID, err)
+	}
+
+	event.ID, _ = result.LastInsertId()
+	return nil
+}
+
+func (r *OutboxRepository) TryLock(ctx context.Context) (bool, error) {
+	ds := goqu.Select(goqu.Func("pg_try_advisory_xact_lock", outboxLockKey))
+
+	var locked bool
+	if err := r.db.QueryRow(ctx, &locked, ds); err != nil {
+		return false, fmt.Errorf("error acquiring outbox lock: %w", err)
+	}
+	return locked, nil
+}
+
+func (r *OutboxRepository) ListUnpublished(ctx context.Context, limit uint) ([]domain.OutboxEvent, error) {
+	ds := goqu.From(outboxTable).
+		Where(
+			goqu.C("published_at").IsNull(),
+			goqu.C("dead_lettered_at").IsNull(),
+		).
+		Order(goqu.C("id").Asc()).
+		Limit(limit)
+
+	var events []domain.OutboxEvent
+	if err := r.db.Select(ctx, &events, ds); err != nil {
+		return nil, fmt.Errorf("error listing unpublished outbox events: %w", err)
+	}
+	return events, nil
+}
+
+func (r *OutboxRepository) ListAfter(ctx context.Context, afterID int64, limit uint) ([]domain.OutboxEvent, error) {
+	ds := goqu.From(outboxTable).
+		Where(goqu.C("id").Gt(afterID)).
+		Order(goqu.C("id").Asc()).
+		Limit(limit)
+
+	var events []domain.OutboxEvent
+	if err := r.db.Select(ctx, &events, ds); err != nil {
+		return nil, fmt.Errorf("error listing outbox events after id %d: %w", afterID, err)
+	}
+	return events, nil
+}
+
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error {
+	ds := goqu.Update(outboxTable).
+		Set(goqu.Record{
+			"published_at": sql.NullTime{Time: publishedAt, Valid: true},
+			"attempts":     goqu.L("attempts + 1"),
+			"last_error":   nil,
+		}).
+		Where(goqu.Ex{"id": id})
+
+	if _, err := r.db.Update(ctx, ds); err != nil {
+		return fmt.Errorf("error marking outbox event id %d published: %w", id, err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, reason string) error {
+	ds := goqu.Update(outboxTable).
+		Set(goqu.Record{
+			"attempts":   goqu.L("attempts + 1"),
+			"last_error": reason,
+		}).
+		Where(goqu.Ex{"id": id})
+
+	if _, err := r.db.Update(ctx, ds); err != nil {
+		return fmt.Errorf("error marking outbox event id %d failed: %w", id, err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) MarkDeadLettered(ctx context.Context, id int64, reason string, at time.Time) error {
+	ds := goqu.Update(outboxTable).
+		Set(goqu.Record{
+			"attempts":         goqu.L("attempts + 1"),
+			"last_error":       reason,
+			"dead_lettered_at": sql.NullTime{Time: at, Valid: true},
+		}).
+		Where(goqu.Ex{"id": id})
+
+	if _, err := r.db.Update(ctx, ds); err != nil {
+		return fmt.Errorf("error dead-lettering outbox event id %d: %w", id, err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	ds := goqu.Delete(outboxTable).
+		Where(goqu.C("published_at").Lt(before))
+
+	result, err := r.db.Delete(ctx, ds)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting outbox events published before %s: %w", before.Format(time.RFC3339), err)
+	}
+	return result.RowsAffected()
+}
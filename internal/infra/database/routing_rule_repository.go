@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/db"
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+)
+
+const routingRulesTable = "routing_rules"
+
+type RoutingRuleRepository struct {
+	db *db.Client
+}
+
+func NewRoutingRuleRepository(db *db.Client) *RoutingRuleRepository {
+	return &RoutingRuleRepository{db: db}
+}
+
+func (r *RoutingRuleRepository) List(ctx context.Context) ([]domain.RoutingRule, error) {
+	ds := goqu.From(routingRulesTable).
+		Order(goqu.C("position").Asc(), goqu.C("id").Asc())
+
+	var rules []domain.RoutingRule
+	if err := r.db.Select(ctx, &rules, ds); err != nil {
+		return nil, fmt.Errorf("error listing routing rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (r *RoutingRuleRepository) Get(ctx context.Context, id int64) (domain.RoutingRule, error) {
+	ds := goqu.From(routingRulesTable).Where(goqu.Ex{"id": id})
+
+	var rule domain.RoutingRule
+	err := r.db.QueryRow(ctx, &rule, ds)
+	if errors.Is(err, db.ErrNoRows) {
+		return domain.RoutingRule{}, domain.ErrRoutingRuleNotFound
+	}
+	if err != nil {
+		return domain.RoutingRule{}, fmt.Errorf("error getting routing rule id %d: %w", id, err)
+	}
+	return rule, nil
+}
+
+func (r *RoutingRuleRepository) Create(ctx context.Context, rule *domain.RoutingRule) error {
+	ds := goqu.Insert(routingRulesTable).Rows(goqu.Record{
+		"name":     rule.Name,
+		"position": rule.Position,
+		"enabled":  rule.Enabled,
+		"match":    rule.Match,
+		"targets":  rule.Targets,
+	})
+
+	result, err := r.db.Insert(ctx, ds)
+	if err != nil {
+		return fmt.Errorf("error creating routing rule: %w", err)
+	}
+
+	rule.ID, _ = result.LastInsertId()
+	return nil
+}
+
+func (r *RoutingRuleRepository) Update(ctx context.Context, rule domain.RoutingRule) error {
+	ds := goqu.Update(routingRulesTable).
+		Set(goqu.Record{
+			"name":       rule.Name,
+			"position":   rule.Position,
+			"enabled":    rule.Enabled,
+			"match":      rule.Match,
+			"targets":    rule.Targets,
+			"updated_at": sql.NullTime{Time: time.Now(), Valid: true},
+		}).
+		Where(goqu.Ex{"id": rule.ID})
+
+	result, err := r.db.Update(ctx, ds)
+	if err != nil {
+		return fmt.Errorf("error updating routing rule id %d: %w", rule.ID, err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return domain.ErrRoutingRuleNotFound
+	}
+	return nil
+}
+
+func (r *RoutingRuleRepository) Delete(ctx context.Context, id int64) error {
+	ds := goqu.Delete(routingRulesTable).Where(goqu.Ex{"id": id})
+
+	result, err := r.db.Delete(ctx, ds)
+	if err != nil {
+		return fmt.Errorf("error deleting routing rule id %d: %w", id, err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return domain.ErrRoutingRuleNotFound
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/api/signing"
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/ohttp"
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+)
+
+const DefaultHTTPTimeout = 10 * time.Second
+
+const (
+	HeaderEventID   = "X-Event-Id"
+	HeaderEventType = "X-Event-Type"
+)
+
+// HTTPSink posts outbox events to a callback URL. The Idempotency-Key header
+// is stable per event so receivers can drop redeliveries.
+type HTTPSink struct {
+	url            string
+	httpClient     *ohttp.Client
+	signingSecrets []string
+	timeout        time.Duration
+}
+
+// Callback is the JSON body sent to the callback URL.
+type Callback struct {
+	ID        int64               `json:"id"`
+	MessageID int64               `json:"messageId"`
+	Type      string              `json:"type"`
+	Data      domain.EventPayload `json:"data"`
+	CreatedAt time.Time           `json:"createdAt"`
+}
+
+// NewHTTPSink creates a sink posting to url. Requests are signed with
+// signingSecrets when any are given and abandoned after timeout.
+func NewHTTPSink(url string, httpClient *ohttp.Client, signingSecrets []string, timeout time.Duration) *HTTPSink {
+	if timeout <= 0 {
+		timeout = DefaultHTTPTimeout
+	}
+	return &HTTPSink{url: url, httpClient: httpClient, signingSecrets: signingSecrets, timeout: timeout}
+}
+
+func (s *HTTPSink) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	body, err := json.Marshal(Callback{
+		ID:        event.ID,
+		MessageID: event.MessageID,
+		Type:      event.EventType,
+		Data:      event.Payload,
+		CreatedAt: event.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode event %d: %w", event.ID, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(ohttp.HeaderIdempotencyKey, "event-"+strconv.FormatInt(event.ID, 10))
+	req.Header.Set(HeaderEventID, strconv.FormatInt(event.ID, 10))
+	req.Header.Set(HeaderEventType, event.EventType)
+	if len(s.signingSecrets) > 0 {
+		if err := signing.SignRequest(req, s.signingSecrets, body, time.Now()); err != nil {
+			return fmt.Errorf("failed to sign request: %w", err)
+		}
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code: %d for %s", resp.StatusCode, s.url)
+	}
+	return nil
+}
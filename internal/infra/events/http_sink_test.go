@@ -0,0 +1,62 @@
+//go:build unit
+
+package events_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/api/signing"
+	"github.com/muratdemir0/gopulse-messages/internal/adapters/ohttp"
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+	"github.com/muratdemir0/gopulse-messages/internal/infra/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPSink_Publish(t *testing.T) {
+	event := domain.OutboxEvent{
+		ID:        42,
+		MessageID: 7,
+		EventType: domain.EventMessageSent,
+		Payload:   domain.EventPayload(`{"messageId":7,"status":"sent"}`),
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	t.Run("Given a 2xx response, the signed event is delivered", func(t *testing.T) {
+		var header http.Header
+		var callback events.Callback
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := signing.VerifyRequest(r, []string{"secret"}, time.Minute)
+			assert.NoError(t, err)
+			assert.NoError(t, json.Unmarshal(body, &callback))
+			header = r.Header.Clone()
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		sink := events.NewHTTPSink(server.URL, ohttp.NewClient(), []string{"secret"}, time.Second)
+		require.NoError(t, sink.Publish(context.Background(), event))
+
+		assert.Equal(t, "event-42", header.Get(ohttp.HeaderIdempotencyKey))
+		assert.Equal(t, domain.EventMessageSent, header.Get(events.HeaderEventType))
+		assert.Equal(t, int64(7), callback.MessageID)
+		assert.JSONEq(t, string(event.Payload), string(callback.Data))
+	})
+
+	t.Run("Given a non-2xx response, an error is returned", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		sink := events.NewHTTPSink(server.URL, ohttp.NewClient(), nil, time.Second)
+		assert.Error(t, sink.Publish(context.Background(), event))
+	})
+}
@@ -0,0 +1,99 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const DefaultChannel = "gopulse:message-events:live"
+
+// RedisPubSub fans events out to every replica over a Redis pub/sub channel.
+// Delivery is best effort; subscribers that miss events resume from the
+// outbox.
+type RedisPubSub struct {
+	client  *redis.Client
+	channel string
+}
+
+type liveEvent struct {
+	ID        int64               `json:"id"`
+	MessageID int64               `json:"messageId"`
+	Type      string              `json:"type"`
+	Payload   domain.EventPayload `json:"payload"`
+	CreatedAt time.Time           `json:"createdAt"`
+}
+
+func NewRedisPubSub(client *redis.Client, channel string) *RedisPubSub {
+	if channel == "" {
+		channel = DefaultChannel
+	}
+	return &RedisPubSub{client: client, channel: channel}
+}
+
+func (p *RedisPubSub) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	data, err := json.Marshal(liveEvent{
+		ID:        event.ID,
+		MessageID: event.MessageID,
+		Type:      event.EventType,
+		Payload:   event.Payload,
+		CreatedAt: event.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode event %d: %w", event.ID, err)
+	}
+
+	if err := p.client.Publish(ctx, p.channel, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish event %d to %s: %w", event.ID, p.channel, err)
+	}
+	return nil
+}
+
+// Subscribe listens on the channel until ctx is done. The Redis client
+// reconnects on its own after connection errors.
+func (p *RedisPubSub) Subscribe(ctx context.Context) (<-chan domain.OutboxEvent, error) {
+	sub := p.client.Subscribe(ctx, p.channel)
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", p.channel, err)
+	}
+
+	out := make(chan domain.OutboxEvent)
+	go func() {
+		defer close(out)
+		defer sub.Close() //nolint:errcheck
+
+		messages := sub.Channel()
+		for {
+			select {
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event liveEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					continue
+				}
+				select {
+				case out <- domain.OutboxEvent{
+					ID:        event.ID,
+					MessageID: event.MessageID,
+					EventType: event.Type,
+					Payload:   event.Payload,
+					CreatedAt: event.CreatedAt,
+				}:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
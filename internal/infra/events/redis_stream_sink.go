@@ -0,0 +1,52 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const DefaultStream = "gopulse:message-events"
+
+// RedisStreamSink appends outbox events to a Redis stream. Consumers can use
+// the event_id field to drop redeliveries.
+type RedisStreamSink struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamSink creates a sink writing to stream. When maxLen is positive
+// the stream is trimmed to roughly that many entries.
+func NewRedisStreamSink(client *redis.Client, stream string, maxLen int64) *RedisStreamSink {
+	if stream == "" {
+		stream = DefaultStream
+	}
+	return &RedisStreamSink{client: client, stream: stream, maxLen: maxLen}
+}
+
+func (s *RedisStreamSink) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	args := &redis.XAddArgs{
+		Stream: s.stream,
+		Values: map[string]any{
+			"event_id":   strconv.FormatInt(event.ID, 10),
+			"message_id": strconv.FormatInt(event.MessageID, 10),
+			"type":       event.EventType,
+			"payload":    string(event.Payload),
+			"created_at": event.CreatedAt.UTC().Format(time.RFC3339Nano),
+		},
+	}
+	if s.maxLen > 0 {
+		args.MaxLen = s.maxLen
+		args.Approx = true
+	}
+
+	if err := s.client.XAdd(ctx, args).Err(); err != nil {
+		return fmt.Errorf("failed to add event %d to stream %s: %w", event.ID, s.stream, err)
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/muratdemir0/gopulse-messages/api/rest"
 	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
 )
 
 type MessageHandler struct {
@@ -22,10 +23,15 @@ type MessageHandler struct {
 // @Produce json
 // @Success 200 {object} map[string]interface{} "message: Automatic message sending started, status: active"
 // @Failure 500 {object} ErrorResponse "Failed to start automatic message sending"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Insufficient role"
+// @Failure 429 {object} ErrorResponse "Rate limit exceeded"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /messages/start [post]
 func (h *MessageHandler) StartAutoSending(w http.ResponseWriter, r *http.Request) {
 	if err := h.service.StartAutoSending(); err != nil {
-		h.logger.Error("Failed to start automatic message sending", "error", err)
+		h.logger.ErrorContext(r.Context(), "Failed to start automatic message sending", "error", err)
 		Error(w, r, http.StatusInternalServerError, "Failed to start automatic message sending")
 		return
 	}
@@ -44,10 +50,15 @@ func (h *MessageHandler) StartAutoSending(w http.ResponseWriter, r *http.Request
 // @Produce json
 // @Success 200 {object} map[string]interface{} "message: Automatic message sending stopped, status: inactive"
 // @Failure 500 {object} ErrorResponse "Failed to stop automatic message sending"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Insufficient role"
+// @Failure 429 {object} ErrorResponse "Rate limit exceeded"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /messages/stop [post]
 func (h *MessageHandler) StopAutoSending(w http.ResponseWriter, r *http.Request) {
 	if err := h.service.StopAutoSending(); err != nil {
-		h.logger.Error("Failed to stop automatic message sending", "error", err)
+		h.logger.ErrorContext(r.Context(), "Failed to stop automatic message sending", "error", err)
 		Error(w, r, http.StatusInternalServerError, "Failed to stop automatic message sending")
 		return
 	}
@@ -65,9 +76,17 @@ func (h *MessageHandler) StopAutoSending(w http.ResponseWriter, r *http.Request)
 // @Produce json
 // @Param limit query int false "Number of messages to return" default(10)
 // @Param offset query int false "Offset for pagination" default(0)
+// @Param channel query string false "Only messages on this channel" Enums(sms, email)
+// @Param tenant query string false "Only messages of this tenant"
+// @Param provider query string false "Only messages delivered by this provider"
 // @Success 200 {object} rest.MessagesListResponse
-// @Failure 400 {object} ErrorResponse "Invalid limit or offset parameter"
+// @Failure 400 {object} ErrorResponse "Invalid limit, offset or filter parameter"
 // @Failure 500 {object} ErrorResponse "Failed to retrieve messages"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Insufficient role"
+// @Failure 429 {object} ErrorResponse "Rate limit exceeded"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /messages [get]
 func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
 	limitStr := r.URL.Query().Get("limit")
@@ -80,7 +99,7 @@ func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
 		if l, err := strconv.ParseUint(limitStr, 10, 32); err == nil {
 			limit = uint(l)
 		} else {
-			h.logger.Warn("Invalid limit parameter", "limit", limitStr)
+			h.logger.WarnContext(r.Context(), "Invalid limit parameter", "limit", limitStr)
 			Error(w, r, http.StatusBadRequest, "Invalid limit parameter")
 			return
 		}
@@ -90,15 +109,21 @@ func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
 		if o, err := strconv.ParseUint(offsetStr, 10, 32); err == nil {
 			offset = uint(o)
 		} else {
-			h.logger.Warn("Invalid offset parameter", "offset", offsetStr)
+			h.logger.WarnContext(r.Context(), "Invalid offset parameter", "offset", offsetStr)
 			Error(w, r, http.StatusBadRequest, "Invalid offset parameter")
 			return
 		}
 	}
 
-	messages, err := h.service.GetSentMessages(r.Context(), limit, offset)
+	filter, ok := parseMessageFilter(r)
+	if !ok {
+		Error(w, r, http.StatusBadRequest, "Invalid channel parameter")
+		return
+	}
+
+	messages, err := h.service.GetSentMessages(r.Context(), filter, limit, offset)
 	if err != nil {
-		h.logger.Error("Failed to retrieve messages", "error", err)
+		h.logger.ErrorContext(r.Context(), "Failed to retrieve messages", "error", err)
 		Error(w, r, http.StatusInternalServerError, "Failed to retrieve messages")
 		return
 	}
@@ -112,6 +137,58 @@ func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
 	JSON(w, r, http.StatusOK, response)
 }
 
+// GetMessageAttempts godoc
+// @Summary Get delivery attempts of a message
+// @Description Lists every provider dispatch of a message with status code, latency and a truncated response body.
+// @Tags messages
+// @Produce json
+// @Param id path int true "Message ID"
+// @Success 200 {object} rest.MessageAttemptsListResponse
+// @Failure 400 {object} ErrorResponse "Invalid message ID"
+// @Failure 500 {object} ErrorResponse "Failed to retrieve attempts"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Insufficient role"
+// @Failure 429 {object} ErrorResponse "Rate limit exceeded"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /messages/{id}/attempts [get]
+func (h *MessageHandler) GetMessageAttempts(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, r, http.StatusBadRequest, "Invalid message ID")
+		return
+	}
+
+	attempts, err := h.service.GetAttempts(r.Context(), id)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to retrieve attempts", "message_id", id, "error", err)
+		Error(w, r, http.StatusInternalServerError, "Failed to retrieve attempts")
+		return
+	}
+
+	responses := rest.ToMessageAttemptResponses(attempts)
+	JSON(w, r, http.StatusOK, rest.MessageAttemptsListResponse{
+		Attempts: responses,
+		Count:    len(responses),
+	})
+}
+
+// parseMessageFilter reads the channel, tenant and provider query parameters
+// shared by the list and stream endpoints. It reports false for an unknown
+// channel.
+func parseMessageFilter(r *http.Request) (domain.MessageFilter, bool) {
+	query := r.URL.Query()
+	filter := domain.MessageFilter{
+		Channel:  domain.MessageChannel(query.Get("channel")),
+		Tenant:   query.Get("tenant"),
+		Provider: query.Get("provider"),
+	}
+	if filter.Channel != "" && !filter.Channel.Valid() {
+		return domain.MessageFilter{}, false
+	}
+	return filter, true
+}
+
 func RegisterMessageHandler(mux *http.ServeMux, service *app.MessageService, logger *slog.Logger) {
 	h := &MessageHandler{
 		service: service,
@@ -121,4 +198,5 @@ func RegisterMessageHandler(mux *http.ServeMux, service *app.MessageService, log
 	mux.HandleFunc("POST /messages/start", h.StartAutoSending)
 	mux.HandleFunc("POST /messages/stop", h.StopAutoSending)
 	mux.HandleFunc("GET /messages", h.GetMessages)
+	mux.HandleFunc("GET /messages/{id}/attempts", h.GetMessageAttempts)
 }
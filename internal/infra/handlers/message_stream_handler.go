@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+)
+
+const (
+	DefaultStreamHeartbeat = 15 * time.Second
+	streamRetry            = 3 * time.Second
+)
+
+type MessageStreamHandler struct {
+	stream    *app.MessageStream
+	heartbeat time.Duration
+	logger    *slog.Logger
+}
+
+// StreamMessages godoc
+// @Summary Stream message status changes
+// @Description Pushes message events (created, sent, failed, delivered) as Server-Sent Events. Each event carries the outbox event ID; reconnecting with Last-Event-ID replays what was missed.
+// @Tags messages
+// @Produce text/event-stream
+// @Param channel query string false "Only messages on this channel" Enums(sms, email)
+// @Param tenant query string false "Only messages of this tenant"
+// @Param provider query string false "Only messages delivered by this provider"
+// @Param types query string false "Comma separated event types" example(sent,failed)
+// @Param lastEventId query int false "Resume after this event ID when the Last-Event-ID header cannot be set"
+// @Param Last-Event-ID header int false "Resume after this event ID"
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {object} ErrorResponse "Invalid filter or event ID"
+// @Failure 500 {object} ErrorResponse "Failed to open stream"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Insufficient role"
+// @Failure 429 {object} ErrorResponse "Rate limit exceeded"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /messages/stream [get]
+func (h *MessageStreamHandler) StreamMessages(w http.ResponseWriter, r *http.Request) {
+	messageFilter, ok := parseMessageFilter(r)
+	if !ok {
+		Error(w, r, http.StatusBadRequest, "Invalid channel parameter")
+		return
+	}
+
+	types, ok := parseEventTypes(r.URL.Query().Get("types"))
+	if !ok {
+		Error(w, r, http.StatusBadRequest, "Invalid types parameter")
+		return
+	}
+
+	lastEventID, ok := parseLastEventID(r)
+	if !ok {
+		Error(w, r, http.StatusBadRequest, "Invalid Last-Event-ID")
+		return
+	}
+
+	// Streams outlive the server write timeout.
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to clear write deadline", "error", err)
+	}
+
+	events, err := h.stream.Subscribe(r.Context(), app.StreamFilter{MessageFilter: messageFilter, Types: types}, lastEventID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to open message stream", "error", err)
+		Error(w, r, http.StatusInternalServerError, "Failed to open stream")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds())
+	if err := rc.Flush(); err != nil {
+		h.logger.ErrorContext(r.Context(), "Streaming is not supported", "error", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.EventType, event.Payload)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-r.Context().Done():
+			return
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// parseEventTypes accepts full event types and their short forms, such as
+// message.sent and sent.
+func parseEventTypes(value string) ([]string, bool) {
+	if value == "" {
+		return nil, true
+	}
+
+	var types []string
+	for _, t := range strings.Split(value, ",") {
+		t = strings.TrimSpace(t)
+		if !strings.HasPrefix(t, "message.") {
+			t = "message." + t
+		}
+		if !slices.Contains(domain.MessageEventTypes, t) {
+			return nil, false
+		}
+		types = append(types, t)
+	}
+	return types, true
+}
+
+func parseLastEventID(r *http.Request) (int64, bool) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("lastEventId")
+	}
+	if value == "" {
+		return 0, true
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+func RegisterMessageStreamHandler(mux *http.ServeMux, stream *app.MessageStream, heartbeat time.Duration, logger *slog.Logger) {
+	if heartbeat <= 0 {
+		heartbeat = DefaultStreamHeartbeat
+	}
+
+	h := &MessageStreamHandler{
+		stream:    stream,
+		heartbeat: heartbeat,
+		logger:    logger.With(slog.String("component", "message_stream_handler")),
+	}
+
+	mux.HandleFunc("GET /messages/stream", h.StreamMessages)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/api/rest"
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+)
+
+type ProbeHandler struct {
+	liveness  *app.CheckRegistry
+	readiness *app.CheckRegistry
+	logger    *slog.Logger
+}
+
+// Livez godoc
+// @Summary Liveness probe
+// @Description Reports whether the process is healthy, including scheduler liveness. Returns 503 when a check fails.
+// @Tags health
+// @Produce json
+// @Success 200 {object} rest.ProbeResponse
+// @Failure 503 {object} rest.ProbeResponse
+// @Router /livez [get]
+func (h *ProbeHandler) Livez(w http.ResponseWriter, r *http.Request) {
+	h.respond(w, r, h.liveness)
+}
+
+// Readyz godoc
+// @Summary Readiness probe
+// @Description Reports whether the service can take traffic, checking its dependencies. Returns 503 when a check fails or the service is shutting down.
+// @Tags health
+// @Produce json
+// @Success 200 {object} rest.ProbeResponse
+// @Failure 503 {object} rest.ProbeResponse
+// @Router /readyz [get]
+func (h *ProbeHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	h.respond(w, r, h.readiness)
+}
+
+func (h *ProbeHandler) respond(w http.ResponseWriter, r *http.Request, registry *app.CheckRegistry) {
+	if registry.Draining() {
+		JSON(w, r, http.StatusServiceUnavailable, rest.ProbeResponse{
+			Status: rest.ProbeStatusDraining,
+			Checks: []rest.CheckResponse{},
+		})
+		return
+	}
+
+	results := registry.Run(r.Context())
+
+	resp := rest.ProbeResponse{
+		Status: rest.ProbeStatusOK,
+		Checks: make([]rest.CheckResponse, len(results)),
+	}
+	for i, result := range results {
+		check := rest.CheckResponse{
+			Name:      result.Name,
+			Status:    rest.ProbeStatusOK,
+			LatencyMs: float64(result.Latency.Microseconds()) / 1000,
+			CheckedAt: result.CheckedAt.Format(time.RFC3339),
+		}
+		if result.Err != nil {
+			check.Status = rest.ProbeStatusFail
+			check.Error = result.Err.Error()
+			resp.Status = rest.ProbeStatusFail
+			h.logger.WarnContext(r.Context(), "Check failed", "check", result.Name, "error", result.Err)
+		}
+		resp.Checks[i] = check
+	}
+
+	status := http.StatusOK
+	if resp.Status != rest.ProbeStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	JSON(w, r, status, resp)
+}
+
+func RegisterProbeHandler(mux *http.ServeMux, liveness, readiness *app.CheckRegistry, logger *slog.Logger) {
+	h := &ProbeHandler{
+		liveness:  liveness,
+		readiness: readiness,
+		logger:    logger.With(slog.String("component", "probe_handler")),
+	}
+
+	mux.HandleFunc("GET /livez", h.Livez)
+	mux.HandleFunc("GET /readyz", h.Readyz)
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/muratdemir0/gopulse-messages/api/rest"
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/muratdemir0/gopulse-messages/internal/domain"
+)
+
+type RoutingHandler struct {
+	router *app.Router
+	logger *slog.Logger
+}
+
+// ListRules godoc
+// @Summary List routing rules
+// @Description Returns all routing rules in evaluation order.
+// @Tags routing
+// @Produce json
+// @Success 200 {object} rest.RoutingRulesListResponse
+// @Failure 500 {object} ErrorResponse "Failed to retrieve routing rules"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Insufficient role"
+// @Failure 429 {object} ErrorResponse "Rate limit exceeded"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /routing/rules [get]
+func (h *RoutingHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.router.ListRules(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to retrieve routing rules", "error", err)
+		Error(w, r, http.StatusInternalServerError, "Failed to retrieve routing rules")
+		return
+	}
+
+	responses := rest.ToRoutingRuleResponses(rules)
+	JSON(w, r, http.StatusOK, rest.RoutingRulesListResponse{
+		Rules: responses,
+		Count: len(responses),
+	})
+}
+
+// CreateRule godoc
+// @Summary Create a routing rule
+// @Description Creates a routing rule. Targets must reference configured providers.
+// @Tags routing
+// @Accept json
+// @Produce json
+// @Param rule body rest.RoutingRuleRequest true "Routing rule"
+// @Success 201 {object} rest.RoutingRuleResponse
+// @Failure 400 {object} ErrorResponse "Invalid routing rule"
+// @Failure 500 {object} ErrorResponse "Failed to create routing rule"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Insufficient role"
+// @Failure 429 {object} ErrorResponse "Rate limit exceeded"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /routing/rules [post]
+func (h *RoutingHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	var req rest.RoutingRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rule := req.ToDomain()
+	if err := h.router.CreateRule(r.Context(), &rule); err != nil {
+		h.writeError(w, r, err, "Failed to create routing rule")
+		return
+	}
+
+	created, err := h.router.GetRule(r.Context(), rule.ID)
+	if err != nil {
+		h.writeError(w, r, err, "Failed to create routing rule")
+		return
+	}
+
+	JSON(w, r, http.StatusCreated, rest.ToRoutingRuleResponse(created))
+}
+
+// UpdateRule godoc
+// @Summary Update a routing rule
+// @Description Replaces the routing rule with the given ID.
+// @Tags routing
+// @Accept json
+// @Produce json
+// @Param id path int true "Routing rule ID"
+// @Param rule body rest.RoutingRuleRequest true "Routing rule"
+// @Success 200 {object} rest.RoutingRuleResponse
+// @Failure 400 {object} ErrorResponse "Invalid routing rule"
+// @Failure 404 {object} ErrorResponse "Routing rule not found"
+// @Failure 500 {object} ErrorResponse "Failed to update routing rule"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Insufficient role"
+// @Failure 429 {object} ErrorResponse "Rate limit exceeded"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /routing/rules/{id} [put]
+func (h *RoutingHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.ruleID(w, r)
+	if !ok {
+		return
+	}
+
+	var req rest.RoutingRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rule := req.ToDomain()
+	rule.ID = id
+	if err := h.router.UpdateRule(r.Context(), rule); err != nil {
+		h.writeError(w, r, err, "Failed to update routing rule")
+		return
+	}
+
+	updated, err := h.router.GetRule(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, err, "Failed to update routing rule")
+		return
+	}
+
+	JSON(w, r, http.StatusOK, rest.ToRoutingRuleResponse(updated))
+}
+
+// DeleteRule godoc
+// @Summary Delete a routing rule
+// @Tags routing
+// @Param id path int true "Routing rule ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse "Invalid routing rule ID"
+// @Failure 404 {object} ErrorResponse "Routing rule not found"
+// @Failure 500 {object} ErrorResponse "Failed to delete routing rule"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Insufficient role"
+// @Failure 429 {object} ErrorResponse "Rate limit exceeded"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /routing/rules/{id} [delete]
+func (h *RoutingHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.ruleID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.router.DeleteRule(r.Context(), id); err != nil {
+		h.writeError(w, r, err, "Failed to delete routing rule")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DryRun godoc
+// @Summary Dry-run message routing
+// @Description Reports which rule and provider a message would be routed to without sending it.
+// @Tags routing
+// @Accept json
+// @Produce json
+// @Param message body rest.DryRunRequest true "Message attributes"
+// @Success 200 {object} rest.DryRunResponse
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 500 {object} ErrorResponse "Failed to evaluate routing rules"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Insufficient role"
+// @Failure 429 {object} ErrorResponse "Rate limit exceeded"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /routing/dry-run [post]
+func (h *RoutingHandler) DryRun(w http.ResponseWriter, r *http.Request) {
+	var req rest.DryRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Recipient == "" {
+		Error(w, r, http.StatusBadRequest, "recipient is required")
+		return
+	}
+
+	decision, err := h.router.DryRun(r.Context(), req.ToDomain())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to evaluate routing rules", "error", err)
+		Error(w, r, http.StatusInternalServerError, "Failed to evaluate routing rules")
+		return
+	}
+
+	resp := rest.DryRunResponse{}
+	if decision.Rule != nil {
+		resp.Matched = true
+		resp.RuleID = &decision.Rule.ID
+		resp.RuleName = decision.Rule.Name
+		resp.Provider = decision.Provider
+	}
+
+	JSON(w, r, http.StatusOK, resp)
+}
+
+func (h *RoutingHandler) ruleID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, r, http.StatusBadRequest, "Invalid routing rule ID")
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *RoutingHandler) writeError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	var validationErr *app.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		Error(w, r, http.StatusBadRequest, validationErr.Error())
+	case errors.Is(err, domain.ErrRoutingRuleNotFound):
+		Error(w, r, http.StatusNotFound, "Routing rule not found")
+	default:
+		h.logger.ErrorContext(r.Context(), message, "error", err)
+		Error(w, r, http.StatusInternalServerError, message)
+	}
+}
+
+func RegisterRoutingHandler(mux *http.ServeMux, router *app.Router, logger *slog.Logger) {
+	h := &RoutingHandler{
+		router: router,
+		logger: logger.With(slog.String("component", "routing_handler")),
+	}
+
+	mux.HandleFunc("GET /routing/rules", h.ListRules)
+	mux.HandleFunc("POST /routing/rules", h.CreateRule)
+	mux.HandleFunc("PUT /routing/rules/{id}", h.UpdateRule)
+	mux.HandleFunc("DELETE /routing/rules/{id}", h.DeleteRule)
+	mux.HandleFunc("POST /routing/dry-run", h.DryRun)
+}
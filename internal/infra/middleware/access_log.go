@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// AccessLog logs one line per request with its method, route pattern, status,
+// response size, duration and client IP. Requests to excluded paths, such as
+// probes and metric scrapes, are not logged. Like Metrics it must wrap the
+// ServeMux without request copies in between to see the route pattern.
+func AccessLog(logger *slog.Logger, excludedPaths []string) func(http.Handler) http.Handler {
+	excluded := make(map[string]bool, len(excludedPaths))
+	for _, path := range excludedPaths {
+		excluded[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if excluded[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			sw := newStatusWriter(w)
+
+			next.ServeHTTP(sw, r)
+
+			level := slog.LevelInfo
+			switch {
+			case sw.status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case sw.status >= http.StatusBadRequest:
+				level = slog.LevelWarn
+			}
+
+			logger.LogAttrs(r.Context(), level, "HTTP request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("route", r.Pattern),
+				slog.Int("status", sw.status),
+				slog.Int("bytes", sw.bytes),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("client_ip", clientIP(r)),
+			)
+		})
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,57 @@
+//go:build unit
+
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/muratdemir0/gopulse-messages/internal/infra/middleware"
+	"github.com/muratdemir0/gopulse-messages/internal/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(telemetry.NewLogHandler(slog.NewJSONHandler(&buf, nil)))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /messages/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("missing"))
+	})
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {})
+	handler := middleware.RequestID(middleware.AccessLog(logger, []string{"/health"})(mux))
+
+	t.Run("Given a request, it should log the route, status, size and request ID", func(t *testing.T) {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/messages/42", nil)
+		req.RemoteAddr = "10.0.0.1:5555"
+		req.Header.Set(middleware.RequestIDHeader, "req-1")
+
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		assert.Equal(t, "WARN", line["level"])
+		assert.Equal(t, "GET", line["method"])
+		assert.Equal(t, "GET /messages/{id}", line["route"])
+		assert.Equal(t, float64(http.StatusNotFound), line["status"])
+		assert.Equal(t, float64(len("missing")), line["bytes"])
+		assert.Equal(t, "10.0.0.1", line["client_ip"])
+		assert.Equal(t, "req-1", line["request_id"])
+	})
+
+	t.Run("Given an excluded path, it should not log", func(t *testing.T) {
+		buf.Reset()
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		assert.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,130 @@
+//go:build unit
+
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/muratdemir0/gopulse-messages/internal/infra/auth"
+	"github.com/muratdemir0/gopulse-messages/internal/infra/handlers"
+	"github.com/muratdemir0/gopulse-messages/internal/infra/middleware"
+)
+
+func TestAuthorize(t *testing.T) {
+	authenticator, err := auth.NewAuthenticator([]auth.APIKey{
+		{Name: "reader", Key: "reader-key", Role: auth.RoleReader},
+		{Name: "operator", Key: "operator-key", Role: auth.RoleOperator},
+	}, nil)
+	require.NoError(t, err)
+
+	var principal auth.Principal
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = auth.PrincipalFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", ok)
+	mux.HandleFunc("GET /messages", ok)
+	mux.HandleFunc("POST /messages/stop", ok)
+	mux.HandleFunc("DELETE /routing/rules/{id}", ok)
+
+	handler := middleware.Authorize(authenticator, mux, map[string]auth.Role{
+		"GET /health":         auth.RolePublic,
+		"GET /messages":       auth.RoleReader,
+		"POST /messages/stop": auth.RoleOperator,
+	})(mux)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		key      string
+		expected int
+	}{
+		{
+			name:     "Given a public route, it should not require credentials",
+			method:   http.MethodGet,
+			path:     "/health",
+			expected: http.StatusOK,
+		},
+		{
+			name:     "Given no credentials, it should respond 401",
+			method:   http.MethodPost,
+			path:     "/messages/stop",
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "Given an unknown key, it should respond 401",
+			method:   http.MethodPost,
+			path:     "/messages/stop",
+			key:      "wrong-key",
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "Given a role below the required one, it should respond 403",
+			method:   http.MethodPost,
+			path:     "/messages/stop",
+			key:      "reader-key",
+			expected: http.StatusForbidden,
+		},
+		{
+			name:     "Given a higher role, it should grant the lower one",
+			method:   http.MethodGet,
+			path:     "/messages",
+			key:      "operator-key",
+			expected: http.StatusOK,
+		},
+		{
+			name:     "Given a route missing from the policy, it should require admin",
+			method:   http.MethodDelete,
+			path:     "/routing/rules/1",
+			key:      "operator-key",
+			expected: http.StatusForbidden,
+		},
+		{
+			name:     "Given an unknown route, it should be left to the mux",
+			method:   http.MethodGet,
+			path:     "/nope",
+			expected: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.key != "" {
+				r.Header.Set(auth.APIKeyHeader, tt.key)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, r)
+
+			assert.Equal(t, tt.expected, rec.Code)
+			if tt.expected == http.StatusUnauthorized || tt.expected == http.StatusForbidden {
+				var resp handlers.ErrorResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, tt.expected, resp.Status)
+				assert.Equal(t, tt.path, resp.Path)
+			}
+			if tt.expected == http.StatusUnauthorized {
+				assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+
+	t.Run("Given an authorized request, the principal and pattern should be visible", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/messages", nil)
+		r.Header.Set(auth.APIKeyHeader, "reader-key")
+
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+
+		assert.Equal(t, "reader", principal.Subject)
+		assert.Equal(t, "GET /messages", r.Pattern)
+	})
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/muratdemir0/gopulse-messages/internal/telemetry"
+)
+
+const (
+	meterName      = "github.com/muratdemir0/gopulse-messages/internal/infra/middleware"
+	unmatchedRoute = "unmatched"
+)
+
+// Metrics records the duration of every request by method, route pattern and
+// status code. It must wrap the ServeMux directly, without handlers that copy
+// the request in between, so the matched pattern is visible once the request
+// is served.
+func Metrics(next http.Handler) http.Handler {
+	duration, err := otel.Meter(meterName).Float64Histogram(
+		"http.server.request.duration",
+		metric.WithDescription("Duration of HTTP server requests"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(telemetry.DurationBuckets...),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := newStatusWriter(w)
+
+		next.ServeHTTP(sw, r)
+
+		route := r.Pattern
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		duration.Record(r.Context(), time.Since(start).Seconds(), metric.WithAttributes(
+			attribute.String("method", r.Method),
+			attribute.String("route", route),
+			attribute.String("status", strconv.Itoa(sw.status)),
+		))
+	})
+}
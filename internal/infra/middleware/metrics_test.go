@@ -0,0 +1,85 @@
+//go:build unit
+
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/muratdemir0/gopulse-messages/internal/infra/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /messages/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := middleware.Metrics(middleware.Recovery(mux))
+
+	tests := []struct {
+		name   string
+		path   string
+		route  string
+		status string
+	}{
+		{
+			name:   "Given a matched request, the route pattern and status are recorded",
+			path:   "/messages/42",
+			route:  "GET /messages/{id}",
+			status: "418",
+		},
+		{
+			name:   "Given an unmatched request, the route is recorded as unmatched",
+			path:   "/unknown",
+			route:  "unmatched",
+			status: "404",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			var rm metricdata.ResourceMetrics
+			require.NoError(t, reader.Collect(context.Background(), &rm))
+
+			histogram := findHistogram(t, rm, "http.server.request.duration")
+			var found bool
+			for _, point := range histogram.DataPoints {
+				route, _ := point.Attributes.Value(attribute.Key("route"))
+				status, _ := point.Attributes.Value(attribute.Key("status"))
+				if route.AsString() == tt.route && status.AsString() == tt.status {
+					found = true
+					assert.Equal(t, uint64(1), point.Count)
+				}
+			}
+			assert.True(t, found, "no data point for route %q and status %s", tt.route, tt.status)
+		})
+	}
+}
+
+func findHistogram(t *testing.T, rm metricdata.ResourceMetrics, name string) metricdata.Histogram[float64] {
+	t.Helper()
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				histogram, ok := m.Data.(metricdata.Histogram[float64])
+				require.True(t, ok)
+				return histogram
+			}
+		}
+	}
+	t.Fatalf("metric %s not recorded", name)
+	return metricdata.Histogram[float64]{}
+}
@@ -2,19 +2,35 @@ package middleware
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"runtime/debug"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/muratdemir0/gopulse-messages/internal/infra/handlers"
 )
 
+// Recovery turns a panicking handler into a 500 ErrorResponse and logs the
+// panic with its stack trace.
 func Recovery(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
-			if err := recover(); err != nil {
-				log.Printf("panic recovered: %v", err)
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			err := recover()
+			if err == nil {
+				return
+			}
+			if err == http.ErrAbortHandler {
+				panic(err)
 			}
+
+			slog.Default().ErrorContext(r.Context(), "panic recovered",
+				slog.Any("error", err),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("stack", string(debug.Stack())),
+			)
+			handlers.Error(w, r, http.StatusInternalServerError, "Internal Server Error")
 		}()
 		next.ServeHTTP(w, r)
 	})
@@ -26,7 +42,11 @@ func Tracing(serviceName string) func(http.Handler) http.Handler {
 			next,
 			serviceName,
 			otelhttp.WithFilter(func(r *http.Request) bool {
-				return r.URL.Path != "/health" && r.URL.Path != "/healthz"
+				switch r.URL.Path {
+				case "/health", "/healthz", "/livez", "/readyz":
+					return false
+				}
+				return true
 			}),
 			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
 				return fmt.Sprintf("HTTP %s %s", r.Method, r.URL.Path)
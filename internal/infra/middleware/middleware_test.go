@@ -0,0 +1,85 @@
+//go:build unit
+
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/muratdemir0/gopulse-messages/internal/infra/handlers"
+	"github.com/muratdemir0/gopulse-messages/internal/infra/middleware"
+	"github.com/muratdemir0/gopulse-messages/internal/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecovery(t *testing.T) {
+	t.Run("Given a panicking handler, it should respond with a JSON ErrorResponse", func(t *testing.T) {
+		handler := middleware.Recovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/messages", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		var resp handlers.ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, http.StatusInternalServerError, resp.Status)
+		assert.Equal(t, "/messages", resp.Path)
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{
+			name:     "Given a valid X-Request-ID, it should be reused",
+			header:   "abc-123",
+			expected: "abc-123",
+		},
+		{
+			name:   "Given no X-Request-ID, one should be generated",
+			header: "",
+		},
+		{
+			name:   "Given an X-Request-ID with control characters, a new one should be generated",
+			header: "abc\x01def",
+		},
+		{
+			name:   "Given an overlong X-Request-ID, a new one should be generated",
+			header: strings.Repeat("a", 200),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fromContext string
+			handler := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fromContext = telemetry.RequestIDFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(middleware.RequestIDHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			responseID := rec.Header().Get(middleware.RequestIDHeader)
+			assert.NotEmpty(t, responseID)
+			assert.Equal(t, responseID, fromContext)
+			if tt.expected != "" {
+				assert.Equal(t, tt.expected, responseID)
+			} else {
+				assert.NotEqual(t, tt.header, responseID)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/infra/auth"
+	"github.com/muratdemir0/gopulse-messages/internal/infra/handlers"
+	"github.com/muratdemir0/gopulse-messages/internal/infra/ratelimit"
+)
+
+// RateLimitPolicy picks the limit for the mux pattern of a request; patterns
+// missing from Routes use Default. Key identifies the client the limit
+// applies to.
+type RateLimitPolicy struct {
+	Default ratelimit.Limit
+	Routes  map[string]ratelimit.Limit
+	Key     func(*http.Request) string
+}
+
+// RateLimit counts each request against its route's limit for its client
+// and rejects it with 429 once the limit is reached. Responses carry
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy
+// headers, and Retry-After when rejected. Requests are let through when the
+// limiter fails, so a Redis outage does not take the API down.
+func RateLimit(limiter ratelimit.Limiter, mux *http.ServeMux, policy RateLimitPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, pattern := mux.Handler(r)
+			r.Pattern = pattern
+
+			limit, ok := policy.Routes[pattern]
+			if !ok {
+				limit = policy.Default
+			}
+			if pattern == "" || limit.Unlimited() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			client := policy.Key(r)
+			result, err := limiter.Allow(r.Context(), pattern+"|"+client, limit)
+			if err != nil {
+				slog.Default().WarnContext(r.Context(), "rate limiter unavailable, allowing request",
+					slog.String("route", pattern),
+					slog.String("error", err.Error()),
+				)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+			header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, ceilSeconds(limit.Window)))
+
+			if !result.Allowed {
+				slog.Default().InfoContext(r.Context(), "rate limit exceeded",
+					slog.String("route", pattern),
+					slog.String("client", client),
+				)
+				header.Set("Retry-After", strconv.Itoa(max(ceilSeconds(result.RetryAfter), 1)))
+				handlers.Error(w, r, http.StatusTooManyRequests, "Rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClientKey identifies a request by its authenticated principal, falling back
+// to the client IP.
+func ClientKey(trustedHops int) func(*http.Request) string {
+	return func(r *http.Request) string {
+		if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+			return "principal:" + principal.Method + ":" + principal.Subject
+		}
+		return "ip:" + ClientIP(r, trustedHops)
+	}
+}
+
+// TenantKey identifies a request by the tenant in header, falling back to
+// ClientKey. The header must be set by a trusted gateway, since callers could
+// otherwise pick a fresh tenant per request.
+func TenantKey(header string, trustedHops int) func(*http.Request) string {
+	fallback := ClientKey(trustedHops)
+	return func(r *http.Request) string {
+		if tenant := r.Header.Get(header); tenant != "" {
+			return "tenant:" + tenant
+		}
+		return fallback(r)
+	}
+}
+
+// IPKey identifies a request by its client IP.
+func IPKey(trustedHops int) func(*http.Request) string {
+	return func(r *http.Request) string {
+		return "ip:" + ClientIP(r, trustedHops)
+	}
+}
+
+// ClientIP returns the IP of the caller. Behind trustedHops proxies that each
+// append the address they received the request from to X-Forwarded-For, that
+// is the trustedHops-th address from the right; anything left of it was sent
+// by the caller and can be forged. With zero hops the header is ignored.
+func ClientIP(r *http.Request, trustedHops int) string {
+	if trustedHops > 0 {
+		if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+			addrs := strings.Split(strings.Join(forwarded, ","), ",")
+			if ip := strings.TrimSpace(addrs[max(len(addrs)-trustedHops, 0)]); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
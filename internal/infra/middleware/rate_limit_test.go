@@ -0,0 +1,183 @@
+//go:build unit
+
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/muratdemir0/gopulse-messages/internal/infra/auth"
+	"github.com/muratdemir0/gopulse-messages/internal/infra/handlers"
+	"github.com/muratdemir0/gopulse-messages/internal/infra/middleware"
+	"github.com/muratdemir0/gopulse-messages/internal/infra/ratelimit"
+)
+
+type fakeLimiter struct {
+	result ratelimit.Result
+	err    error
+	keys   []string
+	limits []ratelimit.Limit
+}
+
+func (f *fakeLimiter) Allow(_ context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	f.keys = append(f.keys, key)
+	f.limits = append(f.limits, limit)
+	return f.result, f.err
+}
+
+func TestRateLimit(t *testing.T) {
+	newHandler := func(limiter ratelimit.Limiter) http.Handler {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /messages", func(w http.ResponseWriter, r *http.Request) {})
+		mux.HandleFunc("GET /livez", func(w http.ResponseWriter, r *http.Request) {})
+
+		return middleware.RateLimit(limiter, mux, middleware.RateLimitPolicy{
+			Default: ratelimit.Limit{Requests: 100, Window: time.Minute},
+			Routes:  map[string]ratelimit.Limit{"GET /livez": {}},
+			Key:     middleware.ClientKey(0),
+		})(mux)
+	}
+
+	t.Run("Given an allowed request, it should pass and set RateLimit headers", func(t *testing.T) {
+		limiter := &fakeLimiter{result: ratelimit.Result{Allowed: true, Limit: 100, Remaining: 99, Reset: 30 * time.Second}}
+		r := httptest.NewRequest(http.MethodGet, "/messages", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		rec := httptest.NewRecorder()
+
+		newHandler(limiter).ServeHTTP(rec, r)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "100", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "99", rec.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "30", rec.Header().Get("RateLimit-Reset"))
+		assert.Equal(t, "100;w=60", rec.Header().Get("RateLimit-Policy"))
+		assert.Empty(t, rec.Header().Get("Retry-After"))
+		assert.Equal(t, []string{"GET /messages|ip:10.0.0.1"}, limiter.keys)
+	})
+
+	t.Run("Given a rejected request, it should respond 429 with Retry-After", func(t *testing.T) {
+		limiter := &fakeLimiter{result: ratelimit.Result{Limit: 100, Reset: 30 * time.Second, RetryAfter: 1500 * time.Millisecond}}
+		rec := httptest.NewRecorder()
+
+		newHandler(limiter).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/messages", nil))
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+		assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+		var resp handlers.ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, http.StatusTooManyRequests, resp.Status)
+		assert.Equal(t, "/messages", resp.Path)
+	})
+
+	t.Run("Given an unlimited route, it should not consult the limiter", func(t *testing.T) {
+		limiter := &fakeLimiter{}
+		rec := httptest.NewRecorder()
+
+		newHandler(limiter).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, limiter.keys)
+	})
+
+	t.Run("Given a failing limiter, it should let the request through", func(t *testing.T) {
+		limiter := &fakeLimiter{err: errors.New("redis down")}
+		rec := httptest.NewRecorder()
+
+		newHandler(limiter).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/messages", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+	})
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	t.Run("Given an authenticated principal, the client key should use it", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/messages", nil)
+		r = r.WithContext(auth.ContextWithPrincipal(r.Context(), auth.Principal{Subject: "ops", Method: auth.MethodAPIKey}))
+
+		assert.Equal(t, "principal:api_key:ops", middleware.ClientKey(0)(r))
+	})
+
+	t.Run("Given a tenant header, the tenant key should use it", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/messages", nil)
+		r.Header.Set("X-Tenant-ID", "acme")
+
+		assert.Equal(t, "tenant:acme", middleware.TenantKey("X-Tenant-ID", 0)(r))
+	})
+
+	t.Run("Given X-Forwarded-For, it should only be used behind a trusted proxy", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/messages", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		r.Header.Set("X-Forwarded-For", "203.0.113.7")
+
+		assert.Equal(t, "10.0.0.1", middleware.ClientIP(r, 0))
+		assert.Equal(t, "203.0.113.7", middleware.ClientIP(r, 1))
+	})
+
+	t.Run("Given a spoofed left-most X-Forwarded-For, it should use the address the proxy appended", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/messages", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		r.Header.Set("X-Forwarded-For", "198.51.100.1, 198.51.100.2, 203.0.113.7")
+
+		assert.Equal(t, "203.0.113.7", middleware.ClientIP(r, 1))
+		assert.Equal(t, "ip:203.0.113.7", middleware.IPKey(1)(r))
+	})
+
+	t.Run("Given two trusted proxies, it should count both from the right", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/messages", nil)
+		r.RemoteAddr = "10.0.0.2:1234"
+		r.Header.Add("X-Forwarded-For", "198.51.100.1, 203.0.113.7")
+		r.Header.Add("X-Forwarded-For", "10.0.0.1")
+
+		assert.Equal(t, "203.0.113.7", middleware.ClientIP(r, 2))
+	})
+}
+
+func TestRateLimit_BeforeAuthorize(t *testing.T) {
+	authenticator, err := auth.NewAuthenticator([]auth.APIKey{{Name: "reader", Key: "reader-key", Role: auth.RoleReader}}, nil)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /messages", func(w http.ResponseWriter, r *http.Request) {})
+	newHandler := func(limiter ratelimit.Limiter) http.Handler {
+		authorized := middleware.Authorize(authenticator, mux, map[string]auth.Role{"GET /messages": auth.RoleReader})(mux)
+		return middleware.RateLimit(limiter, mux, middleware.RateLimitPolicy{
+			Default: ratelimit.Limit{Requests: 10, Window: time.Minute},
+			Key:     middleware.IPKey(0),
+		})(authorized)
+	}
+	badKey := func() *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/messages", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		r.Header.Set(auth.APIKeyHeader, "guess")
+		return r
+	}
+
+	t.Run("Given invalid credentials within the limit, it should count them and respond 401", func(t *testing.T) {
+		limiter := &fakeLimiter{result: ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9}}
+		rec := httptest.NewRecorder()
+
+		newHandler(limiter).ServeHTTP(rec, badKey())
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, []string{"GET /messages|ip:10.0.0.1"}, limiter.keys)
+	})
+
+	t.Run("Given invalid credentials over the limit, it should respond 429", func(t *testing.T) {
+		limiter := &fakeLimiter{result: ratelimit.Result{Limit: 10, RetryAfter: time.Second}}
+		rec := httptest.NewRecorder()
+
+		newHandler(limiter).ServeHTTP(rec, badKey())
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	})
+}
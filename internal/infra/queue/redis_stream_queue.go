@@ -0,0 +1,157 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muratdemir0/gopulse-messages/internal/app"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	DefaultStream       = "gopulse:message-queue"
+	DefaultGroup        = "dispatchers"
+	DefaultBlock        = 5 * time.Second
+	DefaultClaimMinIdle = time.Minute
+)
+
+// Config describes the stream and consumer group a RedisStreamQueue uses.
+// Consumer must be unique per replica and defaults to hostname-pid.
+type Config struct {
+	Stream   string
+	Group    string
+	Consumer string
+	// Block bounds how long Receive waits for new entries.
+	Block time.Duration
+	// ClaimMinIdle is how long an entry may stay unacknowledged before
+	// another consumer takes it over.
+	ClaimMinIdle time.Duration
+	// MaxLen trims the stream to roughly this many entries when positive.
+	MaxLen int64
+}
+
+// RedisStreamQueue is an app.WorkQueue on a Redis stream consumed through a
+// consumer group. Entries left pending by a crashed or stuck consumer are
+// reclaimed with XAUTOCLAIM.
+type RedisStreamQueue struct {
+	client *redis.Client
+	cfg    Config
+}
+
+func NewRedisStreamQueue(client *redis.Client, cfg Config) *RedisStreamQueue {
+	if cfg.Stream == "" {
+		cfg.Stream = DefaultStream
+	}
+	if cfg.Group == "" {
+		cfg.Group = DefaultGroup
+	}
+	if cfg.Consumer == "" {
+		hostname, _ := os.Hostname()
+		cfg.Consumer = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	if cfg.Block <= 0 {
+		cfg.Block = DefaultBlock
+	}
+	if cfg.ClaimMinIdle <= 0 {
+		cfg.ClaimMinIdle = DefaultClaimMinIdle
+	}
+
+	return &RedisStreamQueue{client: client, cfg: cfg}
+}
+
+// EnsureGroup creates the stream and consumer group if they do not exist.
+func (q *RedisStreamQueue) EnsureGroup(ctx context.Context) error {
+	err := q.client.XGroupCreateMkStream(ctx, q.cfg.Stream, q.cfg.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %s on %s: %w", q.cfg.Group, q.cfg.Stream, err)
+	}
+	return nil
+}
+
+func (q *RedisStreamQueue) Enqueue(ctx context.Context, messageID int64) error {
+	args := &redis.XAddArgs{
+		Stream: q.cfg.Stream,
+		Values: map[string]any{"message_id": strconv.FormatInt(messageID, 10)},
+	}
+	if q.cfg.MaxLen > 0 {
+		args.MaxLen = q.cfg.MaxLen
+		args.Approx = true
+	}
+
+	if err := q.client.XAdd(ctx, args).Err(); err != nil {
+		return fmt.Errorf("failed to enqueue message id %d: %w", messageID, err)
+	}
+	return nil
+}
+
+// Receive first reclaims entries that stayed pending longer than ClaimMinIdle
+// and otherwise reads new entries, blocking up to Block.
+func (q *RedisStreamQueue) Receive(ctx context.Context, count int) ([]app.QueueJob, error) {
+	claimed, _, err := q.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   q.cfg.Stream,
+		Group:    q.cfg.Group,
+		Consumer: q.cfg.Consumer,
+		MinIdle:  q.cfg.ClaimMinIdle,
+		Start:    "0-0",
+		Count:    int64(count),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reclaim pending entries: %w", err)
+	}
+	if len(claimed) > 0 {
+		return q.jobs(ctx, claimed), nil
+	}
+
+	streams, err := q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    q.cfg.Group,
+		Consumer: q.cfg.Consumer,
+		Streams:  []string{q.cfg.Stream, ">"},
+		Count:    int64(count),
+		Block:    q.cfg.Block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from %s: %w", q.cfg.Stream, err)
+	}
+
+	var jobs []app.QueueJob
+	for _, stream := range streams {
+		jobs = append(jobs, q.jobs(ctx, stream.Messages)...)
+	}
+	return jobs, nil
+}
+
+func (q *RedisStreamQueue) Ack(ctx context.Context, jobs ...app.QueueJob) error {
+	ids := make([]string, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID
+	}
+
+	if err := q.client.XAck(ctx, q.cfg.Stream, q.cfg.Group, ids...).Err(); err != nil {
+		return fmt.Errorf("failed to acknowledge %d entries: %w", len(ids), err)
+	}
+	return nil
+}
+
+// jobs converts stream entries to jobs. Malformed entries can never be
+// processed, so they are acknowledged and dropped.
+func (q *RedisStreamQueue) jobs(ctx context.Context, messages []redis.XMessage) []app.QueueJob {
+	var jobs []app.QueueJob
+	for _, msg := range messages {
+		value, _ := msg.Values["message_id"].(string)
+		messageID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			_ = q.client.XAck(ctx, q.cfg.Stream, q.cfg.Group, msg.ID).Err()
+			continue
+		}
+		jobs = append(jobs, app.QueueJob{ID: msg.ID, MessageID: messageID})
+	}
+	return jobs
+}
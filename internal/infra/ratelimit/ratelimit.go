@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit allows Requests per Window. A zero Requests means unlimited.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Window <= 0
+}
+
+// Result describes the state of a client's window after a request.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the current window ends.
+	Reset time.Duration
+	// RetryAfter is the time until the next request would be allowed. It is
+	// zero for allowed requests.
+	RetryAfter time.Duration
+}
+
+// Limiter counts a request for key against limit.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// slidingWindow estimates the requests of the last window by weighting the
+// previous fixed window's count by how much of it still overlaps, and builds
+// the result for a request elapsed into the current window.
+func slidingWindow(limit Limit, previous, current int64, elapsed time.Duration, allowed bool) Result {
+	weight := float64(limit.Window-elapsed) / float64(limit.Window)
+	count := float64(previous)*weight + float64(current)
+
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit.Requests,
+		Remaining: max(int(math.Ceil(float64(limit.Requests)-count)), 0),
+		Reset:     limit.Window - elapsed,
+	}
+
+	if !allowed {
+		result.RetryAfter = retryAfter(limit, previous, current, elapsed, count)
+	}
+	return result
+}
+
+// retryAfter returns how long until the weighted count drops below the limit
+// again.
+func retryAfter(limit Limit, previous, current int64, elapsed time.Duration, count float64) time.Duration {
+	window := float64(limit.Window)
+	excess := count - float64(limit.Requests)
+	remainingInWindow := window - float64(elapsed)
+
+	// The previous window's share decays linearly over the current window.
+	if previous > 0 {
+		if wait := excess * window / float64(previous); wait <= remainingInWindow {
+			return time.Duration(wait)
+		}
+	}
+
+	// Otherwise wait for the next window, in which the current count decays.
+	if current < int64(limit.Requests) {
+		return time.Duration(remainingInWindow)
+	}
+	decay := window * (1 - float64(limit.Requests)/float64(current))
+	return time.Duration(remainingInWindow + decay)
+}
@@ -0,0 +1,42 @@
+//go:build unit
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlidingWindow(t *testing.T) {
+	limit := Limit{Requests: 10, Window: time.Minute}
+
+	t.Run("Given an allowed request, remaining should include the previous window's share", func(t *testing.T) {
+		result := slidingWindow(limit, 6, 3, 30*time.Second, true)
+
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 10, result.Limit)
+		assert.Equal(t, 4, result.Remaining)
+		assert.Equal(t, 30*time.Second, result.Reset)
+		assert.Zero(t, result.RetryAfter)
+	})
+
+	t.Run("Given a full window after a busy previous one, retry when its share has decayed", func(t *testing.T) {
+		// 12 * 0.5 + 5 = 11; the previous window loses one request per 5s.
+		result := slidingWindow(limit, 12, 5, 30*time.Second, false)
+
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+		assert.Equal(t, 5*time.Second, result.RetryAfter)
+	})
+
+	t.Run("Given a full current window, retry after it ends and part of it decays", func(t *testing.T) {
+		result := slidingWindow(limit, 0, 10, 45*time.Second, false)
+
+		assert.Equal(t, 15*time.Second, result.RetryAfter)
+
+		result = slidingWindow(limit, 0, 20, 45*time.Second, false)
+		assert.Equal(t, 45*time.Second, result.RetryAfter)
+	})
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript counts a request in KEYS[1] unless the weighted count
+// of KEYS[1] and the previous window KEYS[2] has reached the limit. It
+// returns whether the request was allowed and both window counts.
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local elapsed = tonumber(ARGV[3])
+
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+
+if previous * (window - elapsed) / window + current >= limit then
+	return {0, previous, current}
+end
+
+current = redis.call('INCR', KEYS[1])
+if current == 1 then
+	redis.call('PEXPIRE', KEYS[1], window * 2)
+end
+return {1, previous, current}
+`)
+
+// RedisLimiter is a sliding window limiter shared by all replicas through
+// Redis. Each window is one counter key, so a client costs two keys at most.
+type RedisLimiter struct {
+	client *redis.Client
+	prefix string
+	now    func() time.Time
+}
+
+func NewRedisLimiter(client *redis.Client, prefix string) *RedisLimiter {
+	return &RedisLimiter{
+		client: client,
+		prefix: prefix,
+		now:    time.Now,
+	}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if limit.Unlimited() {
+		return Result{Allowed: true, Limit: limit.Requests, Remaining: limit.Requests}, nil
+	}
+
+	window := limit.Window.Milliseconds()
+	now := l.now().UnixMilli()
+	index := now / window
+	elapsed := now - index*window
+
+	keys := []string{
+		fmt.Sprintf("%s:%s:%d", l.prefix, key, index),
+		fmt.Sprintf("%s:%s:%d", l.prefix, key, index-1),
+	}
+	values, err := slidingWindowScript.Run(ctx, l.client, keys, limit.Requests, window, elapsed).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to evaluate rate limit for %s: %w", key, err)
+	}
+
+	return slidingWindow(limit, values[1], values[2], time.Duration(elapsed)*time.Millisecond, values[0] == 1), nil
+}
@@ -0,0 +1,91 @@
+//go:build integration
+
+package ratelimit_test
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/muratdemir0/gopulse-messages/internal/infra/ratelimit"
+)
+
+var testRedis *redis.Client
+
+func TestMain(m *testing.M) {
+	ctx := context.Background()
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "redis:7-alpine",
+			ExposedPorts: []string{"6379/tcp"},
+			WaitingFor:   wait.ForLog("Ready to accept connections").WithStartupTimeout(10 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		log.Fatalf("Failed to start container: %v", err)
+	}
+
+	endpoint, err := container.Endpoint(ctx, "")
+	if err != nil {
+		log.Fatalf("Failed to get container endpoint: %v", err)
+	}
+	testRedis = redis.NewClient(&redis.Options{Addr: endpoint})
+
+	code := m.Run()
+
+	_ = testRedis.Close()
+	if err := container.Terminate(ctx); err != nil {
+		log.Printf("Failed to terminate container: %v", err)
+	}
+	os.Exit(code)
+}
+
+func TestRedisLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+	limiter := ratelimit.NewRedisLimiter(testRedis, "test:ratelimit")
+	limit := ratelimit.Limit{Requests: 3, Window: time.Minute}
+
+	t.Run("Given requests up to the limit, they should be allowed and the next rejected", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			result, err := limiter.Allow(ctx, "client-a", limit)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 3, result.Limit)
+		}
+
+		result, err := limiter.Allow(ctx, "client-a", limit)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+		assert.Positive(t, result.RetryAfter)
+	})
+
+	t.Run("Given another client, it should have its own window", func(t *testing.T) {
+		result, err := limiter.Allow(ctx, "client-b", limit)
+
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Remaining)
+	})
+
+	t.Run("Given two limiters sharing Redis, they should share the count", func(t *testing.T) {
+		other := ratelimit.NewRedisLimiter(testRedis, "test:ratelimit")
+
+		_, err := limiter.Allow(ctx, "client-c", limit)
+		require.NoError(t, err)
+		result, err := other.Allow(ctx, "client-c", limit)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, result.Remaining)
+	})
+}
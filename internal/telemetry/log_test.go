@@ -0,0 +1,97 @@
+//go:build unit
+
+package telemetry_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/muratdemir0/gopulse-messages/internal/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func logLine(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	buf.Reset()
+	return line
+}
+
+func TestLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(telemetry.NewLogHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+
+	t.Run("Given a context with a span, the trace and span IDs are logged", func(t *testing.T) {
+		ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "op")
+		defer span.End()
+
+		logger.InfoContext(ctx, "hello")
+
+		line := logLine(t, &buf)
+		assert.Equal(t, span.SpanContext().TraceID().String(), line["trace_id"])
+		assert.Equal(t, span.SpanContext().SpanID().String(), line["span_id"])
+		assert.Equal(t, "test", line["component"])
+	})
+
+	t.Run("Given a context with a request ID, the request ID is logged", func(t *testing.T) {
+		ctx := telemetry.ContextWithRequestID(context.Background(), "req-1")
+
+		logger.InfoContext(ctx, "hello")
+
+		line := logLine(t, &buf)
+		assert.Equal(t, "req-1", line["request_id"])
+		assert.NotContains(t, line, "trace_id")
+	})
+
+	t.Run("Given a plain context, no correlation fields are logged", func(t *testing.T) {
+		logger.Info("hello")
+
+		line := logLine(t, &buf)
+		assert.NotContains(t, line, "trace_id")
+		assert.NotContains(t, line, "request_id")
+	})
+}
+
+func TestFanoutHandler(t *testing.T) {
+	t.Run("Given two handlers, each receives records at its own level", func(t *testing.T) {
+		var info, debug bytes.Buffer
+		logger := slog.New(telemetry.NewFanoutHandler(
+			slog.NewJSONHandler(&info, &slog.HandlerOptions{Level: slog.LevelInfo}),
+			slog.NewJSONHandler(&debug, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		)).With("component", "test")
+
+		logger.Debug("debug only")
+		assert.Empty(t, info.String())
+		assert.Equal(t, "debug only", logLine(t, &debug)["msg"])
+
+		logger.Info("both")
+		assert.Equal(t, "test", logLine(t, &info)["component"])
+		assert.Equal(t, "test", logLine(t, &debug)["component"])
+	})
+}
+
+func TestLevelHandler(t *testing.T) {
+	t.Run("Given a level var, changing it should take effect on existing loggers", func(t *testing.T) {
+		var buf bytes.Buffer
+		level := new(slog.LevelVar)
+		level.Set(slog.LevelInfo)
+		logger := slog.New(telemetry.NewLevelHandler(level,
+			slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		)).With("component", "test")
+
+		logger.Debug("dropped")
+		assert.Empty(t, buf.String())
+
+		level.Set(slog.LevelDebug)
+		logger.Debug("kept")
+		line := logLine(t, &buf)
+		assert.Equal(t, "kept", line["msg"])
+		assert.Equal(t, "test", line["component"])
+	})
+}
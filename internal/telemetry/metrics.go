@@ -0,0 +1,105 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/sdk/metric"
+)
+
+const DefaultMetricsExportInterval = 15 * time.Second
+
+// DurationBuckets are the histogram boundaries, in seconds, used for request
+// and send latencies.
+var DurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// MeterConfig selects where metrics go. OTLP export is enabled by an
+// endpoint, the Prometheus handler by the Prometheus flag; both may be on.
+type MeterConfig struct {
+	ServiceName    string
+	OTLPEndpoint   string
+	ExportInterval time.Duration
+	Prometheus     bool
+}
+
+// MeterProvider collects the metrics recorded through the global OTel meter
+// provider, pushes them to an OTLP collector and serves them in the
+// Prometheus text format.
+type MeterProvider struct {
+	provider *metric.MeterProvider
+	registry *prometheus.Registry
+}
+
+func NewMeterProvider(cfg MeterConfig) (*MeterProvider, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := newResource(ctx, cfg.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []metric.Option{metric.WithResource(res)}
+
+	if cfg.OTLPEndpoint != "" {
+		exporter, err := otlpmetricgrpc.New(ctx,
+			otlpmetricgrpc.WithEndpoint(cfg.OTLPEndpoint),
+			otlpmetricgrpc.WithInsecure(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP gRPC metric exporter: %w", err)
+		}
+
+		interval := cfg.ExportInterval
+		if interval <= 0 {
+			interval = DefaultMetricsExportInterval
+		}
+		opts = append(opts, metric.WithReader(metric.NewPeriodicReader(exporter, metric.WithInterval(interval))))
+	}
+
+	var registry *prometheus.Registry
+	if cfg.Prometheus {
+		registry = prometheus.NewRegistry()
+		registry.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
+
+		exporter, err := otelprometheus.New(
+			otelprometheus.WithRegisterer(registry),
+			otelprometheus.WithoutScopeInfo(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Prometheus exporter: %w", err)
+		}
+		opts = append(opts, metric.WithReader(exporter))
+	}
+
+	mp := metric.NewMeterProvider(opts...)
+
+	otel.SetMeterProvider(mp)
+
+	return &MeterProvider{provider: mp, registry: registry}, nil
+}
+
+// Handler serves the collected metrics for Prometheus to scrape. It returns
+// nil when the Prometheus exporter is disabled.
+func (mp *MeterProvider) Handler() http.Handler {
+	if mp.registry == nil {
+		return nil
+	}
+	return promhttp.HandlerFor(mp.registry, promhttp.HandlerOpts{})
+}
+
+// Shutdown flushes pending metrics to the collector and stops the readers.
+func (mp *MeterProvider) Shutdown(ctx context.Context) error {
+	return mp.provider.Shutdown(ctx)
+}
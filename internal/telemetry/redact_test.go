@@ -0,0 +1,113 @@
+//go:build unit
+
+package telemetry_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/muratdemir0/gopulse-messages/internal/telemetry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactCredentials(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"URL DSN", "postgres://gopulse:s3cret@db:5432/gopulse?sslmode=disable", "postgres://gopulse:xxxxx@db:5432/gopulse?sslmode=disable"},
+		{"URL in a sentence", "dial redis://:hunter2@cache:6379 failed", "dial redis://:xxxxx@cache:6379 failed"},
+		{"keyword DSN", "host=db user=gopulse password=s3cret dbname=gopulse", "host=db user=gopulse password=xxxxx dbname=gopulse"},
+		{"quoted keyword DSN", "host=db password='s3 cret' dbname=gopulse", "host=db password=xxxxx dbname=gopulse"},
+		{"URL without password", "https://user@example.com/hook", "https://user@example.com/hook"},
+		{"plain text", "connection refused", "connection refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, telemetry.RedactCredentials(tt.input))
+		})
+	}
+}
+
+func TestMaskContact(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"phone number", "+905551234567", "+90********67"},
+		{"short number", "12345", "*****"},
+		{"email", "jane.doe@example.com", "j***@example.com"},
+		{"email without local part", "@example.com", "***@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, telemetry.MaskContact(tt.input))
+		})
+	}
+}
+
+func TestRedactHandler(t *testing.T) {
+	var buf bytes.Buffer
+	newLogger := func(redactor telemetry.Redactor) *slog.Logger {
+		return slog.New(telemetry.NewRedactHandler(redactor, slog.NewJSONHandler(&buf, nil)))
+	}
+
+	t.Run("Given sensitive attributes, they are masked", func(t *testing.T) {
+		newLogger(telemetry.Redactor{}).Info("hello",
+			"dsn", "postgres://gopulse:s3cret@db/gopulse",
+			"recipient", "+905551234567",
+			"token", "abc",
+			"error", errors.New("connect postgres://gopulse:s3cret@db failed"),
+			"message_id", 42,
+		)
+
+		line := logLine(t, &buf)
+		assert.Equal(t, "postgres://gopulse:xxxxx@db/gopulse", line["dsn"])
+		assert.Equal(t, "+90********67", line["recipient"])
+		assert.Equal(t, "[REDACTED]", line["token"])
+		assert.Equal(t, "connect postgres://gopulse:xxxxx@db failed", line["error"])
+		assert.EqualValues(t, 42, line["message_id"])
+	})
+
+	t.Run("Given message content, it is dropped by default", func(t *testing.T) {
+		newLogger(telemetry.Redactor{}).Info("hello", "content", "your code is 1234", "message_id", 1)
+
+		line := logLine(t, &buf)
+		assert.NotContains(t, line, "content")
+		assert.Contains(t, line, "message_id")
+	})
+
+	t.Run("Given content logging is enabled, message content is kept", func(t *testing.T) {
+		newLogger(telemetry.Redactor{LogContent: true}).Info("hello", "content", "your code is 1234")
+
+		line := logLine(t, &buf)
+		assert.Equal(t, "your code is 1234", line["content"])
+	})
+
+	t.Run("Given attributes added with With and groups, they are masked too", func(t *testing.T) {
+		newLogger(telemetry.Redactor{}).
+			With("email", "jane.doe@example.com").
+			Info("hello", slog.Group("message", "recipient", "+905551234567", "content", "secret"))
+
+		line := logLine(t, &buf)
+		assert.Equal(t, "j***@example.com", line["email"])
+		assert.Equal(t, map[string]any{"recipient": "+90********67"}, line["message"])
+	})
+
+	t.Run("Given the redactor as ReplaceAttr, it masks attributes", func(t *testing.T) {
+		redactor := telemetry.Redactor{}
+		logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: redactor.ReplaceAttr}))
+
+		logger.Info("hello", "phone", "+905551234567", "content", "hi")
+
+		line := logLine(t, &buf)
+		assert.Equal(t, "+90********67", line["phone"])
+		assert.NotContains(t, line, "content")
+	})
+}
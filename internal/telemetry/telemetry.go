@@ -3,6 +3,7 @@ package telemetry
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -14,6 +15,7 @@ import (
 
 type TracerProvider struct {
 	provider *trace.TracerProvider
+	sampler  *ratioSampler
 }
 
 func NewTracerProvider(serviceName, otlpEndpoint string, sampleRate float64) (*TracerProvider, error) {
@@ -28,25 +30,26 @@ func NewTracerProvider(serviceName, otlpEndpoint string, sampleRate float64) (*T
 		return nil, fmt.Errorf("failed to create OTLP gRPC exporter: %w", err)
 	}
 
-	res, err := resource.New(ctx,
-		resource.WithAttributes(
-			semconv.ServiceNameKey.String(serviceName),
-			semconv.ServiceVersionKey.String("1.0.0"),
-		),
-	)
+	res, err := newResource(ctx, serviceName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create resource: %w", err)
+		return nil, err
 	}
 
+	sampler := newRatioSampler(sampleRate)
 	tp := trace.NewTracerProvider(
 		trace.WithBatcher(exporter),
 		trace.WithResource(res),
-		trace.WithSampler(trace.TraceIDRatioBased(sampleRate)),
+		trace.WithSampler(sampler),
 	)
 
 	otel.SetTracerProvider(tp)
 
-	return &TracerProvider{provider: tp}, nil
+	return &TracerProvider{provider: tp, sampler: sampler}, nil
+}
+
+// SetSampleRate changes the ratio of traces sampled from now on.
+func (tp *TracerProvider) SetSampleRate(rate float64) {
+	tp.sampler.set(rate)
 }
 
 func (tp *TracerProvider) Shutdown(ctx context.Context) error {
@@ -56,3 +59,42 @@ func (tp *TracerProvider) Shutdown(ctx context.Context) error {
 func (tp *TracerProvider) GetTracerProvider() *trace.TracerProvider {
 	return tp.provider
 }
+
+// newResource describes the service on every exported span and metric.
+func newResource(ctx context.Context, serviceName string) (*resource.Resource, error) {
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceNameKey.String(serviceName),
+			semconv.ServiceVersionKey.String("1.0.0"),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+	return res, nil
+}
+
+// ratioSampler is a TraceIDRatioBased sampler whose ratio can change at
+// runtime.
+type ratioSampler struct {
+	sampler atomic.Pointer[trace.Sampler]
+}
+
+func newRatioSampler(rate float64) *ratioSampler {
+	s := &ratioSampler{}
+	s.set(rate)
+	return s
+}
+
+func (s *ratioSampler) set(rate float64) {
+	sampler := trace.TraceIDRatioBased(rate)
+	s.sampler.Store(&sampler)
+}
+
+func (s *ratioSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	return (*s.sampler.Load()).ShouldSample(p)
+}
+
+func (s *ratioSampler) Description() string {
+	return (*s.sampler.Load()).Description()
+}
@@ -0,0 +1,3 @@
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS failed_attempts,
+    DROP COLUMN IF EXISTS provider;
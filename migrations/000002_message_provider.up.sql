@@ -0,0 +1,3 @@
+ALTER TABLE messages
+    ADD COLUMN provider        VARCHAR(64),
+    ADD COLUMN failed_attempts JSONB NOT NULL DEFAULT '[]'::jsonb;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS routing_rules;
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS tenant,
+    DROP COLUMN IF EXISTS priority;
@@ -0,0 +1,17 @@
+ALTER TABLE messages
+    ADD COLUMN priority VARCHAR(10) NOT NULL DEFAULT 'normal' CHECK (priority IN ('low', 'normal', 'high')),
+    ADD COLUMN tenant   VARCHAR(64),
+    ADD COLUMN tags     JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+CREATE TABLE routing_rules (
+    id         SERIAL       PRIMARY KEY,
+    name       VARCHAR(100) NOT NULL UNIQUE,
+    position   INT          NOT NULL DEFAULT 0,
+    enabled    BOOLEAN      NOT NULL DEFAULT TRUE,
+    match      JSONB        NOT NULL DEFAULT '{}'::jsonb,
+    targets    JSONB        NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_routing_rules_position ON routing_rules (position, id);
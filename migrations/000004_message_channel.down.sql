@@ -0,0 +1,8 @@
+DELETE FROM messages WHERE channel = 'email';
+
+ALTER TABLE messages
+    ALTER COLUMN content   TYPE VARCHAR(160),
+    ALTER COLUMN recipient TYPE VARCHAR(20),
+    DROP COLUMN IF EXISTS html_content,
+    DROP COLUMN IF EXISTS subject,
+    DROP COLUMN IF EXISTS channel;
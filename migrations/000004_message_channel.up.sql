@@ -0,0 +1,6 @@
+ALTER TABLE messages
+    ADD COLUMN channel      VARCHAR(10) NOT NULL DEFAULT 'sms' CHECK (channel IN ('sms', 'email')),
+    ADD COLUMN subject      VARCHAR(255),
+    ADD COLUMN html_content TEXT,
+    ALTER COLUMN recipient TYPE VARCHAR(254),
+    ALTER COLUMN content   TYPE TEXT;
@@ -0,0 +1,14 @@
+CREATE TABLE message_attempts (
+    id            BIGSERIAL    PRIMARY KEY,
+    message_id    INT          NOT NULL REFERENCES messages (id) ON DELETE CASCADE,
+    provider      VARCHAR(64)  NOT NULL,
+    status_code   INT,
+    latency_ms    BIGINT       NOT NULL,
+    response_body TEXT,
+    error_class   VARCHAR(20)  NOT NULL DEFAULT '',
+    error         TEXT,
+    retry_attempt INT,
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_message_attempts_message_id ON message_attempts (message_id, id);
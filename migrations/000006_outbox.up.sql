@@ -0,0 +1,12 @@
+CREATE TABLE outbox_events (
+    id           BIGSERIAL    PRIMARY KEY,
+    message_id   INT          NOT NULL,
+    event_type   VARCHAR(64)  NOT NULL,
+    payload      JSONB        NOT NULL,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP WITH TIME ZONE,
+    attempts     INT          NOT NULL DEFAULT 0,
+    last_error   TEXT
+);
+
+CREATE INDEX idx_outbox_events_unpublished ON outbox_events (id) WHERE published_at IS NULL;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS messages_notify_created ON messages;
+DROP FUNCTION IF EXISTS notify_message_created();
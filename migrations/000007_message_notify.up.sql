@@ -0,0 +1,12 @@
+CREATE OR REPLACE FUNCTION notify_message_created() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('messages_created', NEW.id::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER messages_notify_created
+    AFTER INSERT ON messages
+    FOR EACH ROW
+    WHEN (NEW.status = 'pending')
+    EXECUTE FUNCTION notify_message_created();
@@ -0,0 +1,3 @@
+ALTER TABLE messages
+    ADD COLUMN trace_id VARCHAR(32),
+    ADD COLUMN span_id  VARCHAR(16);
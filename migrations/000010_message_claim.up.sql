@@ -0,0 +1,2 @@
+ALTER TABLE messages
+    ADD COLUMN claimed_until TIMESTAMP WITH TIME ZONE;